@@ -0,0 +1,351 @@
+package fstr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AST is the parsed representation of a format string, for tools such as linters, editors and
+// migrators that analyze or rewrite templates. Its String method renders the nodes back into a
+// format string, so a template can be rewritten by editing its nodes:
+//
+//	tree, _ := fstr.Parse("Hi {name|snake}, you owe {amount:,.2f}")
+//	for _, n := range tree.Nodes {
+//		if p, ok := n.(*fstr.Placeholder); ok && p.Key == "amount" {
+//			p.Key = "balance"
+//		}
+//	}
+//	// tree.String(): "Hi {name|snake}, you owe {balance:,.2f}"
+type AST struct {
+	// Format is the format string that was parsed. Whitespace trim markers are kept as the TrimBefore
+	// and TrimAfter fields of the nodes, and the whitespace they trim as part of the Text nodes.
+	Format string
+	// Nodes are the top-level nodes of the format string, in order.
+	Nodes []Node
+}
+
+// Node is a node of an AST: a *Text, *Placeholder, *Partial or *Block.
+type Node interface {
+	// String returns the node as format string text.
+	String() string
+
+	node()
+}
+
+// Text is literal text, rendered as is. It includes anything that looks like a placeholder but is
+// not one, such as {name|unknownFilter}.
+type Text struct {
+	// Offset is the byte offset of the node in the AST's Format.
+	Offset int
+	Text   string
+}
+
+// Placeholder is a placeholder, such as {name}, {balance=:,.2f} or {"-"|repeat(60)}.
+type Placeholder struct {
+	// Offset is the byte offset of the node in the AST's Format.
+	Offset int
+	// Key is the data map key, "*" for the expand-all placeholder, or the value of a string literal.
+	Key string
+	// Literal is set if Key is the value of a string literal rather than a data map key.
+	Literal bool
+	// Debug is set for the {key=} form, which renders "key=value".
+	Debug bool
+	// Secret is set for the {secret:path#key} form, whose Spec names a secret; see WithSecretResolver.
+	Secret bool
+	// Filters are applied to the value in order, before the spec.
+	Filters []Filter
+	// Spec is the format specifier, or nil if there is none.
+	Spec *Spec
+	// TrimBefore and TrimAfter are set for the {-key} and {key-} whitespace trim markers.
+	TrimBefore, TrimAfter bool
+}
+
+// Filter is a filter applied to a placeholder's value, such as |snake or |truncate(20, "…").
+type Filter struct {
+	Name string
+	Args []string
+}
+
+// Spec is the format specifier of a placeholder, such as ",.2f", "red,bold" or "bar(10)".
+type Spec struct {
+	// Text is the specifier as written.
+	Text string
+	// Name and Args are set for named specifiers, such as "bar" and ["10"] for bar(10).
+	Name string
+	Args []string
+}
+
+// Partial includes a partial: {>name} or {>name arg=key arg="text"}. See WithPartials and Define.
+type Partial struct {
+	// Offset is the byte offset of the node in the AST's Format.
+	Offset int
+	// Name is the name of the partial.
+	Name string
+	// Args are the arguments bound for the partial, in order.
+	Args []PartialArg
+	// TrimBefore and TrimAfter are set for the {->name} and {>name-} whitespace trim markers.
+	TrimBefore, TrimAfter bool
+}
+
+// PartialArg is an argument of a partial, such as v=amount or cur="EUR".
+type PartialArg struct {
+	Name string
+	// Value is the data map key the argument is bound to, or the value of a string literal.
+	Value string
+	// Literal is set if Value is the value of a string literal rather than a data map key.
+	Literal bool
+}
+
+// Block is a loop block: {#each key options}body{/each}.
+type Block struct {
+	// Offset is the byte offset of the node in the AST's Format.
+	Offset int
+	// Key is the data map key of the slice looped over.
+	Key string
+	// Options are the block's options as written, such as "sortby=.price desc" or "|groupby .category".
+	Options string
+	// Body are the nodes rendered for each element.
+	Body []Node
+	// TrimBefore and TrimAfter are set for the whitespace trim markers of the {#each} tag, and
+	// EndTrimBefore and EndTrimAfter for those of the {/each} tag.
+	TrimBefore, TrimAfter       bool
+	EndTrimBefore, EndTrimAfter bool
+}
+
+func (*Text) node()        {}
+func (*Placeholder) node() {}
+func (*Partial) node()     {}
+func (*Block) node()       {}
+
+// Parse parses a format string into its AST. It returns an ErrParse *Error if a loop block is
+// malformed or not closed; placeholders are not type-checked against any data.
+func Parse(format string) (*AST, error) {
+	s, offsets, marks := unmark(format)
+	tree := &AST{Format: format}
+	type frame struct {
+		block *Block
+		tag   string
+		nodes *[]Node
+	}
+	stack := []frame{{nodes: &tree.Nodes}}
+	// text adds the text of format between the offsets start and end of s.
+	text := func(start, end int) {
+		nodes := stack[len(stack)-1].nodes
+		start, end = offsets[start], offsets[end]
+		if start == end {
+			return
+		}
+		if n := len(*nodes); n > 0 {
+			if t, ok := (*nodes)[n-1].(*Text); ok {
+				t.Text += format[start:end]
+				return
+			}
+		}
+		*nodes = append(*nodes, &Text{Offset: start, Text: format[start:end]})
+	}
+	last := 0
+	for _, loc := range tokenRe.FindAllStringIndex(s, -1) {
+		m := s[loc[0]:loc[1]]
+		offset, tag, mark := offsets[loc[0]], format[offsets[loc[0]]:offsets[loc[1]]], marks[loc[0]]
+		if matches := blockRe.FindStringSubmatch(m); matches != nil {
+			if matches[1] == "" {
+				if len(stack) == 1 {
+					return nil, &Error{Format: format, Placeholder: tag, Err: fmt.Errorf("{/each} without {#each}"), kind: ErrParse}
+				}
+				text(last, loc[0])
+				b := stack[len(stack)-1].block
+				b.EndTrimBefore, b.EndTrimAfter = mark.before, mark.after
+				stack = stack[:len(stack)-1]
+				last = loc[1]
+				continue
+			}
+			if _, err := parseEachOptions(matches[2]); err != nil {
+				return nil, &Error{Format: format, Placeholder: tag, Err: err, kind: ErrParse}
+			}
+			b := &Block{Offset: offset, Key: matches[1], Options: strings.TrimSpace(matches[2]), TrimBefore: mark.before, TrimAfter: mark.after}
+			text(last, loc[0])
+			nodes := stack[len(stack)-1].nodes
+			*nodes = append(*nodes, b)
+			stack = append(stack, frame{block: b, tag: tag, nodes: &b.Body})
+			last = loc[1]
+			continue
+		}
+		if matches := partialRe.FindStringSubmatch(m); matches != nil {
+			text(last, loc[0])
+			n := &Partial{Offset: offset, Name: matches[1], TrimBefore: mark.before, TrimAfter: mark.after}
+			for _, arg := range partialArgRe.FindAllStringSubmatch(matches[2], -1) {
+				a := PartialArg{Name: arg[1], Value: arg[2]}
+				if v, err := strconv.Unquote(arg[2]); err == nil {
+					a.Value, a.Literal = v, true
+				}
+				n.Args = append(n.Args, a)
+			}
+			nodes := stack[len(stack)-1].nodes
+			*nodes = append(*nodes, n)
+			last = loc[1]
+			continue
+		}
+		p, ok := parsePlaceholder(m)
+		if !ok {
+			continue // text
+		}
+		text(last, loc[0])
+		n := newPlaceholder(offset, p)
+		n.TrimBefore, n.TrimAfter = mark.before, mark.after
+		nodes := stack[len(stack)-1].nodes
+		*nodes = append(*nodes, n)
+		last = loc[1]
+	}
+	if len(stack) > 1 {
+		return nil, &Error{Format: format, Placeholder: stack[len(stack)-1].tag, Err: fmt.Errorf("unclosed loop block"), kind: ErrParse}
+	}
+	text(last, len(s))
+	return tree, nil
+}
+
+// unmark removes the whitespace trim markers from the tags of format without applying them. It
+// returns the unmarked format string, the offset in format of each of its byte offsets up to and
+// including its length, and the markers of its tags keyed by their offsets.
+func unmark(format string) (s string, offsets []int, marks map[int]markedTag) {
+	var b strings.Builder
+	offsets = make([]int, 0, len(format)+1)
+	marks = make(map[int]markedTag)
+	copyText := func(start, end int) {
+		b.WriteString(format[start:end])
+		for n := start; n < end; n++ {
+			offsets = append(offsets, n)
+		}
+	}
+	last := 0
+	for _, m := range markedTags(format) {
+		copyText(last, m.start)
+		marks[b.Len()] = m
+		b.WriteString(m.tag)
+		// The bytes of an unmarked tag all map to its start; its end maps to the end of the marked tag.
+		for n := 0; n < len(m.tag); n++ {
+			offsets = append(offsets, m.start)
+		}
+		last = m.end
+	}
+	copyText(last, len(format))
+	return b.String(), append(offsets, len(format)), marks
+}
+
+// newPlaceholder converts a parsed placeholder at offset into its AST node.
+func newPlaceholder(offset int, p placeholder) *Placeholder {
+	n := &Placeholder{Offset: offset, Key: p.key, Literal: p.literal, Debug: p.debug, Secret: p.secret}
+	for _, f := range p.filters {
+		n.Filters = append(n.Filters, Filter{Name: f.name, Args: f.args})
+	}
+	if p.spec != "" {
+		n.Spec = &Spec{Text: p.spec}
+		if c, ok := parseNamedSpec(p.spec); ok {
+			n.Spec.Name, n.Spec.Args = c.name, c.args
+		}
+	}
+	return n
+}
+
+// String renders the nodes back into a format string.
+func (t *AST) String() string {
+	return nodesString(t.Nodes)
+}
+
+// nodesString concatenates the format string text of nodes.
+func nodesString(nodes []Node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(n.String())
+	}
+	return b.String()
+}
+
+// String returns the text.
+func (t *Text) String() string {
+	return t.Text
+}
+
+// String returns the placeholder as format string text.
+func (p *Placeholder) String() string {
+	var b strings.Builder
+	b.WriteString(openTag(p.TrimBefore))
+	if p.Literal {
+		b.WriteString(strconv.Quote(p.Key))
+	} else {
+		b.WriteString(p.Key)
+	}
+	if p.Debug {
+		b.WriteByte('=')
+	}
+	for _, f := range p.Filters {
+		b.WriteString("|" + f.String())
+	}
+	if p.Spec != nil {
+		b.WriteString(":" + p.Spec.String())
+	}
+	b.WriteString(closeTag(p.TrimAfter))
+	return b.String()
+}
+
+// String returns the filter as format string text, quoting the arguments that need it.
+func (f Filter) String() string {
+	if len(f.Args) == 0 {
+		return f.Name
+	}
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = arg
+		if arg == "" || arg != strings.TrimSpace(arg) || strings.ContainsAny(arg, `,"`) {
+			args[i] = strconv.Quote(arg)
+		}
+	}
+	return f.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// String returns the specifier as format string text: Name and Args if Name is set, Text otherwise.
+func (s *Spec) String() string {
+	if s.Name == "" {
+		return s.Text
+	}
+	return Filter{Name: s.Name, Args: s.Args}.String()
+}
+
+// String returns the partial as format string text.
+func (p *Partial) String() string {
+	s := openTag(p.TrimBefore) + ">" + p.Name
+	for _, a := range p.Args {
+		if a.Literal {
+			s += " " + a.Name + "=" + strconv.Quote(a.Value)
+		} else {
+			s += " " + a.Name + "=" + a.Value
+		}
+	}
+	return s + closeTag(p.TrimAfter)
+}
+
+// String returns the loop block as format string text.
+func (b *Block) String() string {
+	options := b.Options
+	if options != "" && !strings.HasPrefix(options, "|") {
+		options = " " + options
+	}
+	return openTag(b.TrimBefore) + "#each " + b.Key + options + closeTag(b.TrimAfter) + nodesString(b.Body) +
+		openTag(b.EndTrimBefore) + "/each" + closeTag(b.EndTrimAfter)
+}
+
+// openTag returns the opening brace of a tag, with the whitespace trim marker if trim is set.
+func openTag(trim bool) string {
+	if trim {
+		return "{-"
+	}
+	return "{"
+}
+
+// closeTag returns the closing brace of a tag, with the whitespace trim marker if trim is set.
+func closeTag(trim bool) string {
+	if trim {
+		return "-}"
+	}
+	return "}"
+}
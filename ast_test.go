@@ -0,0 +1,116 @@
+package fstr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tree, err := Parse(`Hi {name=|truncate(10, "…")}: {"-"|repeat(3)} {n:bar(10)} {x|nope}{#each items sortby=.p desc}[{p:,.2f}]{/each}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Node{
+		&Text{Offset: 0, Text: "Hi "},
+		&Placeholder{Offset: 3, Key: "name", Debug: true, Filters: []Filter{{Name: "truncate", Args: []string{"10", "…"}}}},
+		&Text{Offset: 30, Text: ": "},
+		&Placeholder{Offset: 32, Key: "-", Literal: true, Filters: []Filter{{Name: "repeat", Args: []string{"3"}}}},
+		&Text{Offset: 47, Text: " "},
+		&Placeholder{Offset: 48, Key: "n", Spec: &Spec{Text: "bar(10)", Name: "bar", Args: []string{"10"}}},
+		&Text{Offset: 59, Text: " {x|nope}"},
+		&Block{Offset: 68, Key: "items", Options: "sortby=.p desc", Body: []Node{
+			&Text{Offset: 96, Text: "["},
+			&Placeholder{Offset: 97, Key: "p", Spec: &Spec{Text: ",.2f"}},
+			&Text{Offset: 105, Text: "]"},
+		}},
+	}
+	if !reflect.DeepEqual(tree.Nodes, want) {
+		for i, n := range tree.Nodes {
+			t.Logf("node %d: %#v", i, n)
+		}
+		t.Errorf("Parse() nodes differ from %v", want)
+	}
+}
+
+func TestASTString(t *testing.T) {
+	formats := []string{
+		"",
+		"plain text",
+		"{name} {age=} {*:kv} {bal:,.2f} {status:red,bold}",
+		`{s|trim|truncate(5, "a, b")|snake} {"-"|repeat(60)} {t|trim(" ")}`,
+		"{#each items|groupby .cat}{cat}: {count}{#each items if .n > 2}{n}{/each}{/each}",
+		"{x|nope} {x:bogus}",
+		"{>header}{#each items}{>item}{/each}",
+		`{>money v=amount cur="EUR"}{>money v=fee cur=currency}`,
+		"Items:\n{-#each items-}\n- {-n|truncate(3, …)-} \n{-/each-}\n {->footer-} {-} {-x|nope}",
+	}
+	for _, format := range formats {
+		tree, err := Parse(format)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", format, err)
+		}
+		if got := tree.String(); got != format {
+			t.Errorf("Parse(%q).String() = %q", format, got)
+		}
+	}
+
+	tree, _ := Parse("Hi {name|snake}, you owe {amount:,.2f} {n:bar(10)}")
+	for _, n := range tree.Nodes {
+		if p, ok := n.(*Placeholder); ok {
+			if p.Key == "amount" {
+				p.Key = "balance"
+			}
+			if p.Spec != nil && p.Spec.Name == "bar" {
+				p.Spec.Args = []string{"20"}
+			}
+		}
+	}
+	if got, want := tree.String(), "Hi {name|snake}, you owe {balance:,.2f} {n:bar(20)}"; got != want {
+		t.Errorf("rewritten String() = %q, want %q", got, want)
+	}
+}
+
+func TestParseTrimMarkers(t *testing.T) {
+	tree, err := Parse("a {-name-} b{#each items-}\n {n}\n{-/each}")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Node{
+		&Text{Offset: 0, Text: "a "},
+		&Placeholder{Offset: 2, Key: "name", TrimBefore: true, TrimAfter: true},
+		&Text{Offset: 10, Text: " b"},
+		&Block{Offset: 12, Key: "items", TrimAfter: true, EndTrimBefore: true, Body: []Node{
+			&Text{Offset: 26, Text: "\n "},
+			&Placeholder{Offset: 28, Key: "n"},
+			&Text{Offset: 31, Text: "\n"},
+		}},
+	}
+	if !reflect.DeepEqual(tree.Nodes, want) {
+		for i, n := range tree.Nodes {
+			t.Logf("node %d: %#v", i, n)
+		}
+		t.Errorf("Parse() nodes differ from %v", want)
+	}
+	if tree.Format != "a {-name-} b{#each items-}\n {n}\n{-/each}" {
+		t.Errorf("Parse().Format = %q, want the format with its markers", tree.Format)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		format      string
+		placeholder string
+	}{
+		{format: "{#each items}{a}", placeholder: "{#each items}"},
+		{format: "{a}{/each}", placeholder: "{/each}"},
+		{format: "{#each items sortby=p}{/each}", placeholder: "{#each items sortby=p}"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.format)
+		var e *Error
+		if !errors.Is(err, ErrParse) || !errors.As(err, &e) || e.Placeholder != tt.placeholder {
+			t.Errorf("Parse(%q) error = %v, want ErrParse at %s", tt.format, err, tt.placeholder)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package fstr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defaultBarWidth is the number of cells drawn by {pct:bar} unless a width is given.
+const defaultBarWidth = 20
+
+// bar renders a fraction between 0 and 1 as a textual progress bar of the given width, followed by
+// the percentage: {pct:bar(10)} renders 0.6 as "[██████····] 60%". Values outside [0, 1] are
+// clamped. Combined with a carriage return, as in fstr.Print("\r{pct:bar(30)}", data), it redraws
+// a single status line.
+func bar(value interface{}, width ...string) (string, error) {
+	w := defaultBarWidth
+	if len(width) > 0 {
+		var err error
+		if w, err = strconv.Atoi(width[0]); err != nil || w < 1 || w > maxSpecWidth {
+			return "", &Error{Spec: "bar", Err: fmt.Errorf("invalid width %q", width[0]), kind: ErrBadSpec}
+		}
+	}
+	r, err := toRat(value)
+	if err != nil {
+		return "", &Error{Spec: "bar", Err: err, kind: ErrUnsupportedType}
+	}
+	f, _ := r.Float64()
+	f = math.Max(0, math.Min(1, f))
+	filled := int(math.Round(f * float64(w)))
+	return fmt.Sprintf("[%s%s] %d%%", strings.Repeat("█", filled), strings.Repeat("·", w-filled), int(math.Round(f*100))), nil
+}
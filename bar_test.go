@@ -0,0 +1,35 @@
+package fstr
+
+import "testing"
+
+func TestBar(t *testing.T) {
+	tests := []struct {
+		format string
+		pct    interface{}
+		want   string
+	}{
+		{format: "{pct:bar(10)}", pct: 0.6, want: "[██████····] 60%"},
+		{format: "{pct:bar(10)}", pct: 0, want: "[··········] 0%"},
+		{format: "{pct:bar(10)}", pct: 1, want: "[██████████] 100%"},
+		{format: "{pct:bar(4)}", pct: 0.333, want: "[█···] 33%"},
+		{format: "{pct:bar(5)}", pct: 1.5, want: "[█████] 100%"},
+		{format: "{pct:bar(5)}", pct: -0.2, want: "[·····] 0%"},
+		{format: "{pct:bar}", pct: 0.5, want: "[██████████··········] 50%"},
+		{format: "\r{pct:bar(5)}", pct: float32(0.2), want: "\r[█····] 20%"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"pct": tt.pct})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.pct, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.pct, got, tt.want)
+		}
+	}
+	for _, format := range []string{"{pct:bar(0)}", "{pct:bar(x)}", "{name:bar}", "{pct:bar(100000000000)}", "{pct:bar(10001)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"pct": 0.5, "name": "x"}); err == nil {
+			t.Errorf("Interpolate(%q) error = nil, want error", format)
+		}
+	}
+}
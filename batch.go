@@ -0,0 +1,85 @@
+package fstr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RecordError reports a failure to interpolate one record of a batch.
+type RecordError struct {
+	// Index is the position of the record in the batch.
+	Index int
+	// Err is the error interpolating the record, usually an *Error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError reports the records of a batch that failed to interpolate, in order. errors.Is and
+// errors.As look through every record's error:
+//
+//	var be *fstr.BatchError
+//	if errors.As(err, &be) {
+//		for _, re := range be.Errors {
+//			log.Printf("skipping row %d: %v", re.Index, re.Err)
+//		}
+//	}
+type BatchError struct {
+	Errors []*RecordError
+}
+
+// Error implements the error interface, reporting one failed record per line.
+func (e *BatchError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, re := range e.Errors {
+		lines[i] = re.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the errors of the failed records.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, re := range e.Errors {
+		errs[i] = re
+	}
+	return errs
+}
+
+// InterpolateAll interpolates format with each of records. See Interpolator.InterpolateAll.
+func InterpolateAll(format string, records []map[string]interface{}) ([]string, error) {
+	return defaultInterpolator.InterpolateAll(format, records)
+}
+
+// InterpolateAll interpolates format with each of records, as for a mail merge. Unlike a loop calling
+// Interpolate, it does not stop at the first failure: the result has one string per record, empty for
+// the records that failed, and the error is a *BatchError identifying each failed record and why.
+func (i *Interpolator) InterpolateAll(format string, records []map[string]interface{}) ([]string, error) {
+	results := make([]string, len(records))
+	var failed []*RecordError
+	for n, data := range records {
+		result, err := i.Interpolate(format, data)
+		if err != nil {
+			failed = append(failed, &RecordError{Index: n, Err: err})
+			continue
+		}
+		results[n] = result
+	}
+	if failed != nil {
+		return results, &BatchError{Errors: failed}
+	}
+	return results, nil
+}
+
+// ExecuteAll interpolates the template with each of records, like InterpolateAll.
+func (t *Template) ExecuteAll(records []map[string]interface{}) ([]string, error) {
+	return t.interp.InterpolateAll(t.format, records)
+}
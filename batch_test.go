@@ -0,0 +1,46 @@
+package fstr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInterpolateAll(t *testing.T) {
+	records := []map[string]interface{}{
+		{"name": "Alice", "balance": 1234.5},
+		{"name": "Bob"},
+		{"name": "Carol", "balance": 12},
+		{"name": "Dave", "balance": "n/a"},
+	}
+	got, err := New(WithStrictKeys()).InterpolateAll("Dear {name}, you owe {balance:,.2f}", records)
+	want := []string{"Dear Alice, you owe 1,234.50", "", "Dear Carol, you owe 12.00", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InterpolateAll() = %q, want %q", got, want)
+	}
+
+	var be *BatchError
+	if !errors.As(err, &be) {
+		t.Fatalf("InterpolateAll() error = %#v, want *BatchError", err)
+	}
+	if len(be.Errors) != 2 || be.Errors[0].Index != 1 || be.Errors[1].Index != 3 {
+		t.Fatalf("BatchError.Errors = %v, want records 1 and 3", be.Errors)
+	}
+	wantMsg := `record 1: fstr: {balance:,.2f}: missing key "balance"` + "\n" +
+		`record 3: fstr: {balance:,.2f}: spec ",.2f": cannot format string as a number`
+	if err.Error() != wantMsg {
+		t.Errorf("InterpolateAll() error =\n%s\nwant\n%s", err, wantMsg)
+	}
+	for _, kind := range []error{ErrMissingKey, ErrUnsupportedType} {
+		if !errors.Is(err, kind) {
+			t.Errorf("errors.Is(err, %v) = false, want true", kind)
+		}
+	}
+
+	if got, err := InterpolateAll("{name}", records); err != nil || len(got) != len(records) {
+		t.Errorf("InterpolateAll() = %q, %v", got, err)
+	}
+	if got, err := greeting.ExecuteAll(records[:1]); err != nil || got[0] != "Hello Alice, your balance is 1,234.50" {
+		t.Errorf("ExecuteAll() = %q, %v", got, err)
+	}
+}
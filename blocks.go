@@ -0,0 +1,420 @@
+package fstr
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// blockRe matches the tags of loop blocks: {#each key options} opens a block and {/each} closes it.
+var blockRe = regexp.MustCompile(`{#each ([a-zA-Z0-9_]+)((?:[ |][^{}]*)?)}|{/each}`)
+
+// eachOptions are the options of a loop block, following its key.
+type eachOptions struct {
+	groupBy string     // field the elements are grouped by, if not empty
+	sortBy  string     // field the elements are sorted by, if not empty
+	desc    bool       // sort in descending order
+	cond    *condition // only elements satisfying it are rendered, if not nil
+}
+
+// condition filters the elements of a loop block: if .field, or if .field op value.
+type condition struct {
+	field string
+	op    string      // comparison operator, or "" to test whether the field is set to a non-zero value
+	value interface{} // a string, or a *big.Rat for numeric literals
+}
+
+// comparisons lists the operators accepted by conditions.
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// parseEachOptions parses the options of a loop block, such as `sortby=.price desc`,
+// `if .status == "open"` or `|groupby .category`.
+func parseEachOptions(s string) (eachOptions, error) {
+	var opts eachOptions
+	tokens, err := optionTokens(s)
+	if err != nil {
+		return eachOptions{}, err
+	}
+	if len(tokens) > 0 && tokens[0] == "|groupby" {
+		if len(tokens) < 2 || !strings.HasPrefix(tokens[1], ".") || !keyRe.MatchString(tokens[1][1:]) {
+			return eachOptions{}, fmt.Errorf("invalid groupby field")
+		}
+		opts.groupBy = tokens[1][1:]
+		tokens = tokens[2:]
+	}
+	for n := 0; n < len(tokens); n++ {
+		if tokens[n] == "if" && opts.cond == nil {
+			cond, used, err := parseCondition(tokens[n+1:])
+			if err != nil {
+				return eachOptions{}, err
+			}
+			opts.cond = &cond
+			n += used
+			continue
+		}
+		field, ok := strings.CutPrefix(tokens[n], "sortby=.")
+		if !ok || !keyRe.MatchString(field) || opts.sortBy != "" {
+			return eachOptions{}, fmt.Errorf("invalid loop option %q", tokens[n])
+		}
+		opts.sortBy = field
+		if n+1 < len(tokens) && (tokens[n+1] == "asc" || tokens[n+1] == "desc") {
+			opts.desc = tokens[n+1] == "desc"
+			n++
+		}
+	}
+	return opts, nil
+}
+
+// parseCondition parses the condition at the start of tokens, following "if", and reports how
+// many tokens it consumed.
+func parseCondition(tokens []string) (condition, int, error) {
+	if len(tokens) == 0 {
+		return condition{}, 0, fmt.Errorf("missing condition after if")
+	}
+	field, ok := strings.CutPrefix(tokens[0], ".")
+	if !ok || !keyRe.MatchString(field) {
+		return condition{}, 0, fmt.Errorf("invalid condition field %q", tokens[0])
+	}
+	if len(tokens) < 2 || !comparisons[tokens[1]] {
+		return condition{field: field}, 1, nil
+	}
+	if len(tokens) < 3 {
+		return condition{}, 0, fmt.Errorf("missing value after %s", tokens[1])
+	}
+	cond := condition{field: field, op: tokens[1]}
+	switch lit := tokens[2]; {
+	case strings.HasPrefix(lit, `"`):
+		cond.value, _ = strconv.Unquote(lit) // optionTokens only yields valid quoted strings
+	default:
+		r, ok := new(big.Rat).SetString(lit)
+		if !ok {
+			return condition{}, 0, fmt.Errorf("invalid condition value %s", lit)
+		}
+		cond.value = r
+	}
+	return cond, 3, nil
+}
+
+// optionTokens splits the options of a loop block into space-separated tokens, keeping
+// double-quoted strings, which may contain spaces, in one piece.
+func optionTokens(s string) ([]string, error) {
+	var tokens []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		if s[0] == '"' {
+			lit, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", s)
+			}
+			tokens = append(tokens, lit)
+			s = s[len(lit):]
+			continue
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		tokens = append(tokens, s[:end])
+		s = s[end:]
+	}
+	return tokens, nil
+}
+
+// match reports whether an element satisfies the condition.
+func (c condition) match(elem map[string]interface{}) bool {
+	v, ok := elem[c.field]
+	if c.op == "" {
+		return ok && v != nil && !reflect.ValueOf(v).IsZero()
+	}
+	if !ok {
+		return c.op == "!="
+	}
+	var cmp int
+	switch want := c.value.(type) {
+	case *big.Rat:
+		r, err := toRat(v)
+		if err != nil {
+			return c.op == "!="
+		}
+		cmp = r.Cmp(want)
+	case string:
+		cmp = strings.Compare(fmt.Sprint(v), want)
+	}
+	switch c.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// blockAction converts a loop block tag into a text/template action.
+func blockAction(tag string) (string, error) {
+	matches := blockRe.FindStringSubmatch(tag)
+	if matches[1] == "" {
+		return "{{end}}", nil
+	}
+	if _, err := parseEachOptions(matches[2]); err != nil {
+		return "", err
+	}
+	// example format: {#each items sortby=.price desc} => {{range each .items "sortby=.price desc"}}
+	return fmt.Sprintf("{{range each .%s %q}}", matches[1], strings.TrimSpace(matches[2])), nil
+}
+
+// each returns the elements of a loop block's slice as data maps, grouped, filtered and ordered as
+// set by the block's options, in that order. Elements must be structs or maps with string keys;
+// struct fields are named as in Table. A nil slice yields no elements.
+func (i *Interpolator) each(items interface{}, options string) ([]map[string]interface{}, error) {
+	if items == nil {
+		return nil, nil
+	}
+	elems, _, err := records(items, i.keyOrder)
+	if err != nil {
+		return nil, &Error{Spec: "each", Err: err, kind: ErrUnsupportedType}
+	}
+	opts, err := parseEachOptions(options)
+	if err != nil {
+		return nil, &Error{Spec: "each", Err: err, kind: ErrParse}
+	}
+	if opts.groupBy != "" {
+		elems = groupBy(elems, opts.groupBy)
+	}
+	if opts.cond != nil {
+		matched := elems[:0]
+		for _, elem := range elems {
+			if opts.cond.match(elem) {
+				matched = append(matched, elem)
+			}
+		}
+		elems = matched
+	}
+	if opts.sortBy != "" {
+		sort.SliceStable(elems, func(a, b int) bool {
+			x, y := elems[a][opts.sortBy], elems[b][opts.sortBy]
+			if opts.desc {
+				return compareValues(y, x) < 0
+			}
+			return compareValues(x, y) < 0
+		})
+	}
+	return elems, nil
+}
+
+// groupBy groups elements by the value of a field, in order of first appearance, for report
+// templates such as {#each txns|groupby .category}{category}: {amount:,.2f}{/each}. Each group is a
+// data map holding the field's value, the group's elements as "items", for a nested loop block,
+// their number as "count", and the subtotal of every other field whose values are all numbers
+// under the field's own name. The field, "items" and "count" take precedence over subtotals.
+func groupBy(elems []map[string]interface{}, field string) []map[string]interface{} {
+	var (
+		groups  []map[string]interface{}
+		members [][]map[string]interface{}
+		index   = make(map[string]int)
+	)
+	for _, elem := range elems {
+		id := fmt.Sprintf("%#v", elem[field])
+		n, ok := index[id]
+		if !ok {
+			n = len(groups)
+			index[id] = n
+			groups = append(groups, map[string]interface{}{field: elem[field]})
+			members = append(members, nil)
+		}
+		members[n] = append(members[n], elem)
+	}
+	for n, group := range groups {
+		group["items"] = members[n]
+		group["count"] = len(members[n])
+		for k, sum := range subtotals(members[n]) {
+			if _, taken := group[k]; !taken {
+				group[k] = sum
+			}
+		}
+	}
+	return groups
+}
+
+// subtotals sums the fields of elems whose values are all numbers. Sums of integers are int64
+// values; a field holding any floating-point value is summed as float64.
+func subtotals(elems []map[string]interface{}) map[string]interface{} {
+	type subtotal struct {
+		ints    int64
+		floats  float64
+		float   bool
+		invalid bool
+	}
+	sums := make(map[string]*subtotal)
+	for _, elem := range elems {
+		for k, value := range elem {
+			sum, ok := sums[k]
+			if !ok {
+				sum = &subtotal{}
+				sums[k] = sum
+			}
+			v := reflect.ValueOf(value)
+			switch {
+			case !isNumber(value):
+				sum.invalid = true
+			case v.CanInt():
+				sum.ints += v.Int()
+			case v.CanUint():
+				sum.ints += int64(v.Uint())
+			default:
+				sum.floats += v.Float()
+				sum.float = true
+			}
+		}
+	}
+	result := make(map[string]interface{})
+	for k, sum := range sums {
+		switch {
+		case sum.invalid:
+		case sum.float:
+			result[k] = sum.floats + float64(sum.ints)
+		default:
+			result[k] = sum.ints
+		}
+	}
+	return result
+}
+
+// compareValues orders two values for sorting: numbers numerically, anything else by its
+// default formatting. Missing values sort before all others.
+func compareValues(x, y interface{}) int {
+	switch {
+	case x == nil || y == nil:
+		if x == nil && y == nil {
+			return 0
+		} else if x == nil {
+			return -1
+		}
+		return 1
+	case isNumber(x) && isNumber(y):
+		rx, errx := toRat(x)
+		ry, erry := toRat(y)
+		if errx == nil && erry == nil {
+			return rx.Cmp(ry)
+		}
+	}
+	return strings.Compare(fmt.Sprint(x), fmt.Sprint(y))
+}
+
+// stripBlocks replaces every outermost loop block of a format string with a plain placeholder for
+// the block's key, so that the placeholders in the block bodies, which refer to the fields of the
+// elements, are not taken for data map keys.
+func stripBlocks(format string) string {
+	var (
+		b     strings.Builder
+		depth int
+		last  int
+	)
+	for _, loc := range blockRe.FindAllStringSubmatchIndex(format, -1) {
+		if loc[2] >= 0 {
+			if depth == 0 {
+				b.WriteString(format[last:loc[0]])
+				b.WriteString("{" + format[loc[2]:loc[3]] + "}")
+			}
+			depth++
+			continue
+		}
+		if depth > 0 {
+			depth--
+			if depth == 0 {
+				last = loc[1]
+			}
+		}
+	}
+	if depth == 0 {
+		b.WriteString(format[last:])
+	}
+	return b.String()
+}
+
+// inlineRe matches the placeholders and partials of a format string.
+var inlineRe = regexp.MustCompile(placeholderRe.String() + "|" + partialRe.String())
+
+// segment is a part of a format string: literal text, or a fragment that can be rendered on its own,
+// either a placeholder or partial outside loop blocks or an outermost loop block.
+type segment struct {
+	text     string
+	fragment bool
+}
+
+// segments splits a format string into literal text and fragments, in order, applying its whitespace
+// trim markers.
+func segments(format string) []segment {
+	format = trimMarkers(format)
+	type span struct{ start, end int }
+	var (
+		spans []span
+		depth int
+		start int
+	)
+	for _, loc := range blockRe.FindAllStringSubmatchIndex(format, -1) {
+		if loc[2] >= 0 {
+			if depth == 0 {
+				start = loc[0]
+			}
+			depth++
+		} else if depth > 0 {
+			depth--
+			if depth == 0 {
+				spans = append(spans, span{start, loc[1]})
+			}
+		}
+	}
+	blocks := spans
+	spans = nil
+	for _, loc := range inlineRe.FindAllStringIndex(format, -1) {
+		for len(blocks) > 0 && blocks[0].start < loc[0] {
+			spans = append(spans, blocks[0])
+			blocks = blocks[1:]
+		}
+		if len(spans) > 0 && loc[0] < spans[len(spans)-1].end {
+			continue // inside a block
+		}
+		spans = append(spans, span{loc[0], loc[1]})
+	}
+	spans = append(spans, blocks...)
+
+	var result []segment
+	last := 0
+	for _, sp := range spans {
+		if sp.start > last {
+			result = append(result, segment{text: format[last:sp.start]})
+		}
+		result = append(result, segment{text: format[sp.start:sp.end], fragment: true})
+		last = sp.end
+	}
+	if last < len(format) {
+		result = append(result, segment{text: format[last:]})
+	}
+	return result
+}
+
+// fragments returns the fragments of a format string in order of appearance, listing repeated
+// fragments once.
+func fragments(format string) []string {
+	var (
+		result []string
+		seen   = make(map[string]bool)
+	)
+	for _, seg := range segments(format) {
+		if seg.fragment && !seen[seg.text] {
+			seen[seg.text] = true
+			result = append(result, seg.text)
+		}
+	}
+	return result
+}
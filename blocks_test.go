@@ -0,0 +1,155 @@
+package fstr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEachBlock(t *testing.T) {
+	type item struct {
+		Name  string  `fstr:"name"`
+		Price float64 `fstr:"price"`
+	}
+	data := map[string]interface{}{
+		"items": []item{{"tea", 3.5}, {"cake", 12}, {"coffee", 4}},
+		"orders": []map[string]interface{}{
+			{"id": 2, "lines": []map[string]interface{}{{"sku": "b"}, {"sku": "a"}}},
+			{"id": 1, "lines": []map[string]interface{}{{"sku": "c"}}},
+		},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{#each items}{name} {/each}", want: "tea cake coffee "},
+		{format: "{#each items sortby=.price}{name}={price:.2f};{/each}", want: "tea=3.50;coffee=4.00;cake=12.00;"},
+		{format: "{#each items sortby=.price desc}{name};{/each}", want: "cake;coffee;tea;"},
+		{format: "{#each items sortby=.name asc}{name};{/each}", want: "cake;coffee;tea;"},
+		{format: "{#each orders sortby=.id}#{id}:{#each lines sortby=.sku}{sku}{/each} {/each}", want: "#1:c #2:ab "},
+		{format: "[{#each missing}x{/each}]", want: "[]"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestEachBlockCondition(t *testing.T) {
+	data := map[string]interface{}{
+		"orders": []map[string]interface{}{
+			{"id": 1, "status": "open", "total": 20.5, "rush": true},
+			{"id": 2, "status": "closed", "total": 99},
+			{"id": 3, "status": "open", "total": 5, "rush": false},
+			{"id": 4, "status": "on hold", "total": 100},
+		},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: `{#each orders if .status == "open"}{id} {/each}`, want: "1 3 "},
+		{format: `{#each orders if .status != "open"}{id} {/each}`, want: "2 4 "},
+		{format: `{#each orders if .status == "on hold"}{id} {/each}`, want: "4 "},
+		{format: `{#each orders if .total >= 20.5}{id} {/each}`, want: "1 2 4 "},
+		{format: `{#each orders if .total < 20}{id} {/each}`, want: "3 "},
+		{format: `{#each orders if .rush}{id} {/each}`, want: "1 "},
+		{format: `{#each orders if .total > 10 sortby=.total desc}{id} {/each}`, want: "4 2 1 "},
+		{format: `{#each orders sortby=.total if .status == "open"}{id} {/each}`, want: "3 1 "},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestEachBlockGroupBy(t *testing.T) {
+	type txn struct {
+		Date     string  `fstr:"date"`
+		Category string  `fstr:"category"`
+		Amount   float64 `fstr:"amount"`
+		Units    int     `fstr:"units"`
+	}
+	data := map[string]interface{}{
+		"txns": []txn{
+			{"03-01", "food", 12.5, 1},
+			{"03-02", "rent", 900, 1},
+			{"03-04", "food", 7.25, 3},
+		},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "{#each txns|groupby .category}{category} ({count}): {amount:,.2f}\n{#each items}  {date} {amount:.2f}\n{/each}{/each}",
+			want:   "food (2): 19.75\n  03-01 12.50\n  03-04 7.25\nrent (1): 900.00\n  03-02 900.00\n",
+		},
+		{format: "{#each txns|groupby .category}{category}={units};{/each}", want: "food=4;rent=1;"},
+		{format: "{#each txns|groupby .category sortby=.amount desc}{category};{/each}", want: "rent;food;"},
+		{format: "{#each txns|groupby .category if .count > 1}{category};{/each}", want: "food;"},
+		{format: "{#each txns|groupby .units}{units}:{count};{/each}", want: "1:2;3:1;"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestEachBlockErrors(t *testing.T) {
+	data := map[string]interface{}{"items": []int{1, 2}}
+	tests := []string{
+		"{#each items sortby=price}{/each}",
+		"{#each items sortby=.price sortby=.name}{/each}",
+		"{#each items}unclosed",
+		"{#each items if}{/each}",
+		"{#each items|groupby}{/each}",
+		"{#each items|groupby category}{/each}",
+		"{#each items sortby=.n |groupby .category}{/each}",
+		"{#each items if status}{/each}",
+		"{#each items if .n >}{/each}",
+		"{#each items if .n > ten}{/each}",
+		`{#each items if .s == "open}{/each}`,
+		"{#each items}{/each}",
+	}
+	for _, format := range tests {
+		_, err := Interpolate(format, data)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("Interpolate(%q) error = %v, want *Error", format, err)
+		}
+	}
+}
+
+func TestEachBlockRequirements(t *testing.T) {
+	reqs, err := Requirements("{title}: {#each items sortby=.price}{name} {price:.2f}{/each} {total:,}")
+	if err != nil {
+		t.Fatalf("Requirements() error = %v", err)
+	}
+	want := []Requirement{
+		{Key: "title", Kind: KindAny},
+		{Key: "items", Kind: KindAny},
+		{Key: "total", Specs: []string{","}, Kind: KindNumber},
+	}
+	if !reflect.DeepEqual(reqs, want) {
+		t.Errorf("Requirements() = %v, want %v", reqs, want)
+	}
+}
@@ -0,0 +1,45 @@
+package fstr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ansiRe matches ANSI escape sequences, which take up no width on a terminal: SGR styles such as
+// those emitted by {status:red} and OSC 8 hyperlinks.
+var ansiRe = regexp.MustCompile("\x1b\\[[0-9;]*m|\x1b]8;;[^\x1b]*\x1b\\\\")
+
+// visibleWidth returns the number of runes of s that a terminal displays.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiRe.ReplaceAllString(s, ""))
+}
+
+// box draws a border around multi-line text, for highlighted notices in command-line tools, with an
+// optional title set into the top border: {notice|box("Warning")} renders
+//
+//	┌─ Warning ──────────┐
+//	│ Disk almost full.  │
+//	│ 95% used on /data. │
+//	└────────────────────┘
+func box(value interface{}, title ...string) string {
+	lines := strings.Split(strings.TrimSuffix(fmt.Sprint(value), "\n"), "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, visibleWidth(line))
+	}
+	top := "┌" + strings.Repeat("─", width+2) + "┐"
+	if len(title) > 0 && title[0] != "" {
+		label := "─ " + title[0] + " "
+		width = max(width, visibleWidth(label)-1)
+		top = "┌" + label + strings.Repeat("─", width+2-visibleWidth(label)) + "┐"
+	}
+	var b strings.Builder
+	b.WriteString(top + "\n")
+	for _, line := range lines {
+		b.WriteString("│ " + line + strings.Repeat(" ", width-visibleWidth(line)) + " │\n")
+	}
+	b.WriteString("└" + strings.Repeat("─", width+2) + "┘")
+	return b.String()
+}
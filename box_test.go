@@ -0,0 +1,45 @@
+package fstr
+
+import "testing"
+
+func TestBox(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		want   string
+	}{
+		{
+			format: "{v|box}",
+			value:  "Hello\nWorld!",
+			want:   "┌────────┐\n│ Hello  │\n│ World! │\n└────────┘",
+		},
+		{
+			format: `{v|box("Warning")}`,
+			value:  "Disk almost full.\n95% used on /data.\n",
+			want: "┌─ Warning ──────────┐\n" +
+				"│ Disk almost full.  │\n" +
+				"│ 95% used on /data. │\n" +
+				"└────────────────────┘",
+		},
+		{
+			format: `{v|box("A long title")}`,
+			value:  "ok",
+			want:   "┌─ A long title ─┐\n│ ok             │\n└────────────────┘",
+		},
+		{
+			format: "{v|box}",
+			value:  "\x1b[31mred\x1b[0m",
+			want:   "┌─────┐\n│ \x1b[31mred\x1b[0m │\n└─────┘",
+		},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"v": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) =\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+}
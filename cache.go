@@ -0,0 +1,72 @@
+package fstr
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultCacheSize is the number of parsed format strings an Interpolator keeps by default.
+const defaultCacheSize = 1000
+
+// parseCache is a least-recently-used cache of parsed templates, bounded so that interpolating
+// caller-controlled format strings cannot grow it without limit. The zero value is an empty cache
+// that keeps nothing.
+type parseCache struct {
+	mu      sync.Mutex
+	size    int                        // maximum number of entries; 0 disables the cache
+	entries map[cacheKey]*list.Element // values are *cacheEntry
+	order   list.List                  // most recently used first
+}
+
+// cacheEntry is an element of a parseCache.
+type cacheEntry struct {
+	key cacheKey
+	p   *parsed
+}
+
+// load returns the template cached for key, marking it as recently used.
+func (c *parseCache) load(key cacheKey) (*parsed, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).p, true
+}
+
+// store caches p for key, evicting the least recently used template if the cache is full.
+func (c *parseCache) store(key cacheKey, p *parsed) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*cacheEntry).p = p
+		c.order.MoveToFront(e)
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[cacheKey]*list.Element)
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, p: p})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// removeIf removes the templates whose keys satisfy fn.
+func (c *parseCache) removeIf(fn func(key cacheKey) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if fn(key) {
+			c.order.Remove(e)
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package fstr
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CSV renders rows as a CSV document with a header record. See Interpolator.CSV.
+func CSV(rows interface{}, columns string) (string, error) {
+	return defaultInterpolator.CSV(rows, columns)
+}
+
+// TSV renders rows as a tab-separated document with a header record. See Interpolator.CSV.
+func TSV(rows interface{}, columns string) (string, error) {
+	return defaultInterpolator.TSV(rows, columns)
+}
+
+// CSV renders rows, a slice of structs or of maps with string keys, as a CSV document: a header
+// record followed by one record per row, each terminated by a newline. Fields are quoted as needed.
+//
+// columns lists the columns as placeholders, each formatting one field of a row, e.g.
+// "{name} {balance:.2f}"; the header of each column is its key. If columns is empty, every field
+// is included, as in Table.
+func (i *Interpolator) CSV(rows interface{}, columns string) (string, error) {
+	return i.delimited(rows, columns, ',')
+}
+
+// TSV is like CSV but separates fields with tabs.
+func (i *Interpolator) TSV(rows interface{}, columns string) (string, error) {
+	return i.delimited(rows, columns, '\t')
+}
+
+// delimited implements CSV and TSV.
+func (i *Interpolator) delimited(rows interface{}, columns string, comma rune) (string, error) {
+	header, cells, _, err := i.tableCells(rows, columns, i.mode(i.stringColor()))
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Comma = comma
+	w.Write(header)
+	w.WriteAll(cells)
+	if err := w.Error(); err != nil {
+		return "", &Error{Format: columns, Err: err}
+	}
+	return b.String(), nil
+}
+
+// csvRow renders a single row as a CSV record, without the trailing newline: {row:csv}.
+// The row may be a slice of values, a struct or a map with string keys, whose fields are
+// ordered as in Table.
+func (i *Interpolator) csvRow(row interface{}) (string, error) {
+	return i.delimitedRow(row, ',', "csv")
+}
+
+// tsvRow renders a single row as a tab-separated record: {row:tsv}.
+func (i *Interpolator) tsvRow(row interface{}) (string, error) {
+	return i.delimitedRow(row, '\t', "tsv")
+}
+
+// delimitedRow implements csvRow and tsvRow.
+func (i *Interpolator) delimitedRow(row interface{}, comma rune, spec string) (string, error) {
+	var fields []string
+	v := indirect(reflect.ValueOf(row))
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for n := 0; n < v.Len(); n++ {
+			fields = append(fields, fmt.Sprint(v.Index(n).Interface()))
+		}
+	default:
+		records, keys, err := records([]interface{}{row}, i.keyOrder)
+		if err != nil {
+			return "", &Error{Spec: spec, Err: err, kind: ErrUnsupportedType}
+		}
+		for _, k := range keys {
+			fields = append(fields, fmt.Sprint(records[0][k]))
+		}
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Comma = comma
+	w.Write(fields)
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", &Error{Spec: spec, Err: err}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
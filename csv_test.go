@@ -0,0 +1,59 @@
+package fstr
+
+import "testing"
+
+func TestCSV(t *testing.T) {
+	type item struct {
+		SKU   string  `fstr:"sku"`
+		Name  string  `fstr:"name"`
+		Price float64 `fstr:"price"`
+	}
+	items := []item{
+		{SKU: "A-1", Name: "Widget, large", Price: 9.5},
+		{SKU: "B-2", Name: `The "best" gadget`, Price: 120},
+	}
+	got, err := CSV(items, "{sku} {name} {price:.2f}")
+	if err != nil {
+		t.Fatalf("CSV() error = %v", err)
+	}
+	want := "sku,name,price\n" +
+		"A-1,\"Widget, large\",9.50\n" +
+		"B-2,\"The \"\"best\"\" gadget\",120.00\n"
+	if got != want {
+		t.Errorf("CSV() =\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = TSV(items, "")
+	if err != nil {
+		t.Fatalf("TSV() error = %v", err)
+	}
+	want = "sku\tname\tprice\n" +
+		"A-1\tWidget, large\t9.5\n" +
+		"B-2\t\"The \"\"best\"\" gadget\"\t120\n"
+	if got != want {
+		t.Errorf("TSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCSVRow(t *testing.T) {
+	tests := []struct {
+		format string
+		row    interface{}
+		want   string
+	}{
+		{format: "{row:csv}", row: []string{"a", "b,c", `d"e`}, want: `a,"b,c","d""e"`},
+		{format: "{row:csv}", row: []interface{}{1, 2.5, "x y"}, want: "1,2.5,x y"},
+		{format: "{row:tsv}", row: []string{"a", "b c"}, want: "a\tb c"},
+		{format: "{row:csv}", row: map[string]interface{}{"b": 2, "a": "line\nbreak"}, want: "\"line\nbreak\",2"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"row": tt.row})
+		if err != nil {
+			t.Errorf("Interpolate(%v) error = %v", tt.row, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%v) = %q, want %q", tt.row, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Diff returns a readable, field-by-field description of the differences between a and b,
+// e.g. "name: A→B, role: admin→viewer". It is also available in templates as {old:diff(new)}.
+//
+// Structs are compared field by field in declaration order. Only exported fields are considered;
+// a field's name can be overridden with an `fstr:"name"` struct tag, and `fstr:"-"` skips it.
+// Maps with string keys are compared key by key in sorted order; use an Interpolator
+// configured WithKeyOrder and the {old:diff(new)} spec for a different order. A key or field present on only
+// one side is rendered as "<none>" on the other. Any other values are compared as a whole.
+//
+// Diff returns an empty string if a and b are equal.
+func Diff(a, b interface{}) string {
+	return diff(a, b, SortedKeys)
+}
+
+// diff implements Diff, emitting map keys in the given order.
+func diff(a, b interface{}, order KeyOrder) string {
+	av, bv := indirect(reflect.ValueOf(a)), indirect(reflect.ValueOf(b))
+	fa, oka := diffFields(av)
+	fb, okb := diffFields(bv)
+	if !oka || !okb {
+		if reflect.DeepEqual(a, b) {
+			return ""
+		}
+		return diffValue(av) + "→" + diffValue(bv)
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, f := range append(fa, fb...) {
+		if !seen[f.name] {
+			seen[f.name] = true
+			names = append(names, f.name)
+		}
+	}
+	if av.Kind() == reflect.Map || bv.Kind() == reflect.Map {
+		order(names)
+	}
+	lookup := func(fields []diffField, name string) (reflect.Value, bool) {
+		for _, f := range fields {
+			if f.name == name {
+				return f.value, true
+			}
+		}
+		return reflect.Value{}, false
+	}
+
+	var changes []string
+	for _, name := range names {
+		x, okx := lookup(fa, name)
+		y, oky := lookup(fb, name)
+		if okx && oky && reflect.DeepEqual(x.Interface(), y.Interface()) {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s→%s", name, diffValue(x), diffValue(y)))
+	}
+	return strings.Join(changes, ", ")
+}
+
+// diffField is a named value taken from a struct field or a map entry.
+type diffField struct {
+	name  string
+	value reflect.Value
+}
+
+// diffFields returns the fields of a struct, in declaration order, or the entries of a string-keyed map.
+// It reports false if v is neither.
+func diffFields(v reflect.Value) ([]diffField, bool) {
+	switch {
+	case v.Kind() == reflect.Struct:
+		var fields []diffField
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if name, ok := fieldName(t.Field(i)); ok {
+				fields = append(fields, diffField{name: name, value: v.Field(i)})
+			}
+		}
+		return fields, true
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		keys := v.MapKeys()
+		fields := make([]diffField, len(keys))
+		for i, k := range keys {
+			fields[i] = diffField{name: k.String(), value: v.MapIndex(k)}
+		}
+		return fields, true
+	default:
+		return nil, false
+	}
+}
+
+// fieldName returns the display name of a struct field, honouring the `fstr` struct tag.
+// It reports false for unexported fields and fields tagged `fstr:"-"`.
+func fieldName(sf reflect.StructField) (string, bool) {
+	if !sf.IsExported() {
+		return "", false
+	}
+	switch tag := sf.Tag.Get("fstr"); tag {
+	case "-":
+		return "", false
+	case "":
+		return sf.Name, true
+	default:
+		return tag, true
+	}
+}
+
+// diffValue renders a single side of a change.
+func diffValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<none>"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// indirect dereferences pointers and interfaces until it reaches a concrete value.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
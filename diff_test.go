@@ -0,0 +1,49 @@
+package fstr
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	type user struct {
+		Name     string
+		Role     string `fstr:"role"`
+		Password string `fstr:"-"`
+		age      int
+	}
+	tests := []struct {
+		name string
+		a, b interface{}
+		want string
+	}{
+		{
+			name: "Structs",
+			a:    user{Name: "A", Role: "admin", Password: "x", age: 1},
+			b:    &user{Name: "B", Role: "viewer", Password: "y", age: 2},
+			want: "Name: A→B, role: admin→viewer",
+		},
+		{
+			name: "Equal structs",
+			a:    user{Name: "A"},
+			b:    user{Name: "A"},
+			want: "",
+		},
+		{
+			name: "Maps",
+			a:    map[string]interface{}{"role": "admin", "name": "A", "team": "core"},
+			b:    map[string]interface{}{"role": "viewer", "name": "A", "email": "a@example.com"},
+			want: "email: <none>→a@example.com, role: admin→viewer, team: core→<none>",
+		},
+		{
+			name: "Scalars",
+			a:    1,
+			b:    2,
+			want: "1→2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.a, tt.b); got != tt.want {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package fstr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// InterpolateJSON interpolates every string value of a JSON document. See Interpolator.InterpolateJSON.
+func InterpolateJSON(r io.Reader, data map[string]interface{}) ([]byte, error) {
+	return defaultInterpolator.InterpolateJSON(r, data)
+}
+
+// InterpolateDocument interpolates every string of a decoded document. See Interpolator.InterpolateDocument.
+func InterpolateDocument(doc interface{}, data map[string]interface{}) (interface{}, error) {
+	return defaultInterpolator.InterpolateDocument(doc, data)
+}
+
+// InterpolateJSON reads a JSON document from r and returns it with every string value interpolated
+// with data, for templating configuration files without round-tripping them through text, which
+// breaks on the document's own braces:
+//
+//	out, err := interp.InterpolateJSON(file, map[string]interface{}{"env": "prod", "tag": "v1.2"})
+//	// {"name": "api-{env}", "image": "api:{tag}"} => {"name": "api-prod", "image": "api:v1.2"}
+//
+// Only string values change: object keys, numbers, layout and key order are kept as they are. A value
+// rendered from a string stays a string, so "{replicas}" yields "3", not 3. The first string that
+// fails to interpolate stops the process and its *Error is returned.
+func (i *Interpolator) InterpolateJSON(r io.Reader, data map[string]interface{}) ([]byte, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &Error{Err: err}
+	}
+	// frame is an enclosing object or array; key tells whether the next token of an object is a key.
+	type frame struct{ object, key bool }
+	var (
+		out   bytes.Buffer
+		stack []frame
+		prev  int // end of the previous token
+		last  int // end of the input written to out
+	)
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF && len(stack) == 0 {
+			break
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, &Error{Err: fmt.Errorf("invalid JSON: %w", err)}
+		}
+		end := int(dec.InputOffset())
+		isKey := false
+		if n := len(stack); n > 0 && stack[n-1].object && tok != json.Delim('}') {
+			isKey = stack[n-1].key
+			stack[n-1].key = !isKey
+		}
+		switch tok := tok.(type) {
+		case json.Delim:
+			if tok == '{' || tok == '[' {
+				stack = append(stack, frame{object: tok == '{', key: true})
+			} else {
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if isKey || !strings.Contains(tok, "{") {
+				break
+			}
+			result, err := i.Interpolate(tok, data)
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := marshalJSON(result, "jsonc", "")
+			if err != nil {
+				return nil, err
+			}
+			start := prev + bytes.IndexByte(raw[prev:end], '"')
+			out.Write(raw[last:start])
+			out.WriteString(encoded)
+			last = end
+		}
+		prev = end
+	}
+	out.Write(raw[last:])
+	return out.Bytes(), nil
+}
+
+// InterpolateDocument returns a copy of doc, a document decoded from JSON, YAML or a similar format,
+// with every string interpolated with data. Maps and slices are copied with their types; other
+// values, including map keys, are kept as they are. It is the way to template YAML configuration,
+// decoded with any YAML package such as gopkg.in/yaml.v3, without round-tripping it through text:
+//
+//	var doc interface{}
+//	if err := yaml.Unmarshal(src, &doc); err != nil { // gopkg.in/yaml.v3
+//		return err
+//	}
+//	doc, err := fstr.InterpolateDocument(doc, data)
+//	if err != nil {
+//		return err
+//	}
+//	out, err := yaml.Marshal(doc)
+//
+// Braces that are not placeholders, such as GitHub Actions' ${{ secrets.TOKEN }}, are kept as written.
+// The first string that fails to interpolate stops the process and its *Error is returned.
+func (i *Interpolator) InterpolateDocument(doc interface{}, data map[string]interface{}) (interface{}, error) {
+	if doc == nil {
+		return nil, nil
+	}
+	v, err := i.interpolateValue(reflect.ValueOf(doc), data)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// interpolateValue implements InterpolateDocument for a value of any type.
+func (i *Interpolator) interpolateValue(v reflect.Value, data map[string]interface{}) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := i.interpolateValue(v.Elem(), data)
+		if err != nil {
+			return v, err
+		}
+		result := reflect.New(v.Type()).Elem()
+		result.Set(elem)
+		return result, nil
+	case reflect.String:
+		if !strings.Contains(v.String(), "{") {
+			return v, nil
+		}
+		s, err := i.Interpolate(v.String(), data)
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(s).Convert(v.Type()), nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := i.interpolateValue(iter.Value(), data)
+			if err != nil {
+				return v, err
+			}
+			result.SetMapIndex(iter.Key(), elem)
+		}
+		return result, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for n := 0; n < v.Len(); n++ {
+			elem, err := i.interpolateValue(v.Index(n), data)
+			if err != nil {
+				return v, err
+			}
+			result.Index(n).Set(elem)
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
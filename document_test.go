@@ -0,0 +1,95 @@
+package fstr
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateJSON(t *testing.T) {
+	data := map[string]interface{}{"env": "prod", "tag": "v1.2", "replicas": 3, "q": `say "hi" <b>`}
+	tests := []struct {
+		name string
+		in   string
+		want string
+		err  error
+	}{
+		{
+			name: "Layout kept",
+			in:   "{\n  \"name\": \"api-{env}\",\n  \"replicas\": 2,\n  \"tags\": [\"{tag}\", \"x\"]\n}\n",
+			want: "{\n  \"name\": \"api-prod\",\n  \"replicas\": 2,\n  \"tags\": [\"v1.2\", \"x\"]\n}\n",
+		},
+		{name: "Keys untouched", in: `{"{env}": "{env}"}`, want: `{"{env}": "prod"}`},
+		{name: "Escaping", in: `{"a":"{q}","b":"é{env}"}`, want: `{"a":"say \"hi\" <b>","b":"éprod"}`},
+		{name: "Nested", in: `[{"a":{"b":["{replicas}",1.50,null,true]}},"{env}"]`, want: `[{"a":{"b":["3",1.50,null,true]}},"prod"]`},
+		{name: "Braces that are not placeholders", in: `{"script":"if x { y }"}`, want: `{"script":"if x { y }"}`},
+		{
+			name: "Template braces",
+			in:   `{"run":"echo ${{ secrets.TOKEN }} {env}","if":"{{{env}}}"}`,
+			want: `{"run":"echo ${{ secrets.TOKEN }} prod","if":"{{prod}}"}`,
+		},
+		{name: "Scalar document", in: ` "{env}" `, want: ` "prod" `},
+		{name: "Missing key", in: `{"a":"{nope}"}`, err: ErrMissingKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(WithStrictKeys()).InterpolateJSON(strings.NewReader(tt.in), data)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("InterpolateJSON() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InterpolateJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("InterpolateJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+	var e *Error
+	if _, err := InterpolateJSON(strings.NewReader(`{"a":`), data); !errors.As(err, &e) {
+		t.Errorf("InterpolateJSON() error = %v, want *Error for invalid JSON", err)
+	}
+}
+
+func TestInterpolateDocument(t *testing.T) {
+	type label string
+	doc := map[string]interface{}{
+		"name":     "api-{env}",
+		"run":      "echo ${{ secrets.TOKEN }} {env}",
+		"replicas": 3,
+		"ports":    []interface{}{"{port}", 443},
+		"labels":   map[string]label{"tier": "{tier}"},
+		"yaml":     map[interface{}]interface{}{1: "{env}", "empty": nil},
+		"none":     []string(nil),
+	}
+	data := map[string]interface{}{"env": "prod", "port": 8080, "tier": "web"}
+	got, err := InterpolateDocument(doc, data)
+	if err != nil {
+		t.Fatalf("InterpolateDocument() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"name":     "api-prod",
+		"run":      "echo ${{ secrets.TOKEN }} prod",
+		"replicas": 3,
+		"ports":    []interface{}{"8080", 443},
+		"labels":   map[string]label{"tier": "web"},
+		"yaml":     map[interface{}]interface{}{1: "prod", "empty": nil},
+		"none":     []string(nil),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InterpolateDocument() = %#v, want %#v", got, want)
+	}
+	if doc["name"] != "api-{env}" {
+		t.Error("InterpolateDocument() modified the document")
+	}
+	if _, err := New(WithStrictKeys()).InterpolateDocument([]interface{}{"{nope}"}, data); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("InterpolateDocument() error = %v, want ErrMissingKey", err)
+	}
+	if got, err := InterpolateDocument(nil, data); got != nil || err != nil {
+		t.Errorf("InterpolateDocument(nil) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,95 @@
+package fstr
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// shortcodeRe matches emoji shortcodes such as :rocket: or :+1:.
+var shortcodeRe = regexp.MustCompile(`:[a-z0-9_+-]+:`)
+
+// shortcodes maps the common Slack, Discord and GitHub emoji shortcodes to their emoji.
+var shortcodes = map[string]string{
+	":+1:":                         "👍",
+	":-1:":                         "👎",
+	":100:":                        "💯",
+	":alarm_clock:":                "⏰",
+	":arrow_down:":                 "⬇️",
+	":arrow_up:":                   "⬆️",
+	":arrow_right:":                "➡️",
+	":arrow_left:":                 "⬅️",
+	":bell:":                       "🔔",
+	":bomb:":                       "💣",
+	":books:":                      "📚",
+	":bug:":                        "🐛",
+	":bulb:":                       "💡",
+	":calendar:":                   "📆",
+	":chart_with_downwards_trend:": "📉",
+	":chart_with_upwards_trend:":   "📈",
+	":check:":                      "✔️",
+	":clap:":                       "👏",
+	":clock:":                      "🕐",
+	":cloud:":                      "☁️",
+	":construction:":               "🚧",
+	":coffee:":                     "☕",
+	":dart:":                       "🎯",
+	":eyes:":                       "👀",
+	":fire:":                       "🔥",
+	":gear:":                       "⚙️",
+	":ghost:":                      "👻",
+	":gift:":                       "🎁",
+	":heart:":                      "❤️",
+	":heavy_check_mark:":           "✔️",
+	":hourglass:":                  "⌛",
+	":information_source:":         "ℹ️",
+	":key:":                        "🔑",
+	":lock:":                       "🔒",
+	":mag:":                        "🔍",
+	":memo:":                       "📝",
+	":money_with_wings:":           "💸",
+	":moneybag:":                   "💰",
+	":no_entry:":                   "⛔",
+	":ok:":                         "🆗",
+	":ok_hand:":                    "👌",
+	":package:":                    "📦",
+	":partying_face:":              "🥳",
+	":pencil:":                     "📝",
+	":point_right:":                "👉",
+	":pray:":                       "🙏",
+	":question:":                   "❓",
+	":rainbow:":                    "🌈",
+	":recycle:":                    "♻️",
+	":red_circle:":                 "🔴",
+	":green_circle:":               "🟢",
+	":yellow_circle:":              "🟡",
+	":rocket:":                     "🚀",
+	":rotating_light:":             "🚨",
+	":scream:":                     "😱",
+	":shield:":                     "🛡️",
+	":skull:":                      "💀",
+	":smile:":                      "😄",
+	":sparkles:":                   "✨",
+	":star:":                       "⭐",
+	":tada:":                       "🎉",
+	":thinking:":                   "🤔",
+	":thumbsdown:":                 "👎",
+	":thumbsup:":                   "👍",
+	":trophy:":                     "🏆",
+	":wave:":                       "👋",
+	":warning:":                    "⚠️",
+	":white_check_mark:":           "✅",
+	":wrench:":                     "🔧",
+	":x:":                          "❌",
+	":zap:":                        "⚡",
+}
+
+// emoji expands emoji shortcodes for chat messages: {msg|emoji} renders "Deployed :rocket:" as
+// "Deployed 🚀". Unknown shortcodes are left as they are.
+func emoji(value interface{}) string {
+	return shortcodeRe.ReplaceAllStringFunc(fmt.Sprint(value), func(code string) string {
+		if e, ok := shortcodes[code]; ok {
+			return e
+		}
+		return code
+	})
+}
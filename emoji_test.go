@@ -0,0 +1,26 @@
+package fstr
+
+import "testing"
+
+func TestEmoji(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{msg: "Deployed :rocket:", want: "Deployed 🚀"},
+		{msg: ":white_check_mark: tests passed :tada::tada:", want: "✅ tests passed 🎉🎉"},
+		{msg: "LGTM :+1:", want: "LGTM 👍"},
+		{msg: "unknown :not_an_emoji: stays", want: "unknown :not_an_emoji: stays"},
+		{msg: "time 10:30:00", want: "time 10:30:00"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{msg|emoji}", map[string]interface{}{"msg": tt.msg})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.msg, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package fstr
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// b64enc encodes a value with standard base64, as Kubernetes secrets expect: {password|b64enc}.
+func b64enc(value interface{}) string {
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(value)))
+}
+
+// b64dec decodes a standard base64 value: {token|b64dec}.
+func b64dec(value interface{}) (string, error) {
+	b, err := base64.StdEncoding.DecodeString(fmt.Sprint(value))
+	if err != nil {
+		return "", &Error{Spec: "b64dec", Err: fmt.Errorf("invalid base64: %w", err), kind: ErrUnsupportedType}
+	}
+	return string(b), nil
+}
+
+// sha256sum returns the hex-encoded SHA-256 digest of a value, for checksum annotations that roll
+// deployments when a config changes: {config|sha256sum}.
+func sha256sum(value interface{}) string {
+	sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,34 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncodingFilters(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{s|b64enc}", want: "czNjcjN0"},
+		{format: "{enc|b64dec}", want: "s3cr3t"},
+		{format: "{s|b64enc|b64dec}", want: "s3cr3t"},
+		{format: "{n|b64enc}", want: "NDI="},
+		{format: "{empty|sha256sum}", want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{format: "{s|sha256sum}", want: "4e738ca5563c06cfd0018299933d58db1dd8bf97f6973dc99bf6cdc64b5550bd"},
+	}
+	data := map[string]interface{}{"s": "s3cr3t", "enc": "czNjcjN0", "n": 42, "empty": ""}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if _, err := Interpolate("{s|b64dec}", map[string]interface{}{"s": "not base64!"}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Interpolate() error = %v, want ErrUnsupportedType", err)
+	}
+}
@@ -0,0 +1,241 @@
+package fstr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// InterpolateEnv interpolates the values of a .env file. See Interpolator.InterpolateEnv.
+func InterpolateEnv(w io.Writer, r io.Reader, data map[string]interface{}) error {
+	return defaultInterpolator.InterpolateEnv(w, r, data)
+}
+
+// InterpolateProperties interpolates the values of a properties file. See Interpolator.InterpolateProperties.
+func InterpolateProperties(w io.Writer, r io.Reader, data map[string]interface{}) error {
+	return defaultInterpolator.InterpolateProperties(w, r, data)
+}
+
+// envLineRe matches a variable assignment of a .env file, capturing everything up to the value,
+// such as "export DB_URL=", and the value with any trailing comment.
+var envLineRe = regexp.MustCompile(`^(\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_.]*\s*=\s*)(.*)$`)
+
+// envSafeRe matches the values that need no quotes in a .env file.
+var envSafeRe = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+// InterpolateEnv reads a .env file from r, interpolates each value with data and writes the result
+// to w, quoting the values that need it, for deployment-time configuration:
+//
+//	DB_URL="postgres://{db_user}@{db_host}/app"  =>  DB_URL=postgres://app@db.internal/app
+//	GREETING=Hello {name}                        =>  GREETING="Hello Ziad Mansour"
+//
+// Values may be unquoted, double-quoted with backslash escapes, or single-quoted; as in shells,
+// single-quoted values are taken literally and not interpolated. Comments, blank lines and the
+// comments following values are kept. To interpolate from the environment, configure the
+// Interpolator WithMissingKeyFunc and os.LookupEnv. The first value that fails to interpolate
+// stops the process and its *Error is returned.
+func (i *Interpolator) InterpolateEnv(w io.Writer, r io.Reader, data map[string]interface{}) error {
+	return eachLine(w, r, func(line string, _ func() (string, bool)) (string, error) {
+		m := envLineRe.FindStringSubmatch(line)
+		if m == nil {
+			return line, nil // a comment, blank line or anything else
+		}
+		value, comment, literal := splitEnvValue(m[2])
+		if literal {
+			return line, nil
+		}
+		result, err := i.Interpolate(value, data)
+		if err != nil {
+			return "", err
+		}
+		return m[1] + quoteEnv(result) + comment, nil
+	})
+}
+
+// splitEnvValue splits the value part of a .env assignment into the value and the trailing comment,
+// including the space before it, removing the quotes and escapes of the value. It reports whether
+// the value is single-quoted.
+func splitEnvValue(s string) (value, comment string, literal bool) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		var b strings.Builder
+		for n := 1; n < len(s); n++ {
+			switch c := s[n]; {
+			case c == '"':
+				return b.String(), s[n+1:], false
+			case c == '\\' && n+1 < len(s):
+				n++
+				switch s[n] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(s[n])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return b.String(), "", false // unterminated
+	case strings.HasPrefix(s, "'"):
+		return "", "", true
+	}
+	if n := strings.Index(s, " #"); n >= 0 {
+		value, comment = s[:n], s[n:]
+	} else {
+		value = s
+	}
+	trimmed := strings.TrimRight(value, " \t")
+	return trimmed, value[len(trimmed):] + comment, false
+}
+
+// quoteEnv quotes a .env value if it holds anything but letters, digits and common punctuation.
+func quoteEnv(s string) string {
+	if envSafeRe.MatchString(s) {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// propertyLineRe matches a property of a properties file, capturing everything up to the value,
+// such as "db.url = ", and the value.
+var propertyLineRe = regexp.MustCompile(`^(\s*(?:[^\s=:\\]|\\.)+(?:\s*[=:]\s*|\s+))(.*)$`)
+
+// InterpolateProperties reads a Java properties file from r, interpolates each value with data and
+// writes the result to w, escaping the values as properties files require:
+//
+//	db.url = jdbc:postgresql://{db_host}/app  =>  db.url = jdbc:postgresql://db.internal/app
+//	motd: Welcome,\n{name}                    =>  motd: Welcome,\nZiad
+//
+// Values continued on the next line with a trailing backslash are joined, and written back on one
+// line. Comments and blank lines are kept. The first value that fails to interpolate stops the
+// process and its *Error is returned.
+func (i *Interpolator) InterpolateProperties(w io.Writer, r io.Reader, data map[string]interface{}) error {
+	return eachLine(w, r, func(line string, next func() (string, bool)) (string, error) {
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			return line, nil
+		}
+		for continued(line) {
+			following, ok := next()
+			if !ok {
+				break
+			}
+			line = line[:len(line)-1] + strings.TrimLeft(following, " \t\f")
+		}
+		m := propertyLineRe.FindStringSubmatch(line)
+		if m == nil {
+			return line, nil // a key without a value
+		}
+		result, err := i.Interpolate(unescapeProperty(m[2]), data)
+		if err != nil {
+			return "", err
+		}
+		return m[1] + escapeProperty(result), nil
+	})
+}
+
+// continued reports whether a line of a properties file continues on the next one: whether it ends
+// with an odd number of backslashes.
+func continued(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+// unescapeProperty removes the escapes of a properties file value, such as \n, \t and \u00e9.
+func unescapeProperty(s string) string {
+	var b strings.Builder
+	for n := 0; n < len(s); n++ {
+		if s[n] != '\\' || n+1 == len(s) {
+			b.WriteByte(s[n])
+			continue
+		}
+		n++
+		switch s[n] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if n+5 <= len(s) {
+				if r, err := strconv.ParseUint(s[n+1:n+5], 16, 16); err == nil {
+					b.WriteRune(rune(r))
+					n += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[n])
+		}
+	}
+	return b.String()
+}
+
+// escapeProperty escapes a properties file value: backslashes, control characters and a leading
+// space. Other characters are written as is, as properties files are read as UTF-8 since Java 9.
+func escapeProperty(s string) string {
+	var b strings.Builder
+	for n, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case r == ' ' && n == 0:
+			b.WriteString(`\ `)
+		case r < ' ':
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// eachLine copies the lines read from r to w, rewriting each one with rewrite, which may consume the
+// following lines with next. Line endings are written as "\n".
+func eachLine(w io.Writer, r io.Reader, rewrite func(line string, next func() (string, bool)) (string, error)) error {
+	scanner := bufio.NewScanner(r)
+	next := func() (string, bool) {
+		if !scanner.Scan() {
+			return "", false
+		}
+		return strings.TrimSuffix(scanner.Text(), "\r"), true
+	}
+	bw := bufio.NewWriter(w)
+	for {
+		line, ok := next()
+		if !ok {
+			break
+		}
+		result, err := rewrite(line, next)
+		if err != nil {
+			return err
+		}
+		bw.WriteString(result)
+		bw.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return &Error{Err: err}
+	}
+	if err := bw.Flush(); err != nil {
+		return &Error{Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package fstr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	data := map[string]interface{}{"host": "db.internal", "name": "Ziad Mansour", "port": 5432, "q": `a"b$c`}
+	in := strings.Join([]string{
+		"# database",
+		"DB_URL=postgres://{host}:{port}/app",
+		`export GREETING="Hello {name}" # shown on login`,
+		"MOTD=Hi {name} # unquoted",
+		"",
+		"RAW='{not interpolated}'",
+		`QUOTED="tab\there {q}"`,
+		"EMPTY=",
+		"not an assignment {host}",
+	}, "\n")
+	want := strings.Join([]string{
+		"# database",
+		"DB_URL=postgres://db.internal:5432/app",
+		`export GREETING="Hello Ziad Mansour" # shown on login`,
+		`MOTD="Hi Ziad Mansour" # unquoted`,
+		"",
+		"RAW='{not interpolated}'",
+		`QUOTED="tab\there a\"b\$c"`,
+		"EMPTY=",
+		"not an assignment {host}",
+	}, "\n") + "\n"
+	var b strings.Builder
+	if err := InterpolateEnv(&b, strings.NewReader(in), data); err != nil {
+		t.Fatalf("InterpolateEnv() error = %v", err)
+	}
+	if b.String() != want {
+		t.Errorf("InterpolateEnv() =\n%s\nwant\n%s", b.String(), want)
+	}
+	if err := New(WithStrictKeys()).InterpolateEnv(&b, strings.NewReader("A={missing}"), data); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("InterpolateEnv() error = %v, want ErrMissingKey", err)
+	}
+}
+
+func TestInterpolateProperties(t *testing.T) {
+	data := map[string]interface{}{"host": "db.internal", "name": "Zoë", "path": `C:\app`}
+	in := strings.Join([]string{
+		"# comment",
+		"! also a comment",
+		"db.url = jdbc:postgresql://{host}/app",
+		`motd: Welcome,\n{name}`,
+		`long.value = first {host} \`,
+		`    second`,
+		`dir {path}`,
+		`key\=with\:seps=\u0041{name}`,
+		"lonely",
+	}, "\n")
+	want := strings.Join([]string{
+		"# comment",
+		"! also a comment",
+		"db.url = jdbc:postgresql://db.internal/app",
+		`motd: Welcome,\nZoë`,
+		`long.value = first db.internal second`,
+		`dir C:\\app`,
+		`key\=with\:seps=AZoë`,
+		"lonely",
+	}, "\n") + "\n"
+	var b strings.Builder
+	if err := InterpolateProperties(&b, strings.NewReader(in), data); err != nil {
+		t.Fatalf("InterpolateProperties() error = %v", err)
+	}
+	if b.String() != want {
+		t.Errorf("InterpolateProperties() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestEscapeProperty(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{in: "plain", want: "plain"},
+		{in: " lead", want: `\ lead`},
+		{in: "a\nb\tc\\", want: `a\nb\tc\\`},
+		{in: "bell\a", want: `bell\u0007`},
+	}
+	for _, tt := range tests {
+		if got := escapeProperty(tt.in); got != tt.want {
+			t.Errorf("escapeProperty(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if got := unescapeProperty(escapeProperty(tt.in)); got != tt.in {
+			t.Errorf("unescapeProperty(escapeProperty(%q)) = %q", tt.in, got)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package fstr
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sentinel errors classify an *Error by the kind of failure. Test for them with errors.Is:
+//
+//	if errors.Is(err, fstr.ErrMissingKey) {
+//		// ask the caller for more data
+//	}
+var (
+	// ErrMissingKey reports a placeholder whose key is not in the data map, for an Interpolator
+	// configured WithStrictKeys, or a secret that cannot be resolved.
+	ErrMissingKey = errors.New("missing key")
+	// ErrBadSpec reports an invalid format specifier or filter argument, such as {n:.xf} or {s|truncate(0)}.
+	ErrBadSpec = errors.New("bad format specifier")
+	// ErrParse reports a format string that cannot be parsed, such as one with an unclosed loop block.
+	ErrParse = errors.New("parse error")
+	// ErrUnsupportedType reports a value that its specifier or filter cannot format, such as a string
+	// given to {n:,.2f} or 4000 given to {n:roman}.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrNoMatch reports a string that does not match the format string it is extracted with.
+	ErrNoMatch = errors.New("no match")
+)
+
+// Error describes a failure to interpolate a format string. Every error returned by this
+// package's functions is an *Error, or several joined by errors.Join for an Interpolator
+// configured WithAllErrors; Eval, Print and Println panic with one.
+//
+// Use errors.As to inspect it:
+//
+//	var fe *fstr.Error
+//	if errors.As(err, &fe) {
+//		log.Printf("bad template %q: %v", fe.Format, fe.Err)
+//	}
+type Error struct {
+	// Format is the format string being interpolated, if known.
+	Format string
+	// Placeholder is the placeholder or loop block tag of Format that failed, such as "{balance:,}",
+	// if known.
+	Placeholder string
+	// Spec is the format specifier that failed, such as ",.2f", if the error concerns one.
+	Spec string
+	// Err is the underlying error.
+	Err error
+
+	kind  error  // the sentinel error classifying the failure, if any
+	field string // the field or map entry holding Format, such as "field Database.Name", for Expand and ExpandMap
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	prefix := "fstr: "
+	if e.field != "" {
+		prefix += e.field + ": "
+	}
+	if e.Placeholder != "" {
+		prefix += e.Placeholder + ": "
+	}
+	if e.Spec != "" {
+		return fmt.Sprintf("%sspec %q: %v", prefix, e.Spec, e.Err)
+	}
+	return prefix + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error, such as ErrMissingKey, that classifies e.
+func (e *Error) Is(target error) bool {
+	return e.kind != nil && e.kind == target
+}
+
+// inField records in each *Error of err that its format string is held by field, such as
+// "field Database.Name". Other errors are wrapped in an *Error naming the field.
+func inField(err error, field string) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			inField(err, field)
+		}
+		return err
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		return &Error{Err: fmt.Errorf("%s: %w", field, err)}
+	}
+	e.field = field
+	return err
+}
+
+// missingKeyRe matches the text/template error reported for a key missing from the data map.
+var missingKeyRe = regexp.MustCompile(`map has no entry for key "((?:[^"\\]|\\.)*)"`)
+
+// execLocationRe matches the location text/template prefixes execution errors with, such as
+// `template: fstr:1:7: executing "fstr" at <.x>: `, capturing its line and column.
+var execLocationRe = regexp.MustCompile(`^template: fstr:(\d+):(\d+): executing "fstr" at <.*?>: `)
+
+// executeError converts an error returned while executing the template parsed from format with data
+// into an *Error, naming the placeholder of format that failed. Errors raised by the formatting functions
+// are already *Error values and are returned as such.
+func executeError(format string, p *parsed, data map[string]interface{}, err error) *Error {
+	placeholder := p.placeholder(err.Error())
+	var e *Error
+	if errors.As(err, &e) {
+		e.Format = format
+		if placeholder != "" {
+			e.Placeholder = placeholder
+		}
+		return e
+	}
+	if m := missingKeyRe.FindStringSubmatch(err.Error()); m != nil {
+		msg := fmt.Sprintf("missing key %q", m[1])
+		if s := suggestKey(m[1], data); s != "" {
+			msg += fmt.Sprintf("; did you mean %q?", s)
+		}
+		return &Error{Format: format, Placeholder: placeholder, Err: errors.New(msg), kind: ErrMissingKey}
+	}
+	return &Error{Format: format, Placeholder: placeholder, Err: fmt.Errorf("failed to execute template: %w", err)}
+}
+
+// placeholder returns the source text of the placeholder or loop block tag at the location reported
+// by msg, an execution error message of the template, or "" if there is none.
+func (p *parsed) placeholder(msg string) string {
+	m := execLocationRe.FindStringSubmatch(msg)
+	if m == nil {
+		return ""
+	}
+	line, _ := strconv.Atoi(m[1])
+	col, _ := strconv.Atoi(m[2])
+	offset := 0
+	for ; line > 1; line-- {
+		n := strings.IndexByte(p.text[offset:], '\n')
+		if n < 0 {
+			return ""
+		}
+		offset += n + 1
+	}
+	offset += col
+	for _, s := range p.sources {
+		if s.start <= offset && offset < s.end {
+			return s.text
+		}
+	}
+	return ""
+}
+
+// suggestKey returns the key of data most similar to the missing key, for a "did you mean" hint:
+// the one with the smallest edit distance, if it is at most 2 and shorter than key itself, preferring
+// the first in sorted order. It returns "" if no key is that similar.
+func suggestKey(key string, data map[string]interface{}) string {
+	best, bestDist := "", min(3, utf8.RuneCountInString(key))
+	for k := range data {
+		d := editDistance(key, k)
+		if d < bestDist || d == bestDist && k < best {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the number of single-rune insertions, deletions, substitutions and
+// transpositions of adjacent runes needed to turn a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// d[i][j] is the distance between the first i runes of a and the first j runes of b.
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
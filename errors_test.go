@@ -0,0 +1,215 @@
+package fstr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	data := map[string]interface{}{"n": 42, "s": "text", "big": 4000, "rows": 1}
+	tests := []struct {
+		format      string
+		want        error
+		placeholder string
+	}{
+		{format: "Hello {name}", want: ErrMissingKey, placeholder: "{name}"},
+		{format: "{name|snake}", want: ErrMissingKey, placeholder: "{name|snake}"},
+		{format: "{s:,.2f}", want: ErrUnsupportedType, placeholder: "{s:,.2f}"},
+		{format: "line\n{n} {big:roman}", want: ErrUnsupportedType, placeholder: "{big:roman}"},
+		{format: "{rows:table}", want: ErrUnsupportedType, placeholder: "{rows:table}"},
+		{format: "{s|truncate(0)}", want: ErrBadSpec, placeholder: "{s|truncate(0)}"},
+		{format: "{n:bar(x)}", want: ErrBadSpec, placeholder: "{n:bar(x)}"},
+		{format: "{#each rows}unclosed", want: ErrParse},
+		{format: "{#each rows sortby=n}{/each}", want: ErrParse, placeholder: "{#each rows sortby=n}"},
+		{format: "{#each rows}{/each}", want: ErrUnsupportedType, placeholder: "{#each rows}"},
+	}
+	kinds := []error{ErrMissingKey, ErrBadSpec, ErrParse, ErrUnsupportedType}
+	strict := New(WithStrictKeys())
+	for _, tt := range tests {
+		_, err := strict.Interpolate(tt.format, data)
+		if err == nil {
+			t.Errorf("Interpolate(%q) error = nil, want %v", tt.format, tt.want)
+			continue
+		}
+		for _, kind := range kinds {
+			if got := errors.Is(err, kind); got != (kind == tt.want) {
+				t.Errorf("errors.Is(Interpolate(%q), %v) = %v, error: %v", tt.format, kind, got, err)
+			}
+		}
+		var e *Error
+		if !errors.As(err, &e) || e.Format != tt.format || e.Placeholder != tt.placeholder {
+			t.Errorf("Interpolate(%q) error = %#v, want *Error for the format and placeholder %q", tt.format, err, tt.placeholder)
+		}
+	}
+}
+
+func TestMissingKeyError(t *testing.T) {
+	_, err := New(WithStrictKeys()).Interpolate("Hello {name}", map[string]interface{}{})
+	if err == nil || err.Error() != `fstr: {name}: missing key "name"` {
+		t.Errorf("Interpolate() error = %v, want missing key", err)
+	}
+	// Without WithStrictKeys, missing keys render "<no value>".
+	if got, err := Interpolate("Hello {name}", map[string]interface{}{}); err != nil || got != "Hello <no value>" {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, "Hello <no value>")
+	}
+}
+
+func TestMissingKeySuggestion(t *testing.T) {
+	data := map[string]interface{}{"balance": 1, "name": "ziad", "id": 7, "ab": 1, "ba": 2}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{ballance}", want: `fstr: {ballance}: missing key "ballance"; did you mean "balance"?`},
+		{format: "{nmae|snake}", want: `fstr: {nmae|snake}: missing key "nmae"; did you mean "name"?`},
+		{format: "{blance:,}", want: `fstr: {blance:,}: missing key "blance"; did you mean "balance"?`},
+		{format: "{aa}", want: `fstr: {aa}: missing key "aa"; did you mean "ab"?`},
+		{format: "{i}", want: `fstr: {i}: missing key "i"`},
+		{format: "{address}", want: `fstr: {address}: missing key "address"`},
+	}
+	strict := New(WithStrictKeys())
+	for _, tt := range tests {
+		_, err := strict.Interpolate(tt.format, data)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Interpolate(%q) error = %v, want %s", tt.format, err, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "balance", b: "balance", want: 0},
+		{a: "ballance", b: "balance", want: 1},
+		{a: "blaance", b: "balance", want: 1},
+		{a: "nmae", b: "name", want: 1},
+		{a: "kitten", b: "sitting", want: 3},
+		{a: "", b: "abc", want: 3},
+		{a: "größe", b: "grösse", want: 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAllErrors(t *testing.T) {
+	interp := New(WithAllErrors(), WithStrictKeys())
+	data := map[string]interface{}{"s": "text", "items": []map[string]interface{}{{"a": 1}}}
+	format := "{a} {s:,.2f} {b} {a} {#each items}{a}{missing}{/each} {s|truncate(0)}"
+
+	_, err := interp.Interpolate(format, data)
+	if err == nil {
+		t.Fatal("Interpolate() error = nil, want errors")
+	}
+	want := `fstr: {a}: missing key "a"` + "\n" +
+		`fstr: {s:,.2f}: spec ",.2f": cannot format string as a number` + "\n" +
+		`fstr: {b}: missing key "b"` + "\n" +
+		`fstr: {missing}: missing key "missing"` + "\n" +
+		`fstr: {s|truncate(0)}: spec "truncate": invalid length "0"`
+	if got := err.Error(); got != want {
+		t.Errorf("Interpolate() error =\n%s\nwant\n%s", got, want)
+	}
+	for _, kind := range []error{ErrMissingKey, ErrUnsupportedType, ErrBadSpec} {
+		if !errors.Is(err, kind) {
+			t.Errorf("errors.Is(err, %v) = false, want true", kind)
+		}
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Format != format {
+		t.Errorf("errors.As(err) = %#v, want *Error for the format", e)
+	}
+
+	// A single problem is returned as a plain *Error.
+	_, err = interp.Interpolate("{s} {b}", data)
+	if !errors.As(err, &e) || err != error(e) {
+		t.Errorf("Interpolate() error = %#v, want *Error", err)
+	}
+	// Parse errors cannot be split up.
+	if _, err = interp.Interpolate("{#each items}{a}", data); !errors.Is(err, ErrParse) {
+		t.Errorf("Interpolate() error = %v, want ErrParse", err)
+	}
+	// Without the option, only the first problem is reported.
+	if _, err = New(WithStrictKeys()).Interpolate(format, data); err == nil || err.Error() != `fstr: {a}: missing key "a"` {
+		t.Errorf("Interpolate() error = %v, want the first error only", err)
+	}
+}
+
+func TestInterpolatePartial(t *testing.T) {
+	data := map[string]interface{}{"name": "ziad", "n": "x", "items": []map[string]interface{}{{"a": 1}}}
+	tests := []struct {
+		name   string
+		interp *Interpolator
+		format string
+		want   string
+		errs   int
+	}{
+		{name: "No errors", interp: New(), format: "user {name}", want: "user ziad"},
+		{name: "Missing key", interp: New(WithStrictKeys()), format: "user {name} failed {action}", want: "user ziad failed {action}", errs: 1},
+		{name: "Bad value", interp: New(), format: "{name}: {n:,.2f} {n:bar(3)}", want: "ziad: {n:,.2f} {n:bar(3)}", errs: 2},
+		{name: "Failing block", interp: New(WithStrictKeys()), format: "[{#each items}{b}{/each}] {name}", want: "[{#each items}{b}{/each}] ziad", errs: 1},
+		{name: "Marker", interp: New(WithPartialMarker("?"), WithStrictKeys()), format: "{name} {a} {b}", want: "ziad ? ?", errs: 2},
+		{name: "Empty marker", interp: New(WithPartialMarker(""), WithStrictKeys()), format: "{name}{a}!", want: "ziad!", errs: 1},
+		{name: "Unclosed block", interp: New(), format: "{#each items}{name}", want: "{#each items}ziad", errs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := tt.interp.InterpolatePartial(tt.format, data)
+			if got != tt.want {
+				t.Errorf("InterpolatePartial() = %q, want %q", got, tt.want)
+			}
+			if len(errs) != tt.errs {
+				t.Errorf("InterpolatePartial() errors = %v, want %d", errs, tt.errs)
+			}
+			for _, err := range errs {
+				var e *Error
+				if !errors.As(err, &e) || e.Format != tt.format {
+					t.Errorf("InterpolatePartial() error = %#v, want *Error for the format", err)
+				}
+			}
+		})
+	}
+	got, errs := New(WithStrictKeys()).InterpolatePartial("user {name} failed {action}", map[string]interface{}{"name": "ziad"})
+	if got != "user ziad failed {action}" || len(errs) != 1 || !errors.Is(errs[0], ErrMissingKey) {
+		t.Errorf("InterpolatePartial() = %q, %v", got, errs)
+	}
+}
+
+func TestErrorHook(t *testing.T) {
+	var reported []string
+	hook := WithErrorHook(func(e *Error) {
+		reported = append(reported, e.Error())
+	})
+
+	interp := New(hook, WithStrictKeys())
+	if _, err := interp.Interpolate("{a}", nil); err == nil {
+		t.Fatal("Interpolate() error = nil, want error")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Eval() did not panic")
+			}
+		}()
+		interp.Eval("{n:roman}", map[string]interface{}{"n": 0})
+	}()
+	if _, err := interp.Interpolate("{ok}", map[string]interface{}{"ok": 1}); err != nil {
+		t.Fatalf("Interpolate() error = %v", err)
+	}
+	want := []string{`fstr: {a}: missing key "a"`, `fstr: {n:roman}: spec "roman": 0 is out of range [1, 3999]`}
+	if !reflect.DeepEqual(reported, want) {
+		t.Errorf("reported %q, want %q", reported, want)
+	}
+
+	reported = nil
+	if _, err := New(hook, WithAllErrors(), WithStrictKeys()).Interpolate("{a} {b}", nil); err == nil {
+		t.Fatal("Interpolate() error = nil, want error")
+	}
+	if want := []string{`fstr: {a}: missing key "a"`, `fstr: {b}: missing key "b"`}; !reflect.DeepEqual(reported, want) {
+		t.Errorf("reported %q, want %q", reported, want)
+	}
+}
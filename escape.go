@@ -0,0 +1,66 @@
+package fstr
+
+import (
+	"fmt"
+	"html"
+)
+
+// EscapeMode selects how the values of placeholders are escaped for the output they are embedded in.
+// See WithEscape.
+type EscapeMode int
+
+const (
+	// EscapeNone renders values as they are. It is the default.
+	EscapeNone EscapeMode = iota
+	// EscapeHTML escapes values for HTML text and quoted attribute values: <, >, &, ' and ".
+	EscapeHTML
+	// EscapeJSON escapes values for the inside of JSON strings, as in {"name": "{name}"}: quotes,
+	// backslashes and control characters.
+	EscapeJSON
+)
+
+// WithEscape sets how the values of placeholders are escaped, so that data cannot inject markup into
+// the output. The format string's own text is trusted and never escaped, and neither is the output
+// of partials, which escape their own placeholders:
+//
+//	interp := fstr.New(fstr.WithEscape(fstr.EscapeHTML))
+//	interp.Eval(`<p title="{title}">Hello {name}</p>`, data)
+//	// name "<b>Bob</b>" => <p title="...">Hello &lt;b&gt;Bob&lt;/b&gt;</p>
+//
+// With EscapeJSON, hand-written JSON templates such as {"msg": "{text}"} stay valid whatever the
+// text holds: quotes, backslashes and line breaks cannot end the string early.
+//
+// The default is EscapeNone.
+func WithEscape(mode EscapeMode) Option {
+	return func(i *Interpolator) {
+		i.escape = mode
+	}
+}
+
+// escapeString escapes s for the mode.
+func (m EscapeMode) escapeString(s string) string {
+	switch m {
+	case EscapeHTML:
+		return html.EscapeString(s)
+	case EscapeJSON:
+		quoted, _ := marshalJSON(s, "", "")
+		return quoted[1 : len(quoted)-1]
+	default:
+		return s
+	}
+}
+
+// escapeValue renders a placeholder's value and escapes it for the mode.
+func (m EscapeMode) escapeValue(value interface{}) string {
+	return m.escapeString(fmt.Sprint(value))
+}
+
+// escapeAction wraps the text/template action rendering a placeholder so that its output is escaped.
+// Tables are not wrapped, as their cells are rendered, and escaped, as placeholders of their own.
+func escapeAction(action string, p placeholder) string {
+	if c, ok := parseNamedSpec(p.spec); ok && (c.name == "table" || c.name == "mdtable") {
+		return action
+	}
+	// example format: {name} => {{escape (.name)}}
+	return "{{escape (" + action[2:len(action)-2] + ")}}"
+}
@@ -0,0 +1,78 @@
+package fstr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEscapeHTML(t *testing.T) {
+	interp := New(WithEscape(EscapeHTML), WithPartials(map[string]string{"bold": "<b>{name}</b>"}))
+	data := map[string]interface{}{
+		"name":  "<i>Tom & Jerry</i>",
+		"n":     1234.5,
+		"rows":  []map[string]interface{}{{"a": "x&y"}},
+		"items": []map[string]interface{}{{"v": "<1>"}, {"v": "2"}},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "<p>{name}</p>", want: "<p>&lt;i&gt;Tom &amp; Jerry&lt;/i&gt;</p>"},
+		{format: `<a title="{name|trimSpace}">`, want: `<a title="&lt;i&gt;Tom &amp; Jerry&lt;/i&gt;">`},
+		{format: "{n:,.2f} {name=}", want: "1,234.50 name=&lt;i&gt;Tom &amp; Jerry&lt;/i&gt;"},
+		{format: "{>bold}", want: "<b>&lt;i&gt;Tom &amp; Jerry&lt;/i&gt;</b>"},
+		{format: "<ul>{#each items}<li>{v}</li>{/each}</ul>", want: "<ul><li>&lt;1&gt;</li><li>2</li></ul>"},
+		{format: `{"<br>"}`, want: "&lt;br&gt;"},
+		{format: "{rows:table}", want: "a\n-------\nx&amp;y"},
+	}
+	for _, tt := range tests {
+		got, err := interp.Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	// Escaping is off by default.
+	if got := Eval("{name}", data); got != "<i>Tom & Jerry</i>" {
+		t.Errorf("Eval() = %q", got)
+	}
+	// Errors still name the placeholder.
+	if _, err := New(WithEscape(EscapeHTML), WithStrictKeys()).Interpolate("<p>{nme}</p>", data); err == nil || err.Error() != `fstr: {nme}: missing key "nme"; did you mean "name"?` {
+		t.Errorf("Interpolate() error = %v", err)
+	}
+}
+
+func TestEscapeJSON(t *testing.T) {
+	interp := New(WithEscape(EscapeJSON))
+	data := map[string]interface{}{"name": "Say \"hi\"\n\t\\ <b>", "n": 42}
+	got, err := interp.Interpolate(`{"name": "{name}", "n": {n}}`, data)
+	if want := `{"name": "Say \"hi\"\n\t\\ <b>", "n": 42}`; err != nil || got != want {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, want)
+	}
+
+	// Values cannot break out of their string.
+	format := `{"user":"{user}","msg":"{msg}","admin":false}`
+	for _, msg := range []string{
+		`", "admin": true, "x": "`,
+		"line\nbreak\r\u2028\u0000",
+		`\", "admin": true}` + "\n",
+		"\xff invalid",
+	} {
+		got, err := interp.Interpolate(format, map[string]interface{}{"user": "bob", "msg": msg})
+		if err != nil {
+			t.Fatalf("Interpolate() error = %v", err)
+		}
+		var v map[string]interface{}
+		if err := json.Unmarshal([]byte(got), &v); err != nil {
+			t.Errorf("Interpolate() = %q, not valid JSON: %v", got, err)
+			continue
+		}
+		if len(v) != 3 || v["admin"] != false || v["user"] != "bob" || v["msg"] != strings.ToValidUTF8(msg, "\uFFFD") {
+			t.Errorf("Interpolate() = %q, decoded as %v", got, v)
+		}
+	}
+}
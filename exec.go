@@ -0,0 +1,111 @@
+package fstr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Default limits of the exec filter; see WithExecLimits.
+const (
+	defaultExecTimeout   = 5 * time.Second
+	defaultExecMaxOutput = 64 << 10
+)
+
+// execWaitDelay bounds how long the exec filter waits for the output of a command to be closed after
+// the command exits or is killed, which background processes started by it could otherwise delay.
+const execWaitDelay = time.Second
+
+// errOutputTooLarge reports a command whose output exceeds the exec filter's limit.
+var errOutputTooLarge = errors.New("output too large")
+
+// WithExec enables the exec filter for the given command lines, so that ops report templates can
+// include values such as the current commit: {cmd|exec} runs the command line that is the value of
+// cmd and renders its standard output, without the trailing newline.
+//
+//	interp := fstr.New(fstr.WithExec("git rev-parse HEAD", "hostname -f"))
+//	report := interp.Eval("Deployed {rev|exec} on {host|exec}", map[string]interface{}{
+//		"rev":  "git rev-parse HEAD",
+//		"host": "hostname -f",
+//	})
+//
+// Only the listed command lines run, compared word for word, and never through a shell. Any other
+// command, or the exec filter of an Interpolator without WithExec, fails with an ErrBadSpec *Error,
+// as does a command that fails, times out or writes too much; see WithExecLimits.
+func WithExec(commands ...string) Option {
+	return func(i *Interpolator) {
+		if i.commands == nil {
+			i.commands = make(map[string]bool, len(commands))
+		}
+		for _, c := range commands {
+			i.commands[strings.Join(strings.Fields(c), " ")] = true
+		}
+	}
+}
+
+// WithExecLimits sets how long a command run by the exec filter may take and how many bytes it may
+// write to its standard output. The defaults are 5 seconds and 64 KiB.
+func WithExecLimits(timeout time.Duration, maxOutput int) Option {
+	return func(i *Interpolator) {
+		i.execTimeout = timeout
+		i.execMaxOutput = maxOutput
+	}
+}
+
+// exec runs the command line given by value, if allowed with WithExec, and returns its output.
+func (i *Interpolator) exec(value interface{}) (string, error) {
+	args := strings.Fields(fmt.Sprint(value))
+	line := strings.Join(args, " ")
+	if len(args) == 0 || !i.commands[line] {
+		return "", &Error{Spec: "exec", Err: fmt.Errorf("command %q is not allowed", line), kind: ErrBadSpec}
+	}
+	timeout, limit := i.execTimeout, i.execMaxOutput
+	if timeout <= 0 {
+		timeout = defaultExecTimeout
+	}
+	if limit <= 0 {
+		limit = defaultExecMaxOutput
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	stdout := &cappedBuffer{max: limit, cancel: cancel}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = stdout
+	cmd.WaitDelay = execWaitDelay
+	err := cmd.Run()
+	switch {
+	case stdout.exceeded:
+		err = fmt.Errorf("command %q: %w: more than %d bytes", line, errOutputTooLarge, limit)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = fmt.Errorf("command %q timed out after %v", line, timeout)
+	case err != nil:
+		err = fmt.Errorf("command %q: %w", line, err)
+	}
+	if err != nil {
+		return "", &Error{Spec: "exec", Err: err, kind: ErrBadSpec}
+	}
+	return strings.TrimSuffix(stdout.buf.String(), "\n"), nil
+}
+
+// cappedBuffer collects the output of a command up to max bytes, and cancels the command if it
+// writes more.
+type cappedBuffer struct {
+	buf      bytes.Buffer
+	max      int
+	cancel   func()
+	exceeded bool
+}
+
+// Write implements io.Writer.
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.max {
+		b.exceeded = true
+		b.cancel()
+		return 0, errOutputTooLarge
+	}
+	return b.buf.Write(p)
+}
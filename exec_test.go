@@ -0,0 +1,75 @@
+package fstr
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecFilter(t *testing.T) {
+	for _, name := range []string{"echo", "sleep", "seq", "false"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s not available: %v", name, err)
+		}
+	}
+	interp := New(
+		WithExec("echo hello  world", "sleep 5", "seq 1 100000", "false"),
+		WithExecLimits(200*time.Millisecond, 1024),
+	)
+	tests := []struct {
+		cmd  string
+		want string
+		err  string
+	}{
+		{cmd: "echo hello world", want: "hello world"},
+		{cmd: " echo  hello world ", want: "hello world"},
+		{cmd: "echo hello", err: `command "echo hello" is not allowed`},
+		{cmd: "echo hello world; rm -rf /", err: "is not allowed"},
+		{cmd: "", err: "is not allowed"},
+		{cmd: "sleep 5", err: "timed out after 200ms"},
+		{cmd: "seq 1 100000", err: "output too large: more than 1024 bytes"},
+		{cmd: "false", err: "exit status 1"},
+	}
+	for _, tt := range tests {
+		got, err := interp.Interpolate("{cmd|exec}", map[string]interface{}{"cmd": tt.cmd})
+		if tt.err != "" {
+			if !errors.Is(err, ErrBadSpec) || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Interpolate(%q) error = %v, want %q", tt.cmd, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, %v, want %q", tt.cmd, got, err, tt.want)
+		}
+	}
+	if _, err := Interpolate("{cmd|exec}", map[string]interface{}{"cmd": "echo hello world"}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("Interpolate() error = %v, want ErrBadSpec without WithExec", err)
+	}
+}
+
+func TestExecWaitDelay(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+	// The background sleep keeps the output open after the script is killed.
+	script := filepath.Join(t.TempDir(), "orphan.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 30 &\nwait\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	interp := New(WithExec(script), WithExecLimits(100*time.Millisecond, 1024))
+	start := time.Now()
+	if _, err := interp.Interpolate("{cmd|exec}", map[string]interface{}{"cmd": script}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("Interpolate() error = %v, want ErrBadSpec", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Interpolate() took %v, want the output closed after the wait delay", elapsed)
+	}
+}
@@ -0,0 +1,167 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Expand interpolates every string of the value ptr points to in place. See Interpolator.Expand.
+func Expand(ptr interface{}, data map[string]interface{}) error {
+	return defaultInterpolator.Expand(ptr, data)
+}
+
+// Expand interpolates every string of the value ptr points to in place, with data: the string fields
+// of a struct, and the strings within its nested structs, pointers, slices, arrays and maps. This
+// makes templated configuration structs, such as those decoded from YAML, ready to use in one call:
+//
+//	var cfg Config
+//	if err := yaml.Unmarshal(src, &cfg); err != nil {
+//		return err
+//	}
+//	if err := fstr.Expand(&cfg, map[string]interface{}{"env": "prod"}); err != nil {
+//		return err
+//	}
+//	// cfg.Database.Name: "orders-{env}" => "orders-prod"
+//
+// Unexported fields and fields tagged `fstr:"-"` are skipped, as are map keys. The first string that
+// fails to interpolate stops the process and an *Error naming its field, such as Database.Hosts[1],
+// is returned; the strings expanded before it keep their new values. Expand returns an
+// ErrUnsupportedType *Error if ptr is not a non-nil pointer.
+func (i *Interpolator) Expand(ptr interface{}, data map[string]interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return &Error{Err: fmt.Errorf("cannot expand %T: not a non-nil pointer", ptr), kind: ErrUnsupportedType}
+	}
+	return i.expand(v.Elem(), "", data, make(map[uintptr]bool))
+}
+
+// ExpandMap returns a copy of m with every value interpolated. See Interpolator.ExpandMap.
+func ExpandMap(m map[string]string, data map[string]interface{}) (map[string]string, error) {
+	return defaultInterpolator.ExpandMap(m, data)
+}
+
+// ExpandMapKeys returns a copy of m with every key and value interpolated. See Interpolator.ExpandMapKeys.
+func ExpandMapKeys(m map[string]string, data map[string]interface{}) (map[string]string, error) {
+	return defaultInterpolator.ExpandMapKeys(m, data)
+}
+
+// ExpandMap returns a copy of m with every value interpolated with data, for templating label sets,
+// HTTP headers and environment blocks in one call:
+//
+//	labels, err := interp.ExpandMap(map[string]string{"app": "{service}", "env": "{env}"}, data)
+//
+// m itself is not modified. The first value that fails to interpolate, in key order, stops the process
+// and an *Error naming its key is returned.
+func (i *Interpolator) ExpandMap(m map[string]string, data map[string]interface{}) (map[string]string, error) {
+	return i.expandMap(m, data, false)
+}
+
+// ExpandMapKeys is like ExpandMap but interpolates the keys of m as well, as in
+// {"x-{tenant}-id": "{id}"}. Two keys expanding to the same key yield an *Error.
+func (i *Interpolator) ExpandMapKeys(m map[string]string, data map[string]interface{}) (map[string]string, error) {
+	return i.expandMap(m, data, true)
+}
+
+// expandMap implements ExpandMap and ExpandMapKeys.
+func (i *Interpolator) expandMap(m map[string]string, data map[string]interface{}, keys bool) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	result := make(map[string]string, len(m))
+	from := make(map[string]string, len(m)) // the key of m each key of result comes from
+	for _, k := range names {
+		key, value := k, m[k]
+		var err error
+		if keys && strings.Contains(key, "{") {
+			if key, err = i.Interpolate(k, data); err != nil {
+				return nil, inField(err, fmt.Sprintf("key %q", k))
+			}
+		}
+		if strings.Contains(value, "{") {
+			if value, err = i.Interpolate(value, data); err != nil {
+				return nil, inField(err, fmt.Sprintf("value of %q", k))
+			}
+		}
+		if prev, ok := from[key]; ok {
+			return nil, &Error{Err: fmt.Errorf("keys %q and %q both expand to %q", prev, k, key)}
+		}
+		from[key] = k
+		result[key] = value
+	}
+	return result, nil
+}
+
+// expand implements Expand for v, a settable value at path. visited holds the pointers already
+// followed, so that cyclic structures are expanded once.
+func (i *Interpolator) expand(v reflect.Value, path string, data map[string]interface{}, visited map[uintptr]bool) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || visited[v.Pointer()] {
+			return nil
+		}
+		visited[v.Pointer()] = true
+		return i.expand(v.Elem(), path, data, visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		// The dynamic value of an interface cannot be set in place; expand a copy.
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := i.expand(elem, path, data, visited); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		if !strings.Contains(v.String(), "{") {
+			return nil
+		}
+		s, err := i.Interpolate(v.String(), data)
+		if err != nil {
+			if path == "" {
+				return err
+			}
+			return inField(err, "field "+path)
+		}
+		v.SetString(s)
+	case reflect.Struct:
+		t := v.Type()
+		for n := 0; n < t.NumField(); n++ {
+			if _, ok := fieldName(t.Field(n)); !ok {
+				continue
+			}
+			name := t.Field(n).Name
+			if path != "" {
+				name = path + "." + name
+			}
+			if err := i.expand(v.Field(n), name, data, visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for n := 0; n < v.Len(); n++ {
+			if err := i.expand(v.Index(n), fmt.Sprintf("%s[%d]", path, n), data, visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values cannot be set in place either.
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := i.expand(elem, fmt.Sprintf("%s[%v]", path, iter.Key()), data, visited); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+	return nil
+}
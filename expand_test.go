@@ -0,0 +1,124 @@
+package fstr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	type database struct {
+		Name  string
+		Hosts []string
+		Port  int
+	}
+	type config struct {
+		Service  string
+		Database *database
+		Labels   map[string]string
+		Extra    map[string]interface{}
+		Replicas [2]string
+		Raw      string `fstr:"-"`
+		note     string
+	}
+	cfg := config{
+		Service:  "api-{env}",
+		Database: &database{Name: "orders_{env}", Hosts: []string{"db1.{region}", "db2.{region}"}, Port: 5432},
+		Labels:   map[string]string{"env": "{env}", "team": "payments"},
+		Extra:    map[string]interface{}{"tags": []interface{}{"{env}", 3}, "debug": false},
+		Replicas: [2]string{"{region}-a", "{region}-b"},
+		Raw:      "{env}",
+		note:     "{env}",
+	}
+	data := map[string]interface{}{"env": "prod", "region": "eu"}
+	if err := Expand(&cfg, data); err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	want := config{
+		Service:  "api-prod",
+		Database: &database{Name: "orders_prod", Hosts: []string{"db1.eu", "db2.eu"}, Port: 5432},
+		Labels:   map[string]string{"env": "prod", "team": "payments"},
+		Extra:    map[string]interface{}{"tags": []interface{}{"prod", 3}, "debug": false},
+		Replicas: [2]string{"eu-a", "eu-b"},
+		Raw:      "{env}",
+		note:     "{env}",
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Expand() =\n%+v\nwant\n%+v", cfg, want)
+	}
+
+	cfg = config{Database: &database{Hosts: []string{"ok", "{missing}"}}}
+	err := New(WithStrictKeys()).Expand(&cfg, data)
+	wantErr := `fstr: field Database.Hosts[1]: {missing}: missing key "missing"`
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Expand() error = %v, want %s", err, wantErr)
+	}
+	if !errors.Is(err, ErrMissingKey) {
+		t.Errorf("errors.Is(err, ErrMissingKey) = false, error: %v", err)
+	}
+	if e := (*Error)(nil); !errors.As(err, &e) || e.Format != "{missing}" || e.Placeholder != "{missing}" {
+		t.Errorf("Expand() error = %#v, want the *Error of the failing string", err)
+	}
+	cfg = config{Database: &database{Name: "{a} {b}"}}
+	err = New(WithStrictKeys(), WithAllErrors()).Expand(&cfg, data)
+	wantErr = `fstr: field Database.Name: {a}: missing key "a"` + "\n" + `fstr: field Database.Name: {b}: missing key "b"`
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Expand() error = %v, want %s", err, wantErr)
+	}
+	if err := Expand(cfg, data); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Expand(non-pointer) error = %v, want ErrUnsupportedType", err)
+	}
+
+	// Cyclic structures are expanded once.
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "{env}"}
+	n.Next = n
+	if err := Expand(n, data); err != nil || n.Name != "prod" {
+		t.Errorf("Expand() = %+v, %v", n, err)
+	}
+}
+
+func TestExpandMap(t *testing.T) {
+	data := map[string]interface{}{"service": "api", "env": "prod", "tenant": "acme", "id": 42}
+	m := map[string]string{"app": "{service}", "env": "{env}", "team": "payments", "x-{tenant}-id": "{id}"}
+
+	got, err := ExpandMap(m, data)
+	want := map[string]string{"app": "api", "env": "prod", "team": "payments", "x-{tenant}-id": "42"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandMap() = %v, %v, want %v", got, err, want)
+	}
+	got, err = ExpandMapKeys(m, data)
+	want = map[string]string{"app": "api", "env": "prod", "team": "payments", "x-acme-id": "42"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandMapKeys() = %v, %v, want %v", got, err, want)
+	}
+	if m["app"] != "{service}" {
+		t.Errorf("ExpandMap() modified the map: %v", m)
+	}
+	if got, err := ExpandMap(nil, data); got != nil || err != nil {
+		t.Errorf("ExpandMap(nil) = %v, %v", got, err)
+	}
+
+	tests := []struct {
+		m    map[string]string
+		keys bool
+		want string
+	}{
+		{m: map[string]string{"a": "ok", "b": "{missing}"}, want: `fstr: value of "b": {missing}: missing key "missing"`},
+		{m: map[string]string{"{missing}": "x"}, keys: true, want: `fstr: key "{missing}": {missing}: missing key "missing"`},
+		{m: map[string]string{"{env}": "a", "prod": "b"}, keys: true, want: `fstr: keys "prod" and "{env}" both expand to "prod"`},
+	}
+	for _, tt := range tests {
+		strict := New(WithStrictKeys())
+		expand := strict.ExpandMap
+		if tt.keys {
+			expand = strict.ExpandMapKeys
+		}
+		if _, err := expand(tt.m, data); err == nil || err.Error() != tt.want {
+			t.Errorf("expanding %v: error = %v, want %s", tt.m, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package fstr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Extract is the reverse of Interpolate: it matches s, a string rendered from format, back against
+// format and returns the text each placeholder was rendered as, by key.
+//
+// Example usage:
+//
+//	values, err := fstr.Extract("{method} /users/{id} took {ms}ms", "GET /users/42 took 7ms")
+//	// values: map[id:42 method:GET ms:7]
+//
+// Each placeholder captures the shortest text that lets the rest of format match, which may be
+// empty unless the placeholder's spec constrains it; see ToRegexp. A key used more than once must
+// capture the same text each time. Loop blocks, partials and the {*} placeholder cannot be
+// extracted from and yield an ErrParse *Error; a string that does not match yields an ErrNoMatch *Error.
+func Extract(format, s string) (map[string]string, error) {
+	p, err := CompilePattern(format)
+	if err != nil {
+		return nil, err
+	}
+	return p.Extract(s)
+}
+
+// Match reports whether s could have been rendered from format, as tested by Extract.
+// It reports false if format cannot be extracted from.
+func Match(format, s string) bool {
+	p, err := CompilePattern(format)
+	return err == nil && p.Match(s)
+}
+
+// Pattern is a format string compiled for matching, to test many strings against the same format
+// efficiently, for instance to route inbound commands:
+//
+//	var deploy = fstr.MustCompilePattern("/deploy {service} to {env}")
+//
+//	if values, err := deploy.Extract(text); err == nil {
+//		// values["service"], values["env"]
+//	}
+//
+// A Pattern is safe for concurrent use by multiple goroutines.
+type Pattern struct {
+	format   string
+	re       *regexp.Regexp
+	repeated bool // some key is captured more than once
+}
+
+// CompilePattern compiles format for Match and Extract. It returns an ErrParse *Error if format holds
+// loop blocks, partials or the {*} placeholder.
+func CompilePattern(format string) (*Pattern, error) {
+	re, err := extractRegexp(format)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pattern{format: format, re: re}
+	seen := make(map[string]bool)
+	for _, key := range re.SubexpNames() {
+		if key != "" {
+			p.repeated = p.repeated || seen[key]
+			seen[key] = true
+		}
+	}
+	return p, nil
+}
+
+// MustCompilePattern is like CompilePattern but panics if format cannot be compiled.
+func MustCompilePattern(format string) *Pattern {
+	p, err := CompilePattern(format)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match reports whether s could have been rendered from the pattern's format.
+func (p *Pattern) Match(s string) bool {
+	if !p.repeated {
+		return p.re.MatchString(s)
+	}
+	_, err := extract(p.format, p.re, s)
+	return err == nil
+}
+
+// Extract returns the text each placeholder of the pattern's format was rendered as in s, by key,
+// like the package-level Extract.
+func (p *Pattern) Extract(s string) (map[string]string, error) {
+	return extract(p.format, p.re, s)
+}
+
+// String returns the format string the pattern was compiled from.
+func (p *Pattern) String() string {
+	return p.format
+}
+
+// ToRegexp converts format into a regular expression matching the strings rendered from it, with a
+// capture group named after the key of each placeholder, to use fstr templates in regexp-based code:
+//
+//	re, err := fstr.ToRegexp("{user} paid {amount:,.2f} in {count:,} parts")
+//	// re: (?s)^(?P<user>.*?) paid (?P<amount>-?[0-9]{1,3}(?:,[0-9]{3})*\.[0-9]{2}|NaN|-?∞) in ...$
+//
+// The regular expression matches whole strings. Placeholders with a numeric spec capture only numbers
+// as that spec renders them, those with the roman spec only Roman numerals, and all others the shortest
+// text that lets the rest of format match. A key used more than once names a group each time. Loop
+// blocks, partials and the {*} placeholder cannot be converted and yield an ErrParse *Error.
+func ToRegexp(format string) (*regexp.Regexp, error) {
+	return extractRegexp(format)
+}
+
+// extractRegexp compiles the regular expression matching the output of format, with a named capture
+// group for each placeholder's key.
+func extractRegexp(format string) (*regexp.Regexp, error) {
+	s := trimMarkers(format)
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+	last := 0
+	for _, loc := range tokenRe.FindAllStringIndex(s, -1) {
+		m := s[loc[0]:loc[1]]
+		if blockRe.MatchString(m) {
+			return nil, &Error{Format: format, Placeholder: m, Err: fmt.Errorf("cannot extract from loop blocks"), kind: ErrParse}
+		}
+		if partialRe.MatchString(m) {
+			return nil, &Error{Format: format, Placeholder: m, Err: fmt.Errorf("cannot extract from partials"), kind: ErrParse}
+		}
+		if namedBlockRe.MatchString(m) {
+			b.WriteString(regexp.QuoteMeta(s[last:loc[0]]))
+			last = loc[1]
+			continue // a block renders its content
+		}
+		p, ok := parsePlaceholder(m)
+		if !ok {
+			continue // rendered as written
+		}
+		b.WriteString(regexp.QuoteMeta(s[last:loc[0]]))
+		last = loc[1]
+		switch {
+		case p.key == "*":
+			return nil, &Error{Format: format, Placeholder: m, Err: fmt.Errorf("cannot extract from {*}"), kind: ErrParse}
+		case p.secret:
+			b.WriteString(`.*?`) // secrets are not extracted
+		case p.literal:
+			value, err := defaultInterpolator.render(m, nil, renderMode{})
+			if err != nil {
+				return nil, err
+			}
+			b.WriteString(regexp.QuoteMeta(value))
+		default:
+			if p.debug {
+				b.WriteString(regexp.QuoteMeta(p.key + "="))
+			}
+			b.WriteString(capturePattern(p.key, p.spec))
+		}
+	}
+	b.WriteString(regexp.QuoteMeta(s[last:]))
+	b.WriteString(`$`)
+	return regexp.MustCompile(b.String()), nil
+}
+
+// capturePattern returns the regular expression capturing the value of a placeholder with the given
+// key and spec. Numeric specs and the roman spec constrain the value; padding to a width is matched
+// outside the group.
+func capturePattern(key, spec string) string {
+	if spec == "roman" {
+		return fmt.Sprintf(`(?P<%s>[IVXLCDM]+)`, key)
+	}
+	ns, err := parseNumberSpec(spec)
+	if err != nil {
+		return fmt.Sprintf(`(?P<%s>.*?)`, key)
+	}
+	digits := `[0-9]+`
+	if ns.group != "" {
+		digits = `[0-9]{1,3}(?:` + regexp.QuoteMeta(ns.group) + `[0-9]{3})*`
+	}
+	switch {
+	case ns.exp != 0 && ns.super:
+		digits = `[0-9](?:\.[0-9]+)?×10⁻?[⁰¹²³⁴⁵⁶⁷⁸⁹]+`
+	case ns.exp != 0:
+		digits = `[0-9](?:\.[0-9]+)?[eE][-+]?[0-9]+`
+	case ns.sig:
+		digits += `(?:\.[0-9]+)?`
+	case ns.precision > 0:
+		digits += fmt.Sprintf(`\.[0-9]{%d}`, ns.precision)
+	case ns.precision < 0:
+		digits += `(?:\.[0-9]+)?`
+	}
+	sign, pad := `-?`, ""
+	if ns.width > 0 && !ns.zero {
+		if ns.align == '=' {
+			sign = `-? *` // padded between the sign and the digits
+		} else {
+			pad = ` *`
+		}
+	}
+	return fmt.Sprintf(`%s(?P<%s>%s%s|NaN|-?∞)%s`, pad, key, sign, digits, pad)
+}
+
+// extract matches s against re, compiled from format by extractRegexp, and returns the captured values by key.
+func extract(format string, re *regexp.Regexp, s string) (map[string]string, error) {
+	matches := re.FindStringSubmatch(s)
+	if matches == nil {
+		return nil, &Error{Format: format, Err: fmt.Errorf("%q does not match", s), kind: ErrNoMatch}
+	}
+	values := make(map[string]string)
+	for n, key := range re.SubexpNames() {
+		if key == "" {
+			continue
+		}
+		if v, ok := values[key]; ok && v != matches[n] {
+			return nil, &Error{Format: format, Err: fmt.Errorf("%q does not match: %q and %q differ for key %q", s, v, matches[n], key), kind: ErrNoMatch}
+		}
+		values[key] = matches[n]
+	}
+	return values, nil
+}
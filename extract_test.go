@@ -0,0 +1,192 @@
+package fstr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		s      string
+		want   map[string]string
+		err    error
+	}{
+		{
+			name:   "Log line",
+			format: "{method} /users/{id} took {ms}ms",
+			s:      "GET /users/42 took 7ms",
+			want:   map[string]string{"method": "GET", "id": "42", "ms": "7"},
+		},
+		{
+			name:   "File name",
+			format: "report-{year}-{month:02}.csv",
+			s:      "report-2024-03.csv",
+			want:   map[string]string{"year": "2024", "month": "03"},
+		},
+		{
+			name:   "Regexp metacharacters",
+			format: "({a}) [{b}]*",
+			s:      "(x.y) [1+2]*",
+			want:   map[string]string{"a": "x.y", "b": "1+2"},
+		},
+		{
+			name:   "Debug form and literal",
+			format: `{user=} {"-"|repeat(3)} {balance:,.2f}`,
+			s:      "user=ziad --- 1,234.50",
+			want:   map[string]string{"user": "ziad", "balance": "1,234.50"},
+		},
+		{
+			name:   "Repeated key",
+			format: "{a}/{b}/{a}",
+			s:      "x/y/x",
+			want:   map[string]string{"a": "x", "b": "y"},
+		},
+		{name: "Multi-line value", format: "msg: {msg}", s: "msg: a\nb", want: map[string]string{"msg": "a\nb"}},
+		{name: "Unknown filter kept as written", format: "{a|nope} {b}", s: "{a|nope} 1", want: map[string]string{"b": "1"}},
+		{name: "No placeholders", format: "static", s: "static", want: map[string]string{}},
+		{name: "No match", format: "{a}-{b}", s: "a_b", err: ErrNoMatch},
+		{name: "Repeated key mismatch", format: "{a}/{a}", s: "x/y", err: ErrNoMatch},
+		{name: "Loop block", format: "{#each items}{a}{/each}", s: "x", err: ErrParse},
+		{name: "Expand all", format: "{*}", s: "a=1", err: ErrParse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Extract(tt.format, tt.s)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Extract() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractRoundTrip(t *testing.T) {
+	format := "{name} owes {balance:,.2f} since {date}"
+	data := map[string]interface{}{"name": "Ziad Mansour", "balance": 1234.5, "date": "2024-01-02"}
+	s := Eval(format, data)
+	got, err := Extract(format, s)
+	if err != nil {
+		t.Fatalf("Extract(%q) error = %v", s, err)
+	}
+	if want := map[string]string{"name": "Ziad Mansour", "balance": "1,234.50", "date": "2024-01-02"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract(%q) = %v, want %v", s, got, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		format string
+		s      string
+		want   bool
+	}{
+		{format: "/deploy {service} to {env}", s: "/deploy api to prod", want: true},
+		{format: "/deploy {service} to {env}", s: "/rollback api", want: false},
+		{format: "{a}/{a}", s: "x/x", want: true},
+		{format: "{a}/{a}", s: "x/y", want: false},
+		{format: "{#each items}{/each}", s: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.format, tt.s); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.format, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestPattern(t *testing.T) {
+	deploy := MustCompilePattern("/deploy {service} to {env}")
+	if !deploy.Match("/deploy api to prod") || deploy.Match("/deploy api") {
+		t.Error("Match() did not tell the commands apart")
+	}
+	got, err := deploy.Extract("/deploy billing to staging")
+	if want := map[string]string{"service": "billing", "env": "staging"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() = %v, %v, want %v", got, err, want)
+	}
+	if deploy.String() != "/deploy {service} to {env}" {
+		t.Errorf("String() = %q", deploy.String())
+	}
+	if _, err := CompilePattern("{*}"); !errors.Is(err, ErrParse) {
+		t.Errorf("CompilePattern() error = %v, want ErrParse", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompilePattern() did not panic")
+		}
+	}()
+	MustCompilePattern("{#each items}{/each}")
+}
+
+func TestToRegexp(t *testing.T) {
+	tests := []struct {
+		format string
+		s      string
+		want   map[string]string // nil if s must not match
+	}{
+		{format: "{a}{n:.2f}", s: "x1.50", want: map[string]string{"a": "x", "n": "1.50"}},
+		{format: "{n:,} items", s: "1,234,567 items", want: map[string]string{"n": "1,234,567"}},
+		{format: "{n:,} items", s: "12,34 items"},
+		{format: "{n:.2f}", s: "1.5"},
+		{format: "{n:.0f}", s: "-42", want: map[string]string{"n": "-42"}},
+		{format: "[{n:6.1f}]", s: "[   3.1]", want: map[string]string{"n": "3.1"}},
+		{format: "[{n:<6}]", s: "[12    ]", want: map[string]string{"n": "12"}},
+		{format: "[{n:=6}]", s: "[-   12]", want: map[string]string{"n": "-   12"}},
+		{format: "{n:05}", s: "00042", want: map[string]string{"n": "00042"}},
+		{format: "{n:.1f}", s: "NaN", want: map[string]string{"n": "NaN"}},
+		{format: "Chapter {n:roman}", s: "Chapter XIV", want: map[string]string{"n": "XIV"}},
+		{format: "Chapter {n:roman}", s: "Chapter 14"},
+		{format: "{word}: {n:,}", s: "total: 1,000", want: map[string]string{"word": "total", "n": "1,000"}},
+	}
+	for _, tt := range tests {
+		re, err := ToRegexp(tt.format)
+		if err != nil {
+			t.Fatalf("ToRegexp(%q) error = %v", tt.format, err)
+		}
+		m := re.FindStringSubmatch(tt.s)
+		if tt.want == nil {
+			if m != nil {
+				t.Errorf("ToRegexp(%q) = %s matches %q", tt.format, re, tt.s)
+			}
+			continue
+		}
+		if m == nil {
+			t.Errorf("ToRegexp(%q) = %s does not match %q", tt.format, re, tt.s)
+			continue
+		}
+		got := make(map[string]string)
+		for n, name := range re.SubexpNames() {
+			if name != "" {
+				got[name] = m[n]
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ToRegexp(%q) captured %v from %q, want %v", tt.format, got, tt.s, tt.want)
+		}
+	}
+	if _, err := ToRegexp("{#each items}{/each}"); !errors.Is(err, ErrParse) {
+		t.Errorf("ToRegexp() error = %v, want ErrParse", err)
+	}
+}
+
+func TestToRegexpRoundTrip(t *testing.T) {
+	format := "{n:,.2f}|{n:09,}|{n:>12.3f}|{n:.2f!e}|{i:roman}"
+	re, err := ToRegexp(format)
+	if err != nil {
+		t.Fatalf("ToRegexp() error = %v", err)
+	}
+	for _, v := range []interface{}{0, -1234.5678, 0.125, 987654321} {
+		s := Eval(format, map[string]interface{}{"n": v, "i": 1994})
+		if !re.MatchString(s) {
+			t.Errorf("ToRegexp(%q) = %s does not match %q", format, re, s)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package fstr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WithFileAccess enables the file filter, which inlines the contents of a file below root at render
+// time, for assembling configs and MOTD-style messages from fragments: {path|file} renders the file
+// whose path, relative to root, is the value of path.
+//
+//	interp := fstr.New(fstr.WithFileAccess("/etc/motd.d"))
+//	motd := interp.Eval("{banner|file}\nWelcome to {host}", data)
+//
+// Paths that are absolute, or that lead outside root through ".." or a symbolic link, fail with an
+// *Error, as does the file filter of an Interpolator without file access. Files are read every time
+// the placeholder renders, and inlined as they are, trailing newline included.
+func WithFileAccess(root string) Option {
+	return func(i *Interpolator) {
+		if abs, err := filepath.Abs(root); err == nil {
+			root = abs
+		}
+		if real, err := filepath.EvalSymlinks(root); err == nil {
+			root = real
+		}
+		i.fileRoot = root
+	}
+}
+
+// file returns the contents of the file at the path given by value, relative to the root set with
+// WithFileAccess.
+func (i *Interpolator) file(value interface{}) (string, error) {
+	if i.fileRoot == "" {
+		return "", &Error{Spec: "file", Err: fmt.Errorf("file access is not enabled"), kind: ErrBadSpec}
+	}
+	name := fmt.Sprint(value)
+	if !filepath.IsLocal(name) {
+		return "", &Error{Spec: "file", Err: fmt.Errorf("path %q is outside the root", name), kind: ErrBadSpec}
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(i.fileRoot, name))
+	if err != nil {
+		return "", &Error{Spec: "file", Err: err}
+	}
+	if rel, err := filepath.Rel(i.fileRoot, path); err != nil || !filepath.IsLocal(rel) {
+		return "", &Error{Spec: "file", Err: fmt.Errorf("path %q is outside the root", name), kind: ErrBadSpec}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", &Error{Spec: "file", Err: err}
+	}
+	return string(b), nil
+}
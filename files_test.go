@@ -0,0 +1,62 @@
+package fstr
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileFilter(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(root, "banner.txt"), "Welcome!\n")
+	write(filepath.Join(root, "conf.d", "db.conf"), "port = 5432")
+	write(filepath.Join(outside, "secret.txt"), "hunter2")
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "escape.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("banner.txt", filepath.Join(root, "motd")); err != nil {
+		t.Fatal(err)
+	}
+
+	interp := New(WithFileAccess(root))
+	tests := []struct {
+		path string
+		want string
+		err  error
+	}{
+		{path: "banner.txt", want: "Welcome!\n"},
+		{path: "conf.d/db.conf", want: "port = 5432"},
+		{path: "motd", want: "Welcome!\n"},
+		{path: "missing.txt", err: fs.ErrNotExist},
+		{path: "../secret.txt", err: ErrBadSpec},
+		{path: filepath.Join(outside, "secret.txt"), err: ErrBadSpec},
+		{path: "escape.txt", err: ErrBadSpec},
+	}
+	for _, tt := range tests {
+		got, err := interp.Interpolate("{path|file}", map[string]interface{}{"path": tt.path})
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Interpolate(%q) error = %v, want %v", tt.path, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := Interpolate("{path|file}", map[string]interface{}{"path": "banner.txt"}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("Interpolate() error = %v, want ErrBadSpec without file access", err)
+	}
+}
@@ -0,0 +1,125 @@
+package fstr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// filterDef describes a filter, applied to a placeholder's value with {key|name} or {key|name(args)}.
+// Its template function is called with the value followed by the filter's arguments, as strings.
+type filterDef struct {
+	fn interface{} // nil for filters bound to an Interpolator; see Interpolator.funcs
+
+	kind    Kind // kind of value the filter expects
+	minArgs int
+	maxArgs int
+}
+
+// filters lists the filters by name.
+var filters = map[string]filterDef{
+	"b64dec":     {fn: b64dec, kind: KindString},
+	"b64enc":     {fn: b64enc, kind: KindString},
+	"box":        {fn: box, kind: KindString, maxArgs: 1},
+	"camel":      {fn: camel, kind: KindString},
+	"center":     {kind: KindAny, maxArgs: 1},
+	"color":      {kind: KindAny, minArgs: 1, maxArgs: 1},
+	"columns":    {kind: KindAny, maxArgs: 1},
+	"deg":        {fn: deg, kind: KindString},
+	"emoji":      {fn: emoji, kind: KindString},
+	"exec":       {kind: KindString},
+	"file":       {kind: KindString},
+	"hist":       {fn: hist, kind: KindAny, maxArgs: 1},
+	"humanjoin":  {fn: humanjoin, kind: KindAny, maxArgs: 2},
+	"indent":     {fn: indent, kind: KindString, minArgs: 1, maxArgs: 1},
+	"json":       {kind: KindAny, maxArgs: 1},
+	"jsonc":      {kind: KindAny},
+	"kebab":      {fn: kebab, kind: KindString},
+	"kv":         {kind: KindAny, maxArgs: 2},
+	"mask":       {fn: mask, kind: KindString, maxArgs: 1},
+	"nindent":    {fn: nindent, kind: KindString, minArgs: 1, maxArgs: 1},
+	"pascal":     {fn: pascal, kind: KindString},
+	"repeat":     {fn: repeat, kind: KindAny, minArgs: 1, maxArgs: 1},
+	"sha256sum":  {fn: sha256sum, kind: KindString},
+	"slug":       {fn: slug, kind: KindString},
+	"snake":      {fn: snake, kind: KindString},
+	"stats":      {fn: stats, kind: KindAny},
+	"toYaml":     {kind: KindAny},
+	"trim":       {fn: trim, kind: KindString, maxArgs: 1},
+	"trimPrefix": {fn: trimPrefix, kind: KindString, minArgs: 1, maxArgs: 1},
+	"trimSpace":  {fn: trimSpace, kind: KindString},
+	"trimSuffix": {fn: trimSuffix, kind: KindString, minArgs: 1, maxArgs: 1},
+	"truncate":   {fn: truncate, kind: KindString, minArgs: 1, maxArgs: 2},
+}
+
+// snake converts an identifier to snake_case: "HTTPServerError" => "http_server_error".
+func snake(value interface{}) string {
+	return strings.ToLower(strings.Join(words(fmt.Sprint(value)), "_"))
+}
+
+// kebab converts an identifier to kebab-case: "HTTPServerError" => "http-server-error".
+func kebab(value interface{}) string {
+	return strings.ToLower(strings.Join(words(fmt.Sprint(value)), "-"))
+}
+
+// camel converts an identifier to camelCase: "http_server_error" => "httpServerError".
+func camel(value interface{}) string {
+	ws := words(fmt.Sprint(value))
+	for i, w := range ws {
+		if i == 0 {
+			ws[i] = strings.ToLower(w)
+		} else {
+			ws[i] = title(w)
+		}
+	}
+	return strings.Join(ws, "")
+}
+
+// pascal converts an identifier to PascalCase: "http_server_error" => "HttpServerError".
+func pascal(value interface{}) string {
+	ws := words(fmt.Sprint(value))
+	for i, w := range ws {
+		ws[i] = title(w)
+	}
+	return strings.Join(ws, "")
+}
+
+// title upper-cases the first letter of a word and lower-cases the rest.
+func title(w string) string {
+	rs := []rune(strings.ToLower(w))
+	rs[0] = unicode.ToUpper(rs[0])
+	return string(rs)
+}
+
+// words splits an identifier into its words. Words are separated by any character that is
+// neither a letter nor a digit, and by changes of case: "parseHTTPResponse2xx" splits into
+// "parse", "HTTP", "Response2xx". Digits belong to the word they follow.
+func words(s string) []string {
+	var ws []string
+	rs := []rune(s)
+	start := -1
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				ws = append(ws, string(rs[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := rs[i-1]
+		lowerToUpper := unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev))
+		acronymEnd := unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1])
+		if lowerToUpper || acronymEnd {
+			ws = append(ws, string(rs[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		ws = append(ws, string(rs[start:]))
+	}
+	return ws
+}
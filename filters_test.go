@@ -0,0 +1,109 @@
+package fstr
+
+import "testing"
+
+func TestCaseFilters(t *testing.T) {
+	tests := []struct {
+		input                       string
+		snake, camel, kebab, pascal string
+	}{
+		{input: "user_id", snake: "user_id", camel: "userId", kebab: "user-id", pascal: "UserId"},
+		{input: "HTTPServerError", snake: "http_server_error", camel: "httpServerError", kebab: "http-server-error", pascal: "HttpServerError"},
+		{input: "parseJSON", snake: "parse_json", camel: "parseJson", kebab: "parse-json", pascal: "ParseJson"},
+		{input: "first-name", snake: "first_name", camel: "firstName", kebab: "first-name", pascal: "FirstName"},
+		{input: "Order Line Item", snake: "order_line_item", camel: "orderLineItem", kebab: "order-line-item", pascal: "OrderLineItem"},
+		{input: "v2Api", snake: "v2_api", camel: "v2Api", kebab: "v2-api", pascal: "V2Api"},
+		{input: "__private__", snake: "private", camel: "private", kebab: "private", pascal: "Private"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{f|snake} {f|camel} {f|kebab} {f|pascal}", map[string]interface{}{"f": tt.input})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.input, err)
+			continue
+		}
+		if want := tt.snake + " " + tt.camel + " " + tt.kebab + " " + tt.pascal; got != want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.input, got, want)
+		}
+	}
+}
+
+func TestFilterSyntax(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{name: "Chained filters", format: "{f|snake|pascal}", want: "UserAccount"},
+		{name: "Debug form", format: "{f=|kebab}", want: "f=user-account"},
+		{name: "Unknown filter is left untouched", format: "{f|nope}", want: "{f|nope}"},
+		{name: "Filter arguments are checked", format: "{f|snake(1)}", want: "{f|snake(1)}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Interpolate(tt.format, map[string]interface{}{"f": "userAccount"})
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCall(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		ok   bool
+	}{
+		{in: "snake", ok: true},
+		{in: "truncate(80)", want: []string{"80"}, ok: true},
+		{in: `kv("=", ",")`, want: []string{"=", ","}, ok: true},
+		{in: `trimPrefix( "a, b" )`, want: []string{"a, b"}, ok: true},
+		{in: `kv("=",)`, ok: false},
+		{in: `kv("=" x)`, ok: false},
+		{in: "1bad", ok: false},
+	}
+	for _, tt := range tests {
+		c, ok := parseCall(tt.in)
+		if ok != tt.ok {
+			t.Errorf("parseCall(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			continue
+		}
+		if len(c.args) != len(tt.want) {
+			t.Errorf("parseCall(%q) args = %q, want %q", tt.in, c.args, tt.want)
+			continue
+		}
+		for i := range c.args {
+			if c.args[i] != tt.want[i] {
+				t.Errorf("parseCall(%q) args = %q, want %q", tt.in, c.args, tt.want)
+			}
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "Hello World", want: "hello-world"},
+		{input: "  Héllo, Wörld!  ", want: "hello-world"},
+		{input: "Ça va? Straße & Co.", want: "ca-va-strasse-and-co"},
+		{input: "Go 1.21 Release Notes", want: "go-1-21-release-notes"},
+		{input: "Smørrebrød--Æblekage", want: "smorrebrod-aeblekage"},
+		{input: "日本語 title", want: "title"},
+		{input: "---", want: ""},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{title|slug}", map[string]interface{}{"title": tt.input})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,470 @@
+package fstr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NonFiniteFunc renders a NaN or infinite value under a numeric specifier, or returns an error if
+// such values must not be rendered. Configure it with WithNonFinite.
+type NonFiniteFunc func(f float64) (string, error)
+
+// NonFiniteSymbols renders NaN as "NaN", +Inf as "∞" and -Inf as "-∞". It is the default NonFiniteFunc.
+func NonFiniteSymbols(f float64) (string, error) {
+	switch {
+	case math.IsNaN(f):
+		return "NaN", nil
+	case f > 0:
+		return "∞", nil
+	default:
+		return "-∞", nil
+	}
+}
+
+// NonFiniteText returns a NonFiniteFunc that renders every NaN or infinite value as fallback, e.g. "n/a".
+func NonFiniteText(fallback string) NonFiniteFunc {
+	return func(float64) (string, error) {
+		return fallback, nil
+	}
+}
+
+// NonFiniteError is a NonFiniteFunc that refuses to render NaN and infinite values, making
+// interpolation fail with an error instead.
+func NonFiniteError(f float64) (string, error) {
+	return "", fmt.Errorf("cannot format non-finite value %v", f)
+}
+
+// DecimalFunc formats a value of an exact decimal type, such as shopspring/decimal's Decimal or
+// cockroachdb/apd's Decimal, in plain decimal notation (e.g. "-1234.50") with the given number of
+// decimal places, or exactly if precision is negative. It reports false if value is not of its type.
+// fstr applies grouping and alignment to the result. Register one with WithDecimal.
+type DecimalFunc func(value interface{}, precision int) (string, bool)
+
+// formatNumber formats value according to spec like the formatNumber function, rendering
+// NaN and infinite values with the Interpolator's NonFiniteFunc, with the separators of loc.
+func (i *Interpolator) formatNumber(loc *numberSymbols, value interface{}, spec string) (string, error) {
+	s, err := i.formatNumberSpec(value, spec)
+	if err != nil {
+		return "", err
+	}
+	return loc.localize(s), nil
+}
+
+// formatNumberSpec implements formatNumber with the default separators.
+func (i *Interpolator) formatNumberSpec(value interface{}, spec string) (string, error) {
+	ns, err := parseNumberSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			s, err := i.nonFinite(f)
+			if err != nil {
+				return "", &Error{Spec: spec, Err: err, kind: ErrUnsupportedType}
+			}
+			return ns.pad(s), nil
+		}
+	}
+	if i.decimal != nil {
+		if ns.exact() {
+			if s, ok := i.decimal(value, -1); ok {
+				return ns.layout(ns.round(s)), nil
+			}
+		} else if s, ok := i.decimal(value, ns.precision); ok {
+			return ns.layout(s), nil
+		}
+	}
+	return ns.format(value)
+}
+
+// formatNumber is a helper function that formats a number according to the given format specifier.
+// It supports thousands separators, decimal precision and alignment within a fixed width:
+//
+//	[align][0][width][group][.precision f|g|e|E][!e][x][digits]
+//
+// where align is one of '<' (left), '>' (right, the default), '^' (centre) or '=' (right, with
+// padding placed after the sign). For example, {price:=12.2f} renders -3.5 as "-       3.50",
+// so that the decimal points of a column of prices line up. The group character separates thousands:
+// a comma, as in {n:,.2f}, or, as some style guides require, an apostrophe ({n:'} renders 1234567 as
+// "1'234'567"), a space ({n: }), a thin space (U+2009) or a narrow no-break space (U+202F). Unlike
+// the comma, these are kept as is by WithLocale. A 0 before the width pads with zeros
+// instead, grouping them like other digits: {n:09,} renders 1234 as "0,001,234". The !e modifier selects
+// round-half-even ("banker's") rounding, e.g. {x:.2f!e}; see roundHalfEven.
+//
+// With g, the precision counts significant figures instead of decimal places, for scientific reporting:
+// {x:.3g} renders 1234.5 as "1230" and 0.00012345 as "0.000123". Unlike Python's g, the result stays in
+// plain decimal notation and keeps its trailing zeros, which are significant: 2.5 renders as "2.50".
+// It is rounded from the shortest decimal representation of the value; see roundSignificant.
+//
+// With e or E, the value is written in scientific notation with precision decimals, as in Python:
+// {x:.2e} renders 12345 as "1.23e+04". Modifiers control the exponent for publication-style output:
+// a digit sets its minimum number of digits and drops the + sign, so {x:.2E!1} renders "1.23E4", and
+// x writes it as a power of ten with superscript digits: {x:.2e!x} renders "1.23×10⁴".
+//
+// The value may be of any integer or floating-point type, including named types such as
+// `type Celsius float64`, a Money or a *big.Int. Integers and Money amounts are formatted exactly,
+// without a round trip through float64.
+func formatNumber(value interface{}, spec string) (string, error) {
+	ns, err := parseNumberSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	return ns.format(value)
+}
+
+// maxSpecWidth and maxSpecPrecision are the largest width and precision a numeric format specifier
+// may give, so that a spec in a user-editable template cannot exhaust memory. maxSpecWidth also
+// bounds the widths given to filters such as indent.
+const (
+	maxSpecWidth     = 10000
+	maxSpecPrecision = 1000
+)
+
+// numberSpec is a parsed numeric format specifier.
+type numberSpec struct {
+	spec      string
+	align     byte
+	zero      bool // pad with zeros after the sign instead of spaces
+	width     int
+	group     string // thousands separator, empty if the digits are not grouped
+	precision int    // -1 if the shortest exact representation should be used
+	sig       bool   // precision counts significant figures rather than decimal places
+	halfEven  bool   // round half to even on the decimal representation
+	exp       byte   // 'e' or 'E' for scientific notation, 0 for plain decimal notation
+	super     bool   // write the exponent as ×10 with superscript digits
+	expDigits int    // minimum number of exponent digits, without a + sign; 0 for the default style
+}
+
+// parseNumberSpec parses a numeric format specifier matched by specRe.
+func parseNumberSpec(spec string) (numberSpec, error) {
+	matches := specRe.FindStringSubmatch(spec)
+	if matches == nil || spec == "" || strings.HasSuffix(spec, "!") {
+		return numberSpec{}, &Error{Spec: spec, Err: errors.New("invalid number format"), kind: ErrBadSpec}
+	}
+	ns := numberSpec{
+		spec:      spec,
+		align:     '>',
+		zero:      matches[2] == "0",
+		group:     matches[4],
+		precision: -1,
+		sig:       matches[6] == "g",
+		halfEven:  matches[7] == "e",
+		super:     matches[8] == "x",
+	}
+	if matches[6] == "e" || matches[6] == "E" {
+		ns.exp = matches[6][0]
+	}
+	if matches[9] != "" {
+		ns.expDigits = int(matches[9][0] - '0')
+	}
+	if ns.exp == 0 && (ns.super || ns.expDigits > 0) {
+		return numberSpec{}, &Error{Spec: spec, Err: errors.New("exponent style without e or E"), kind: ErrBadSpec}
+	}
+	if matches[1] != "" {
+		ns.align = matches[1][0]
+	}
+	if matches[3] != "" {
+		width, err := strconv.Atoi(matches[3])
+		if err != nil {
+			return numberSpec{}, &Error{Spec: spec, Err: fmt.Errorf("invalid width: %w", err), kind: ErrBadSpec}
+		}
+		if width > maxSpecWidth {
+			return numberSpec{}, &Error{Spec: spec, Err: fmt.Errorf("width %d exceeds %d", width, maxSpecWidth), kind: ErrBadSpec}
+		}
+		ns.width = width
+	}
+	switch {
+	case matches[5] != "":
+		// example format: {gpa:.4f} and gpa is 3.165789 => 3.1658
+		p, err := strconv.Atoi(matches[5])
+		if err != nil {
+			return numberSpec{}, &Error{Spec: spec, Err: fmt.Errorf("invalid precision: %w", err), kind: ErrBadSpec}
+		}
+		if p > maxSpecPrecision {
+			return numberSpec{}, &Error{Spec: spec, Err: fmt.Errorf("precision %d exceeds %d", p, maxSpecPrecision), kind: ErrBadSpec}
+		}
+		ns.precision = p
+		if ns.sig && p == 0 {
+			ns.precision = 1 // as in Python, .0g keeps one significant figure
+		}
+	case ns.group != "":
+		// example format: {balance:,} and balance is 123456789.111 => 123,456,789
+		ns.precision = 0
+	}
+	return ns, nil
+}
+
+// format formats value according to the spec.
+func (ns numberSpec) format(value interface{}) (string, error) {
+	if ns.exact() {
+		strNumber, err := formatDecimal(value, -1)
+		if err != nil {
+			return "", &Error{Spec: ns.spec, Err: err, kind: ErrUnsupportedType}
+		}
+		return ns.layout(ns.round(strNumber)), nil
+	}
+	strNumber, err := formatDecimal(value, ns.precision)
+	if err != nil {
+		return "", &Error{Spec: ns.spec, Err: err, kind: ErrUnsupportedType}
+	}
+	return ns.layout(strNumber), nil
+}
+
+// exact reports whether the spec rounds the exact decimal representation of a value, rather than
+// letting strconv round its binary value.
+func (ns numberSpec) exact() bool {
+	return ns.sig || ns.exp != 0 || ns.halfEven && ns.precision >= 0
+}
+
+// round rounds a number in plain decimal notation according to a spec for which exact reports true.
+func (ns numberSpec) round(number string) string {
+	switch {
+	case ns.exp != 0:
+		return ns.scientific(number)
+	case ns.sig:
+		return roundSignificant(number, ns.precision, ns.halfEven)
+	default:
+		return roundHalfEven(number, ns.precision)
+	}
+}
+
+// superscripts are the superscript digits, for exponents written as ×10ⁿ.
+var superscripts = []rune("⁰¹²³⁴⁵⁶⁷⁸⁹")
+
+// scientific formats a number in plain decimal notation in scientific notation, with the spec's
+// number of decimals and exponent style: 12345 renders as "1.23e+04" with .2e, "1.23E4" with .2E!1
+// and "1.23×10⁴" with .2e!x.
+func (ns numberSpec) scientific(number string) string {
+	sign, digits, point := significand(number, ns.precision+1, ns.halfEven)
+	mantissa := digits[:1]
+	if ns.precision > 0 {
+		mantissa += "." + digits[1:]
+	}
+	exp, expSign := point-1, ""
+	switch {
+	case exp < 0:
+		expSign, exp = "-", -exp
+	case ns.expDigits == 0 && !ns.super:
+		expSign = "+"
+	}
+	minDigits := ns.expDigits
+	if minDigits == 0 {
+		minDigits = 2
+		if ns.super {
+			minDigits = 1
+		}
+	}
+	expText := fmt.Sprintf("%0*d", minDigits, exp)
+	if !ns.super {
+		return sign + mantissa + string(ns.exp) + expSign + expText
+	}
+	raised := []rune(strings.Replace(expSign, "-", "⁻", 1))
+	for _, d := range expText {
+		raised = append(raised, superscripts[d-'0'])
+	}
+	return sign + mantissa + "×10" + string(raised)
+}
+
+// layout groups and pads a number formatted in plain decimal notation according to the spec.
+func (ns numberSpec) layout(number string) string {
+	if ns.zero {
+		return ns.zeroFill(number)
+	}
+	if ns.group != "" {
+		number = groupThousands(number, ns.group)
+	}
+	return ns.pad(number)
+}
+
+// zeroFill pads a number in plain decimal notation with leading zeros, after the sign, until it is
+// at least as wide as the spec, grouping the zeros like any other digit: {n:09,} renders 1234 as
+// "0,001,234". As in Python, the result may exceed the width by one character rather than start
+// with a separator.
+func (ns numberSpec) zeroFill(number string) string {
+	sign, digits := "", number
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	for {
+		filled := sign + digits
+		if ns.group != "" {
+			filled = groupThousands(filled, ns.group)
+		}
+		if utf8.RuneCountInString(filled) >= ns.width {
+			return filled
+		}
+		digits = "0" + digits
+	}
+}
+
+// pad aligns a formatted number within the spec's width.
+func (ns numberSpec) pad(number string) string {
+	if ns.align == '=' && (strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+")) {
+		return number[:1] + alignText(number[1:], ns.width-1, '>')
+	}
+	return alignText(number, ns.width, ns.align)
+}
+
+// groupThousands inserts sep between every group of three digits in the integer part of a decimal
+// number, e.g. "-1234567.89" => "-1,234,567.89" for a comma. A leading sign is not counted as a digit.
+func groupThousands(number, sep string) string {
+	sign, digits := "", number
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	intPart, decimalPart, hasDecimals := strings.Cut(digits, ".")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	if hasDecimals {
+		b.WriteString(".")
+		b.WriteString(decimalPart)
+	}
+	return b.String()
+}
+
+// formatDecimal formats a numeric value in plain decimal notation with the given number of decimals,
+// or with as many as needed to represent it exactly if precision is negative.
+// It returns an error if value is not a number.
+func formatDecimal(value interface{}, precision int) (string, error) {
+	if m, ok := value.(Money); ok {
+		if precision < 0 {
+			return m.decimal(), nil
+		}
+		return roundDecimal(m.decimal(), precision, false), nil
+	}
+	if n, ok := value.(*big.Int); ok && n != nil {
+		return withPrecision(n.String(), "", precision), nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return withPrecision(strconv.FormatInt(v.Int(), 10), "", precision), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return withPrecision(strconv.FormatUint(v.Uint(), 10), "", precision), nil
+	case reflect.Float32:
+		return unsignedZero(strconv.FormatFloat(v.Float(), 'f', precision, 32)), nil
+	case reflect.Float64:
+		return unsignedZero(strconv.FormatFloat(v.Float(), 'f', precision, 64)), nil
+	default:
+		return "", fmt.Errorf("cannot format %T as a number", value)
+	}
+}
+
+// roundHalfEven rounds a number in plain decimal notation to the given number of decimal places,
+// rounding ties to the nearest even digit ("banker's rounding"): 2.675 => 2.68, 2.665 => 2.66.
+//
+// Unlike strconv and fmt, which round the exact binary value of a float (2.675 is stored as
+// 2.67499999...), it rounds the shortest decimal representation, which is what accounting
+// systems expect.
+func roundHalfEven(number string, precision int) string {
+	return roundDecimal(number, precision, true)
+}
+
+// roundDecimal rounds a number in plain decimal notation to the given number of decimal places,
+// rounding ties away from zero, or to the nearest even digit if halfEven is set.
+func roundDecimal(number string, precision int, halfEven bool) string {
+	sign, digits := "", number
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if len(fracPart) <= precision {
+		return withPrecision(sign+intPart, fracPart, precision)
+	}
+	kept := []byte(intPart + fracPart[:precision])
+	next, rest := fracPart[precision], fracPart[precision+1:]
+	roundUp := next > '5' || next == '5' && (!halfEven || strings.Trim(rest, "0") != "" || (kept[len(kept)-1]-'0')%2 == 1)
+	if roundUp {
+		i := len(kept) - 1
+		for ; i >= 0 && kept[i] == '9'; i-- {
+			kept[i] = '0'
+		}
+		if i < 0 {
+			kept = append([]byte{'1'}, kept...)
+		} else {
+			kept[i]++
+		}
+	}
+	split := len(kept) - precision
+	return unsignedZero(withPrecision(sign+string(kept[:split]), string(kept[split:]), precision))
+}
+
+// roundSignificant rounds a number in plain decimal notation to sig significant figures, whatever its
+// magnitude, keeping the result in plain decimal notation: to 3 figures, 1234.5 => 1230, 2.5 => 2.50
+// and 0.00012345 => 0.000123. Ties round away from zero, or to the nearest even digit if halfEven is set.
+func roundSignificant(number string, sig int, halfEven bool) string {
+	sign, digits, point := significand(number, sig, halfEven)
+	switch {
+	case point <= 0:
+		return sign + "0." + strings.Repeat("0", -point) + digits
+	case point >= sig:
+		return sign + digits + strings.Repeat("0", point-sig)
+	default:
+		return sign + digits[:point] + "." + digits[point:]
+	}
+}
+
+// significand rounds a number in plain decimal notation to sig significant figures like
+// roundSignificant, returning its sign, its sig digits and the position of its decimal point relative
+// to the first digit: "-0.012345" to 3 figures is "-", "123" and -1. Zero has sig zeros and a point of 1.
+func significand(number string, sig int, halfEven bool) (sign, digits string, point int) {
+	digits = number
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	digits = intPart + fracPart
+	first := strings.IndexFunc(digits, func(r rune) bool { return r != '0' })
+	if first < 0 {
+		return "", strings.Repeat("0", sig), 1
+	}
+	digits, point = digits[first:], len(intPart)-first
+	if len(digits) < sig {
+		digits += strings.Repeat("0", sig-len(digits))
+	}
+	kept, rest := []byte(digits[:sig]), digits[sig:]
+	roundUp := rest != "" && (rest[0] > '5' || rest[0] == '5' &&
+		(!halfEven || strings.Trim(rest[1:], "0") != "" || (kept[sig-1]-'0')%2 == 1))
+	if roundUp {
+		i := sig - 1
+		for ; i >= 0 && kept[i] == '9'; i-- {
+			kept[i] = '0'
+		}
+		if i < 0 {
+			kept[0] = '1' // 9.99 => 10.0
+			point++
+		} else {
+			kept[i]++
+		}
+	}
+	return sign, string(kept), point
+}
+
+// withPrecision joins an integer part and a fractional part, padding the latter with zeros to precision digits.
+func withPrecision(intPart, fracPart string, precision int) string {
+	if precision <= 0 {
+		return intPart
+	}
+	return intPart + "." + fracPart + strings.Repeat("0", precision-len(fracPart))
+}
+
+// unsignedZero drops the sign of a formatted number that rounded to zero, such as "-0.00",
+// so that zero always renders the same way.
+func unsignedZero(number string) string {
+	if strings.HasPrefix(number, "-") && strings.Trim(number[1:], "0.") == "" {
+		return number[1:]
+	}
+	return number
+}
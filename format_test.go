@@ -0,0 +1,446 @@
+package fstr
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestFormatNumberTypes(t *testing.T) {
+	type celsius float64
+	tests := []struct {
+		name   string
+		value  interface{}
+		format string
+		want   string
+	}{
+		{name: "int", value: 1234567, format: ",", want: "1,234,567"},
+		{name: "int8", value: int8(-12), format: ".1f", want: "-12.0"},
+		{name: "int16", value: int16(12345), format: ",.2f", want: "12,345.00"},
+		{name: "int32", value: int32(7), format: ".3f", want: "7.000"},
+		{name: "int64 beyond float64 precision", value: int64(9007199254740993), format: ",", want: "9,007,199,254,740,993"},
+		{name: "uint8", value: uint8(255), format: ",", want: "255"},
+		{name: "uint16", value: uint16(65535), format: ",", want: "65,535"},
+		{name: "uint32", value: uint32(4294967295), format: ",.1f", want: "4,294,967,295.0"},
+		{name: "uint64 max", value: uint64(math.MaxUint64), format: ",", want: "18,446,744,073,709,551,615"},
+		{name: "uint", value: uint(1000), format: ",", want: "1,000"},
+		{name: "float32", value: float32(3.14159), format: ".2f", want: "3.14"},
+		{name: "float64", value: 123456789.9787968, format: ",.3f", want: "123,456,789.979"},
+		{name: "named float", value: celsius(21.55), format: ".1f", want: "21.6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatNumber(tt.value, tt.format)
+			if err != nil {
+				t.Fatalf("formatNumber() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("formatNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatNumberErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		spec  string
+	}{
+		{name: "Invalid spec", value: 1.5, spec: ".2x"},
+		{name: "Empty spec", value: 1.5, spec: ""},
+		{name: "Not a number", value: "abc", spec: ",.2f"},
+		{name: "Width too large", value: 1.5, spec: "100000000000"},
+		{name: "Precision too large", value: 1.5, spec: ".1000000000f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := formatNumber(tt.value, tt.spec)
+			var fe *Error
+			if !errors.As(err, &fe) {
+				t.Fatalf("formatNumber() error = %v, want *Error", err)
+			}
+			if fe.Spec != tt.spec {
+				t.Errorf("Error.Spec = %q, want %q", fe.Spec, tt.spec)
+			}
+		})
+	}
+}
+
+func TestInterpolateReturnsError(t *testing.T) {
+	format := "balance: {balance:,.2f}"
+	_, err := Interpolate(format, map[string]interface{}{"balance": "lots"})
+	var fe *Error
+	if !errors.As(err, &fe) {
+		t.Fatalf("Interpolate() error = %v, want *Error", err)
+	}
+	if fe.Format != format || fe.Spec != ",.2f" {
+		t.Errorf("Error = %+v, want Format %q and Spec %q", fe, format, ",.2f")
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Eval() did not panic")
+		} else if _, ok := r.(*Error); !ok {
+			t.Errorf("Eval() panicked with %T, want *Error", r)
+		}
+	}()
+	Eval(format, map[string]interface{}{"balance": "lots"})
+}
+
+func TestInterpolateDoesNotModifyData(t *testing.T) {
+	data := map[string]interface{}{"age": 23}
+	if _, err := Interpolate("{age:.1f}", data); err != nil {
+		t.Fatalf("Interpolate() error = %v", err)
+	}
+	if _, ok := data["age"].(int); !ok {
+		t.Errorf("data[\"age\"] = %T, want int", data["age"])
+	}
+}
+
+func TestFormatNumberSigns(t *testing.T) {
+	specs := []string{",", ".2f", ",.2f"}
+	tests := []struct {
+		value interface{}
+		want  []string // one result per spec
+	}{
+		{value: 0, want: []string{"0", "0.00", "0.00"}},
+		{value: 0.0, want: []string{"0", "0.00", "0.00"}},
+		{value: -0.001, want: []string{"0", "0.00", "0.00"}},
+		{value: 1, want: []string{"1", "1.00", "1.00"}},
+		{value: -1, want: []string{"-1", "-1.00", "-1.00"}},
+		{value: 999, want: []string{"999", "999.00", "999.00"}},
+		{value: -999, want: []string{"-999", "-999.00", "-999.00"}},
+		{value: -999.5, want: []string{"-1,000", "-999.50", "-999.50"}},
+		{value: 1000, want: []string{"1,000", "1000.00", "1,000.00"}},
+		{value: -1000, want: []string{"-1,000", "-1000.00", "-1,000.00"}},
+		{value: -123456, want: []string{"-123,456", "-123456.00", "-123,456.00"}},
+		{value: -1234567.89, want: []string{"-1,234,568", "-1234567.89", "-1,234,567.89"}},
+		{value: int64(-9223372036854775808), want: []string{"-9,223,372,036,854,775,808", "-9223372036854775808.00", "-9,223,372,036,854,775,808.00"}},
+	}
+	for _, tt := range tests {
+		for i, spec := range specs {
+			got, err := formatNumber(tt.value, spec)
+			if err != nil {
+				t.Errorf("formatNumber(%v, %q) error = %v", tt.value, spec, err)
+				continue
+			}
+			if got != tt.want[i] {
+				t.Errorf("formatNumber(%v, %q) = %v, want %v", tt.value, spec, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestFormatNumberNonFinite(t *testing.T) {
+	values := []float64{math.NaN(), math.Inf(1), math.Inf(-1)}
+	tests := []struct {
+		name      string
+		nonFinite NonFiniteFunc
+		want      []string // one result per value; nil if an error is expected
+	}{
+		{name: "Symbols", nonFinite: NonFiniteSymbols, want: []string{"NaN", "∞", "-∞"}},
+		{name: "Fallback text", nonFinite: NonFiniteText("n/a"), want: []string{"n/a", "n/a", "n/a"}},
+		{name: "Error", nonFinite: NonFiniteError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp := New(WithNonFinite(tt.nonFinite))
+			for i, v := range values {
+				for _, spec := range []string{",", ".2f", ",.2f"} {
+					got, err := interp.formatNumber(interp.locale, v, spec)
+					switch {
+					case tt.want == nil && err == nil:
+						t.Errorf("formatNumber(%v, %q) = %v, want error", v, spec, got)
+					case tt.want != nil && err != nil:
+						t.Errorf("formatNumber(%v, %q) error = %v", v, spec, err)
+					case tt.want != nil && got != tt.want[i]:
+						t.Errorf("formatNumber(%v, %q) = %v, want %v", v, spec, got, tt.want[i])
+					}
+				}
+			}
+		})
+	}
+	if got := Eval("{x:,.2f}", map[string]interface{}{"x": float32(math.Inf(1))}); got != "∞" {
+		t.Errorf("Eval() = %v, want ∞", got)
+	}
+}
+
+func TestFormatNumberAlignment(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		spec  string
+		want  string
+	}{
+		{value: 3.5, spec: "=12.2f", want: "        3.50"},
+		{value: -3.5, spec: "=12.2f", want: "-       3.50"},
+		{value: 1234.5, spec: "=12,.2f", want: "    1,234.50"},
+		{value: -3.5, spec: "12.2f", want: "       -3.50"},
+		{value: -3.5, spec: ">12.2f", want: "       -3.50"},
+		{value: 42, spec: "<6", want: "42    "},
+		{value: 42, spec: "^6", want: "  42  "},
+		{value: 2.5, spec: "6", want: "   2.5"},
+		{value: 123456.789, spec: "=4.2f", want: "123456.79"},
+	}
+	for _, tt := range tests {
+		got, err := formatNumber(tt.value, tt.spec)
+		if err != nil {
+			t.Errorf("formatNumber(%v, %q) error = %v", tt.value, tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatNumber(%v, %q) = %q, want %q", tt.value, tt.spec, got, tt.want)
+		}
+	}
+	got := Eval("[{a:=8.2f}]\n[{b:=8.2f}]", map[string]interface{}{"a": 12.5, "b": -1234.125})
+	if want := "[   12.50]\n[-1234.12]"; got != want {
+		t.Errorf("Eval() = %q, want %q", got, want)
+	}
+	if got := Eval("{x:=6.1f}", map[string]interface{}{"x": math.Inf(-1)}); got != "-    ∞" {
+		t.Errorf("Eval() = %q, want %q", got, "-    ∞")
+	}
+}
+
+// cents is a minimal exact decimal type with two decimal places, standing in for a decimal library.
+type cents int64
+
+func (c cents) StringFixed(places int) string {
+	r := new(big.Rat).SetFrac64(int64(c), 100)
+	return r.FloatString(places)
+}
+
+func TestFormatNumberDecimal(t *testing.T) {
+	interp := New(WithDecimal(func(v interface{}, precision int) (string, bool) {
+		c, ok := v.(cents)
+		if !ok {
+			return "", false
+		}
+		if precision < 0 {
+			precision = 2
+		}
+		return c.StringFixed(precision), true
+	}))
+	tests := []struct {
+		value interface{}
+		spec  string
+		want  string
+	}{
+		{value: cents(123456789), spec: ",.2f", want: "1,234,567.89"},
+		{value: cents(-5), spec: "=8.2f", want: "-   0.05"},
+		{value: cents(30), spec: "6", want: "  0.30"},
+		{value: 2.675, spec: ".2f", want: "2.67"},
+	}
+	for _, tt := range tests {
+		got, err := interp.formatNumber(interp.locale, tt.value, tt.spec)
+		if err != nil {
+			t.Errorf("formatNumber(%v, %q) error = %v", tt.value, tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatNumber(%v, %q) = %q, want %q", tt.value, tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumberHalfEven(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		spec  string
+		want  string
+	}{
+		{value: 2.675, spec: ".2f", want: "2.67"},
+		{value: 2.675, spec: ".2f!e", want: "2.68"},
+		{value: 2.665, spec: ".2f!e", want: "2.66"},
+		{value: 2.5, spec: ".0f!e", want: "2"},
+		{value: 3.5, spec: ".0f!e", want: "4"},
+		{value: -0.125, spec: ".2f!e", want: "-0.12"},
+		{value: -0.005, spec: ".2f!e", want: "0.00"},
+		{value: 0.0051, spec: ".2f!e", want: "0.01"},
+		{value: 999.995, spec: ",.2f!e", want: "1,000.00"},
+		{value: 1.5, spec: "=8.3f!e", want: "   1.500"},
+		{value: 12, spec: ".2f!e", want: "12.00"},
+		{value: cents(1234565), spec: ",.3f!e", want: "12,345.650"},
+	}
+	interp := New(WithDecimal(func(v interface{}, precision int) (string, bool) {
+		c, ok := v.(cents)
+		if !ok {
+			return "", false
+		}
+		if precision < 0 {
+			precision = 2
+		}
+		return c.StringFixed(precision), true
+	}))
+	for _, tt := range tests {
+		got, err := interp.formatNumber(interp.locale, tt.value, tt.spec)
+		if err != nil {
+			t.Errorf("formatNumber(%v, %q) error = %v", tt.value, tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatNumber(%v, %q) = %q, want %q", tt.value, tt.spec, got, tt.want)
+		}
+	}
+	got := Eval("{stat:.2f} {money:.2f!e}", map[string]interface{}{"stat": 0.125, "money": 0.125})
+	if want := "0.12 0.12"; got != want {
+		t.Errorf("Eval() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatNumberZeroFill(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		spec  string
+		want  string
+	}{
+		{value: 1234, spec: "08,", want: "0,001,234"},
+		{value: 1234, spec: "09,", want: "0,001,234"},
+		{value: 1234, spec: "010,", want: "00,001,234"},
+		{value: -1234, spec: "08,", want: "-001,234"},
+		{value: -1234, spec: "010,", want: "-0,001,234"},
+		{value: 1234.5, spec: "012,.2f", want: "0,001,234.50"},
+		{value: 42, spec: "06", want: "000042"},
+		{value: -42, spec: "06", want: "-00042"},
+		{value: 1234567, spec: "04,", want: "1,234,567"},
+	}
+	for _, tt := range tests {
+		got, err := formatNumber(tt.value, tt.spec)
+		if err != nil {
+			t.Errorf("formatNumber(%v, %q) error = %v", tt.value, tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatNumber(%v, %q) = %q, want %q", tt.value, tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestFormatNumberSignificant(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		spec  string
+		want  string
+	}{
+		{value: 1234.5, spec: ".3g", want: "1230"},
+		{value: 0.00012345, spec: ".3g", want: "0.000123"},
+		{value: 2.5, spec: ".3g", want: "2.50"},
+		{value: 6.02214076e23, spec: ".4g", want: "602200000000000000000000"},
+		{value: 9.996, spec: ".3g", want: "10.0"},
+		{value: 0.0999, spec: ".2g", want: "0.10"},
+		{value: 999999, spec: ",.2g", want: "1,000,000"},
+		{value: -0.0004567, spec: ".2g", want: "-0.00046"},
+		{value: 2.675, spec: ".3g", want: "2.68"},
+		{value: 2.665, spec: ".3g!e", want: "2.66"},
+		{value: 125, spec: ".2g!e", want: "120"},
+		{value: int64(987654321), spec: ".1g", want: "1000000000"},
+		{value: 3.14159, spec: ".0g", want: "3"},
+		{value: 0, spec: ".3g", want: "0.00"},
+		{value: 42.195, spec: "8.3g", want: "    42.2"},
+	}
+	for _, tt := range tests {
+		got, err := formatNumber(tt.value, tt.spec)
+		if err != nil {
+			t.Errorf("formatNumber(%v, %q) error = %v", tt.value, tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatNumber(%v, %q) = %q, want %q", tt.value, tt.spec, got, tt.want)
+		}
+	}
+	values, err := Extract("t={t:.3g}s", "t=0.000123s")
+	if err != nil || values["t"] != "0.000123" {
+		t.Errorf("Extract() = %v, %v, want t=0.000123", values, err)
+	}
+}
+
+func TestFormatNumberScientific(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		spec  string
+		want  string
+	}{
+		{value: 12345, spec: ".2e", want: "1.23e+04"},
+		{value: 12345, spec: ".2E", want: "1.23E+04"},
+		{value: 12345, spec: ".2E!1", want: "1.23E4"},
+		{value: 12345, spec: ".2e!3", want: "1.23e004"},
+		{value: 12345, spec: ".2e!x", want: "1.23×10⁴"},
+		{value: 0.000123, spec: ".1e!x", want: "1.2×10⁻⁴"},
+		{value: 6.02214076e23, spec: ".3e!x", want: "6.022×10²³"},
+		{value: -0.00456, spec: ".2e", want: "-4.56e-03"},
+		{value: 1.5e-10, spec: ".0E!1", want: "2E-10"},
+		{value: 9.999, spec: ".2e", want: "1.00e+01"},
+		{value: 0, spec: ".2e", want: "0.00e+00"},
+		{value: 2.5e6, spec: ".0e!e", want: "2e+06"},
+		{value: 1234, spec: "12.1e", want: "     1.2e+03"},
+		{value: 1234, spec: "<10.1e", want: "1.2e+03   "},
+	}
+	for _, tt := range tests {
+		got, err := formatNumber(tt.value, tt.spec)
+		if err != nil {
+			t.Errorf("formatNumber(%v, %q) error = %v", tt.value, tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatNumber(%v, %q) = %q, want %q", tt.value, tt.spec, got, tt.want)
+		}
+	}
+	for _, spec := range []string{".2f!x", ".2f!2", "!", ".2e!"} {
+		if _, err := formatNumber(1.0, spec); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("formatNumber(%q) error = %v, want ErrBadSpec", spec, err)
+		}
+	}
+	values, err := Extract("{a:.2e} {b:.1e!x}", "1.23e+04 1.2×10⁻⁴")
+	if err != nil || values["a"] != "1.23e+04" || values["b"] != "1.2×10⁻⁴" {
+		t.Errorf("Extract() = %v, %v", values, err)
+	}
+}
+
+func TestFormatNumberGroupCharacters(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		spec  string
+		want  string
+	}{
+		{value: 1234567, spec: "'", want: "1'234'567"},
+		{value: 1234567.891, spec: "'.2f", want: "1'234'567.89"},
+		{value: 1234567, spec: " ", want: "1 234 567"},
+		{value: -1234.5, spec: "\u2009.1f", want: "-1\u2009234.5"},
+		{value: 1234567, spec: "\u202f", want: "1\u202f234\u202f567"},
+		{value: 1234, spec: "09'", want: "0'001'234"},
+		{value: 1234, spec: ">8'", want: "   1'234"},
+		{value: 999, spec: "'", want: "999"},
+	}
+	for _, tt := range tests {
+		got, err := formatNumber(tt.value, tt.spec)
+		if err != nil {
+			t.Errorf("formatNumber(%v, %q) error = %v", tt.value, tt.spec, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatNumber(%v, %q) = %q, want %q", tt.value, tt.spec, got, tt.want)
+		}
+	}
+	got, err := New(WithLocale("de")).Interpolate("{n:'.2f} {n: .2f}", map[string]interface{}{"n": 1234.5})
+	if want := "1'234,50 1 234,50"; err != nil || got != want {
+		t.Errorf("Interpolate(de) = %q, %v, want %q", got, err, want)
+	}
+	values, err := Extract("total: {n:'.2f} CHF", "total: 1'234'567.89 CHF")
+	if err != nil || values["n"] != "1'234'567.89" {
+		t.Errorf("Extract() = %v, %v", values, err)
+	}
+}
+
+func TestFormatNumberLimits(t *testing.T) {
+	data := map[string]interface{}{"x": 1.5, "m": [][]float64{{1}}}
+	for _, format := range []string{
+		"{x:100000000000}", "{x:010001}", "{x:.1000000000f}", "{x:.1001g}", "{x:.1001e}",
+		"{x:pct(1000000000)}", "{m:matrix(.1000000000f)}",
+	} {
+		if _, err := Interpolate(format, data); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("Interpolate(%q) error = %v, want ErrBadSpec", format, err)
+		}
+	}
+	if got, err := Interpolate("{x:10000.1000f}", data); err != nil || len(got) != 10000 {
+		t.Errorf("Interpolate() at the limits = %d characters, %v", len(got), err)
+	}
+}
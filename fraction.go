@@ -0,0 +1,120 @@
+package fstr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// defaultMaxDenominator is the largest denominator used by {p:frac} and {a:ratio(b)} unless one is given.
+const defaultMaxDenominator = 1000
+
+// frac renders a number as a fraction, e.g. 0.75 => "3/4" and 1.5 => "3/2", approximating it with
+// the closest fraction whose denominator does not exceed the optional maximum (default 1000):
+// {p:frac(8)} renders 0.3 as "2/7". Whole numbers render without a denominator.
+func frac(value interface{}, maxDenominator ...string) (string, error) {
+	limit, err := denominatorLimit("frac", maxDenominator)
+	if err != nil {
+		return "", err
+	}
+	r, err := toRat(value)
+	if err != nil {
+		return "", &Error{Spec: "frac", Err: err, kind: ErrUnsupportedType}
+	}
+	r = limitDenominator(r, limit)
+	if r.IsInt() {
+		return r.Num().String(), nil
+	}
+	return r.String(), nil
+}
+
+// ratio renders the ratio of two numbers in lowest terms, e.g. 1920 and 1080 => "16:9".
+func ratio(a, b interface{}) (string, error) {
+	x, err := toRat(a)
+	if err != nil {
+		return "", &Error{Spec: "ratio", Err: err, kind: ErrUnsupportedType}
+	}
+	y, err := toRat(b)
+	if err != nil {
+		return "", &Error{Spec: "ratio", Err: err, kind: ErrUnsupportedType}
+	}
+	if y.Sign() == 0 {
+		return "", &Error{Spec: "ratio", Err: errors.New("division by zero"), kind: ErrUnsupportedType}
+	}
+	r := limitDenominator(new(big.Rat).Quo(x, y), defaultMaxDenominator)
+	return r.Num().String() + ":" + r.Denom().String(), nil
+}
+
+// denominatorLimit parses the optional maximum denominator argument of a fraction spec.
+func denominatorLimit(spec string, args []string) (int64, error) {
+	if len(args) == 0 {
+		return defaultMaxDenominator, nil
+	}
+	limit, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || limit < 1 {
+		return 0, &Error{Spec: spec, Err: fmt.Errorf("invalid maximum denominator %q", args[0]), kind: ErrBadSpec}
+	}
+	return limit, nil
+}
+
+// toRat converts a numeric value exactly to a rational number.
+func toRat(value interface{}) (*big.Rat, error) {
+	if n, ok := value.(*big.Int); ok && n != nil {
+		return new(big.Rat).SetInt(n), nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("cannot convert non-finite value %v to a fraction", f)
+		}
+		return new(big.Rat).SetFloat64(f), nil
+	default:
+		return nil, fmt.Errorf("cannot format %T as a number", value)
+	}
+}
+
+// limitDenominator returns the closest fraction to r with a denominator of at most limit,
+// using the same continued-fraction algorithm as Python's Fraction.limit_denominator.
+func limitDenominator(r *big.Rat, limit int64) *big.Rat {
+	max := big.NewInt(limit)
+	if r.Denom().Cmp(max) <= 0 {
+		return r
+	}
+	p0, q0, p1, q1 := big.NewInt(0), big.NewInt(1), big.NewInt(1), big.NewInt(0)
+	n, d := new(big.Int).Set(r.Num()), new(big.Int).Set(r.Denom())
+	a, q2 := new(big.Int), new(big.Int)
+	for {
+		a.Div(n, d) // floor division, as d is positive
+		q2.Mul(a, q1).Add(q2, q0)
+		if q2.Cmp(max) > 0 {
+			break
+		}
+		p2 := new(big.Int).Mul(a, p1)
+		p2.Add(p2, p0)
+		p0, q0, p1, q1 = p1, q1, p2, new(big.Int).Set(q2)
+		rem := new(big.Int).Mul(a, d)
+		n, d = d, rem.Sub(n, rem)
+	}
+	k := new(big.Int).Sub(max, q0)
+	k.Div(k, q1)
+	bound1 := new(big.Rat).SetFrac(
+		new(big.Int).Add(p0, new(big.Int).Mul(k, p1)),
+		new(big.Int).Add(q0, new(big.Int).Mul(k, q1)),
+	)
+	bound2 := new(big.Rat).SetFrac(p1, q1)
+	diff1 := new(big.Rat).Abs(new(big.Rat).Sub(bound1, r))
+	diff2 := new(big.Rat).Abs(new(big.Rat).Sub(bound2, r))
+	if diff2.Cmp(diff1) <= 0 {
+		return bound2
+	}
+	return bound1
+}
@@ -0,0 +1,38 @@
+package fstr
+
+import "testing"
+
+func TestFracAndRatio(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   map[string]interface{}
+		want   string
+	}{
+		{name: "Three quarters", format: "{p:frac}", data: map[string]interface{}{"p": 0.75}, want: "3/4"},
+		{name: "Improper", format: "{p:frac}", data: map[string]interface{}{"p": 1.5}, want: "3/2"},
+		{name: "Negative", format: "{p:frac}", data: map[string]interface{}{"p": -0.125}, want: "-1/8"},
+		{name: "Whole", format: "{p:frac}", data: map[string]interface{}{"p": 2}, want: "2"},
+		{name: "Third", format: "{p:frac}", data: map[string]interface{}{"p": 1.0 / 3}, want: "1/3"},
+		{name: "Max denominator", format: "{p:frac(8)}", data: map[string]interface{}{"p": 0.3}, want: "2/7"},
+		{name: "Pi", format: "{p:frac(100)}", data: map[string]interface{}{"p": 3.14159265}, want: "311/99"},
+		{name: "Ratio", format: "{a:ratio(b)}", data: map[string]interface{}{"a": 1.5, "b": 1}, want: "3:2"},
+		{name: "Aspect ratio", format: "{w:ratio(h)}", data: map[string]interface{}{"w": 1920, "h": 1080}, want: "16:9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Interpolate(tt.format, tt.data)
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	for _, format := range []string{"{p:frac(0)}", "{a:ratio(b)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"p": 1.5, "a": 1, "b": 0}); err == nil {
+			t.Errorf("Interpolate(%q) error = nil, want error", format)
+		}
+	}
+}
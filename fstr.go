@@ -47,6 +47,16 @@ Example usage of `Println`:
 		}
 	) // Output: "Good day, Alice! Your score is 92."
 
+Interpolator:
+The package-level functions use default options. To change them, create an `Interpolator` with `New` and
+the `With...` options; it offers the same `Interpolate`, `Eval`, `Print` and `Println` methods. For example,
+placeholders that render whole maps, such as {*}, emit keys in sorted order by default so that output is
+stable; `WithKeyOrder` selects a different order:
+
+	interp := fstr.New(fstr.WithKeyOrder(fstr.FixedOrder("id", "name")))
+	interp.Println("{*}", map[string]interface{}{"name": "Alice", "id": 7, "age": 30})
+	// Output: "id=7 name=Alice age=30"
+
 Both `Interpolate` and `Eval`, along with the printing functions, are invaluable for generating dynamic text
 where the template remains consistent, but the data changes, facilitating ease of maintenance and clarity in code
 involving string operations.
@@ -56,9 +66,10 @@ package fstr
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"regexp"
+	"strconv"
 	"strings"
-	"text/template"
 )
 
 // Interpolate performs string interpolation on the provided format string using the given data map.
@@ -67,6 +78,24 @@ import (
 // The function supports:
 //   - Simple placeholders like {key} which are replaced by the value of 'key' from the data map.
 //   - Formatted placeholders like {key:.2f} or {key:,} which are replaced with the value formatted according to the specifier.
+//   - Filtered placeholders like {key|snake}, which transform the value before it is formatted. Filters can be chained.
+//   - Loop blocks like {#each items}{name} {/each}, which render their body once for every element of a slice of
+//     structs or maps, with placeholders referring to the element's fields. {#each items sortby=.price desc}
+//     orders the elements by a field first, and {#each orders if .status == "open"} renders only the elements
+//     satisfying a comparison (==, !=, <, <=, >, >=) with a string or number, or with {#each orders if .rush}
+//     the elements whose field is set to a non-zero value. {#each txns|groupby .category} renders one section per
+//     category instead, holding the category, the group's elements as {#each items}, their {count} and the
+//     subtotals of the numeric fields, such as {amount}.
+//   - Partials like {>signature}, which render a format string registered with WithPartials in place.
+//   - Template inheritance: a format string starting with {extends base} renders the partial base
+//     instead, with the overridable blocks of base, such as {block title}Default title{/block},
+//     replaced by the blocks of the same name in the format string. Its other text is not rendered.
+//     A base can itself extend another partial. Without {extends}, a block renders its own content.
+//   - Secret placeholders like {secret:db/prod#password}, resolved at render time by the resolver
+//     registered with WithSecretResolver.
+//   - Whitespace trim markers: a placeholder, partial or loop block tag opened with "{-" removes the
+//     whitespace and newlines before it, and one closed with "-}" those after it, as in {#each items-}
+//     or {-/each}, so that block tags on lines of their own leave no blank lines.
 //
 // The function uses Go's text/template package for template processing and supports custom formatting through the formatNumber function.
 //
@@ -75,29 +104,25 @@ import (
 //   - data: A map of keys and values used to replace placeholders in the format string.
 //
 // Returns:
-//   - The interpolated string or an error if the template parsing or execution fails.
+//   - The interpolated string or an *Error if the template parsing or execution fails,
+//     for example because a value cannot be formatted with its specifier. Use errors.Is with
+//     ErrBadSpec, ErrParse or ErrUnsupportedType to tell these failures apart. A placeholder
+//     whose key is missing from the data map renders "<no value>"; an Interpolator configured
+//     WithStrictKeys fails with ErrMissingKey instead.
 func Interpolate(format string, data map[string]interface{}) (string, error) {
-	format = preprocess(format)
-	t, err := template.New("fstr").Funcs(template.FuncMap{
-		"formatNumber": formatNumber,
-	}).Parse(format)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
-	}
-	var output bytes.Buffer
-	// convert any int value inside data to float64
-	for k, v := range data {
-		switch v.(type) {
-		case int:
-			data[k] = float64(v.(int))
-		default:
-			data[k] = v
-		}
-	}
-	if err := t.Execute(&output, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
-	}
-	return output.String(), nil
+	return defaultInterpolator.Interpolate(format, data)
+}
+
+// InterpolatePartial is like Interpolate but always returns the best possible output, keeping each
+// placeholder that failed to render as written, along with the problems found. Use an Interpolator
+// configured WithPartialMarker to substitute a different marker.
+//
+// Example usage:
+//
+//	msg, errs := fstr.InterpolatePartial("user {name} owes {due:,.2f}", map[string]interface{}{"name": "ziad", "due": "n/a"})
+//	// msg: "user ziad owes {due:,.2f}", errs: [fstr: {due:,.2f}: spec ",.2f": cannot format string as a number]
+func InterpolatePartial(format string, data map[string]interface{}) (string, []error) {
+	return defaultInterpolator.InterpolatePartial(format, data)
 }
 
 // Eval is a convenience wrapper around Interpolate. It takes a format string and a data map,
@@ -133,87 +158,344 @@ func Eval(format string, data map[string]interface{}) string {
 	return result
 }
 
+// EvalOr is like Eval but never panics: if an error occurs during interpolation, it returns fallback,
+// or the format string itself if fallback is empty. This keeps Eval's brevity safe on logging and
+// HTTP paths, where a bad template must not take the process down. Register a hook with
+// WithErrorHook to still learn about the failures.
+//
+// Example usage:
+//
+//	log.Print(fstr.EvalOr("user {name} logged in", data, "user logged in"))
+func EvalOr(format string, data map[string]interface{}, fallback string) string {
+	return defaultInterpolator.EvalOr(format, data, fallback)
+}
+
 // Print is a convenience wrapper around Eval. It takes a format string and a data map,
 // interpolates the format string with values from the data map, and prints the result to stdout.
 // If an error occurs during interpolation, Print panics with that error.
+// Styles are only rendered if stdout is a terminal.
 func Print(format string, data map[string]interface{}) {
-	fmt.Print(Eval(format, data))
+	defaultInterpolator.Print(format, data)
 }
 
 // Println is a convenience wrapper around Eval. It takes a format string and a data map,
 // interpolates the format string with values from the data map, and prints the result to stdout.
 // If an error occurs during interpolation, Println panics with that error.
+// Styles are only rendered if stdout is a terminal.
 func Println(format string, data map[string]interface{}) {
-	fmt.Println(Eval(format, data))
+	defaultInterpolator.Println(format, data)
+}
+
+// Fprint interpolates the format string and writes the result to w, returning the number of bytes
+// written and any interpolation or write error. Styles such as {status:red} are only rendered if w
+// is a terminal, so output redirected to a file or pipe holds no raw escape sequences.
+func Fprint(w io.Writer, format string, data map[string]interface{}) (int, error) {
+	return defaultInterpolator.Fprint(w, format, data)
+}
+
+// Fprintln is like Fprint but adds a newline after the result.
+func Fprintln(w io.Writer, format string, data map[string]interface{}) (int, error) {
+	return defaultInterpolator.Fprintln(w, format, data)
+}
+
+// placeholderRe matches placeholders of the form {key}, {key=}, {key:spec} and {key=:spec},
+// optionally with filters applied to the value before formatting, as in {key|filter|filter(arg):spec},
+// as well as the expand-all placeholders {*} and {*:spec}. In place of a key, a placeholder may hold
+// a double-quoted string literal, as in {"-"|repeat(60)}.
+var placeholderRe = regexp.MustCompile(`{([a-zA-Z0-9_]+|\*|"(?:[^"\\{}]|\\.)*")(=)?((?:\|[a-zA-Z][a-zA-Z0-9]*(?:\([^(){}]*\))?)*)(?::([^{}]+))?}`)
+
+// specRe matches the numeric format specifiers, such as `,`, `.2f`, `,.2f`, `=12.2f`, `09,`, `.2f!e`, `.3g`,
+// `.2e!x` or `'.2f`. See formatNumber for the full syntax.
+var specRe = regexp.MustCompile(`^([<>^=])?(0)?([0-9]+)?([,' \x{2009}\x{202f}])?(?:\.([0-9]+)([fgeE]))?(?:!(e?)(x?)([1-9]?))?$`)
+
+// termSpecRe matches the terminal alignment specifiers `<term`, `>term` and `^term`.
+var termSpecRe = regexp.MustCompile(`^([<>^])term$`)
+
+// placeholder is a parsed placeholder.
+type placeholder struct {
+	key     string
+	literal bool   // key is the value of a string literal rather than a data map key
+	debug   bool   // the {key=} form, which renders "key=value"
+	secret  bool   // the {secret:path#key} form, whose spec names a secret; see WithSecretResolver
+	filters []call // applied to the value in order, before the spec
+	spec    string
+}
+
+// parsePlaceholder parses a placeholder matched by placeholderRe.
+// It reports false if the placeholder uses an unknown filter or an unrecognised specifier.
+func parsePlaceholder(m string) (placeholder, bool) {
+	matches := placeholderRe.FindStringSubmatch(m)
+	if matches == nil {
+		return placeholder{}, false
+	}
+	p := placeholder{key: matches[1], debug: matches[2] == "=", spec: matches[4]}
+	if p.key == "*" && (p.debug || matches[3] != "") {
+		return placeholder{}, false
+	}
+	if strings.HasPrefix(p.key, `"`) {
+		s, err := strconv.Unquote(p.key)
+		if err != nil || p.debug {
+			return placeholder{}, false
+		}
+		p.key, p.literal = s, true
+	}
+	if matches[3] != "" {
+		for _, f := range splitFilters(matches[3][1:]) {
+			c, ok := parseCall(f)
+			if !ok {
+				return placeholder{}, false
+			}
+			def, ok := lookupFilter(c.name)
+			if !ok || len(c.args) < def.minArgs || len(c.args) > def.maxArgs {
+				return placeholder{}, false
+			}
+			p.filters = append(p.filters, c)
+		}
+	}
+	if _, ok := p.action(); !ok {
+		if p.literal || p.debug || !isSecretRef(p.key, p.spec) {
+			return placeholder{}, false
+		}
+		p.secret = true
+	}
+	return p, true
+}
+
+// tokenRe matches the placeholders, partials, loop block tags and overridable block tags of a format string.
+var tokenRe = regexp.MustCompile(placeholderRe.String() + "|" + partialRe.String() + "|" + blockRe.String() + "|" + namedBlockRe.String())
+
+// markedRe matches the candidates for tags with whitespace trim markers, such as {-name}, {name-} and
+// {-/each-}, capturing the markers and the tag without them.
+var markedRe = regexp.MustCompile(`{(-)?([^{}]*?)(-)?}`)
+
+// markedTag is a tag of a format string written with whitespace trim markers.
+type markedTag struct {
+	// start and end delimit the tag in the format string, markers included.
+	start, end int
+	// tag is the tag without its markers.
+	tag string
+	// before and after are set for the "{-" and "-}" markers.
+	before, after bool
+}
+
+// markedTags returns the placeholders, partials and loop block tags of a format string that are
+// written with whitespace trim markers, in order.
+func markedTags(format string) []markedTag {
+	if !strings.Contains(format, "{-") && !strings.Contains(format, "-}") {
+		return nil
+	}
+	var tags []markedTag
+	for _, loc := range markedRe.FindAllStringSubmatchIndex(format, -1) {
+		m := markedTag{start: loc[0], end: loc[1], tag: "{" + format[loc[4]:loc[5]] + "}", before: loc[2] >= 0, after: loc[6] >= 0}
+		if (m.before || m.after) && isTag(m.tag) {
+			tags = append(tags, m)
+		}
+	}
+	return tags
+}
+
+// trimMarkers applies the whitespace trim markers of a format string: a placeholder, partial or loop
+// block tag written with "{-" removes the spaces, tabs and newlines before it, and one written with
+// "-}" those after it, as in text/template. The markers are removed from the tags, so the rest of the
+// package only ever sees unmarked tags.
+func trimMarkers(format string) string {
+	tags := markedTags(format)
+	if tags == nil {
+		return format
+	}
+	var (
+		b         = make([]byte, 0, len(format))
+		last      int
+		trimAfter bool
+	)
+	for _, m := range tags {
+		text := format[last:m.start]
+		if trimAfter {
+			text = strings.TrimLeft(text, " \t\r\n")
+		}
+		b = append(b, text...)
+		if m.before {
+			b = bytes.TrimRight(b, " \t\r\n")
+		}
+		b = append(b, m.tag...)
+		last, trimAfter = m.end, m.after
+	}
+	text := format[last:]
+	if trimAfter {
+		text = strings.TrimLeft(text, " \t\r\n")
+	}
+	return string(append(b, text...))
+}
+
+// nestTag updates open, the tags of the blocks enclosing a loop block or overridable block tag, with
+// that tag. It fails if the tag closes a block of the other kind, or one that is not open.
+func nestTag(open []string, tag string) ([]string, error) {
+	if !strings.HasPrefix(tag, "{/") {
+		return append(open, tag), nil
+	}
+	if len(open) == 0 {
+		return nil, fmt.Errorf("%s without an open block", tag)
+	}
+	opener := open[len(open)-1]
+	if strings.HasPrefix(opener, "{#each") != (tag == "{/each}") {
+		return nil, fmt.Errorf("%s closes %s", tag, opener)
+	}
+	return open[:len(open)-1], nil
+}
+
+// isTag reports whether s is exactly one placeholder, partial, loop block tag or overridable block tag.
+func isTag(s string) bool {
+	if tokenRe.FindString(s) != s {
+		return false
+	}
+	if blockRe.MatchString(s) || partialRe.MatchString(s) || namedBlockRe.MatchString(s) {
+		return true
+	}
+	_, ok := parsePlaceholder(s)
+	return ok
+}
+
+// source maps a span of preprocessed text back to the placeholder, partial or loop block tag it was generated from.
+type source struct {
+	start, end int
+	text       string
 }
 
 // preprocess converts placeholders in the format string into a syntax compatible with Go's text/template package.
-// It identifies and converts simple placeholders (e.g., {key}) and formatted placeholders (e.g., {key:.2f}).
-func preprocess(format string) string {
-	re := regexp.MustCompile(`{([a-zA-Z0-9_]+)(=)?(?::(,|\.([0-9]+)f|,\.([0-9]+)f))?}`)
-	return re.ReplaceAllStringFunc(format, func(m string) string {
-		matches := re.FindStringSubmatch(m)
-		switch {
-		case matches[2] == "=":
-			/*
-				Handle all four cases:
-				- {name=} => name={{.name}}
-				- {balance=:,} => balance={{formatNumber .balance ","}}
-				- {total:.3f} => total={{formatNumber .total ".3"}}
-				- {balance=:,.2f} => balance={{formatNumber .balance ",.2"}}
-			*/
-			if matches[3] == "," {
-				// example format: {balance:,} and balance is 123456789.111 => 123,456,789
-				return fmt.Sprintf("%[1]s={{formatNumber .%[1]s \",\"}}", matches[1])
-			} else if matches[4] != "" {
-				// example format: {total:.3f} and total is 123456789.9787968 => 123,456,789.979
-				return fmt.Sprintf("%[1]s={{formatNumber .%[1]s \".%s\"}}", matches[1], matches[4])
-			} else if matches[5] != "" {
-				// example format: {total:,.3f} and total is 123456789.9787968 => 123,456,789.979
-				return fmt.Sprintf("%[1]s={{formatNumber .%[1]s \",.%s\"}}", matches[1], matches[5])
-			} else {
-				return fmt.Sprintf("%[1]s={{.%[1]s}}", matches[1])
+// It identifies and converts simple placeholders (e.g., {key}) and formatted placeholders (e.g., {key:.2f}),
+// as well as partials (e.g., {>signature}), loop blocks (e.g., {#each items}...{/each}) and overridable
+// blocks (e.g., {block title}...{/block}), and reports where each came from. Whitespace trim markers are applied first; see trimMarkers.
+// Placeholders with an unknown filter or an unrecognised specifier are left untouched. If escape is set,
+// the output of placeholders is passed through the escape function; see WithEscape.
+func preprocess(format string, escape bool) (string, []source, error) {
+	s := trimMarkers(format)
+	var (
+		b       strings.Builder
+		sources []source
+		last    int
+		open    []string // the tags of the enclosing blocks
+	)
+	for _, loc := range tokenRe.FindAllStringIndex(s, -1) {
+		m := s[loc[0]:loc[1]]
+		var action string
+		if blockRe.MatchString(m) || namedBlockRe.MatchString(m) {
+			var err error
+			if open, err = nestTag(open, m); err != nil {
+				return "", nil, &Error{Format: format, Placeholder: m, Err: err, kind: ErrParse}
+			}
+			if !blockRe.MatchString(m) {
+				action = namedBlockAction(m)
+			} else if action, err = blockAction(m); err != nil {
+				return "", nil, &Error{Format: format, Placeholder: m, Err: err, kind: ErrParse}
+			}
+		} else if partialRe.MatchString(m) {
+			action = partialAction(m)
+		} else {
+			p, ok := parsePlaceholder(m)
+			if !ok {
+				continue
+			}
+			action, _ = p.action()
+			if escape {
+				action = escapeAction(action, p)
 			}
-		case matches[3] == ",":
-			// example format: {balance:,} and balance is 123456789.111 => 123,456,789
-			return fmt.Sprintf("{{formatNumber .%s \",\"}}", matches[1])
-		case matches[4] != "":
-			// example format: {total:.3f} and total is 123456789.9787968 => 123,456,789.979
-			return fmt.Sprintf("{{formatNumber .%s \".%s\"}}", matches[1], matches[4])
-		case matches[5] != "":
-			// example format: {total:,.3f} and total is 123456789.9787968 => 123,456,789.979
-			return fmt.Sprintf("{{formatNumber .%s \",.%s\"}}", matches[1], matches[5])
-		default:
-			return fmt.Sprintf("{{.%s}}", matches[1])
+			if p.debug {
+				// example format: {balance=:,} and balance is 123456789.111 => balance=123,456,789
+				action = p.key + "=" + action
+			}
+		}
+		writeLiteral(&b, s[last:loc[0]])
+		sources = append(sources, source{start: b.Len(), end: b.Len() + len(action), text: m})
+		b.WriteString(action)
+		last = loc[1]
+	}
+	writeLiteral(&b, s[last:])
+	return b.String(), sources, nil
+}
+
+// writeLiteral writes text from outside the tokens of a format string to b, escaping each brace
+// that would start a text/template action: one followed by another brace, or by the action of the
+// next token. Literal braces such as GitHub Actions' ${{ secrets.TOKEN }} thus render as written,
+// and data values expanded with WithRecursion cannot run actions of their own.
+func writeLiteral(b *strings.Builder, text string) {
+	for {
+		n := strings.IndexByte(text, '{')
+		if n < 0 {
+			b.WriteString(text)
+			return
+		}
+		b.WriteString(text[:n])
+		if n+1 == len(text) || text[n+1] == '{' {
+			b.WriteString(`{{"{"}}`)
+		} else {
+			b.WriteByte('{')
+		}
+		text = text[n+1:]
+	}
+}
+
+// action returns the text/template action rendering the placeholder.
+// It reports false if the spec is not recognised.
+func (p placeholder) action() (string, bool) {
+	if p.key == "*" {
+		// example format: {*} or {*:kv} => age=23 name=Ziad
+		if p.spec == "" || p.spec == "kv" {
+			return "{{kvAll .}}", true
+		}
+		return "", false
+	}
+	// example format: {name|snake|kebab} => (kebab (snake .name))
+	expr, spec := "."+p.key, p.spec
+	if p.literal {
+		expr = strconv.Quote(p.key)
+	}
+	if p.secret {
+		// example format: {secret:db/prod#password} => {{(secret "db/prod#password")}}
+		expr, spec = "(secret "+strconv.Quote(p.spec)+")", ""
+	}
+	for _, f := range p.filters {
+		expr = "(" + f.name + " " + expr
+		for _, arg := range f.args {
+			expr += " " + strconv.Quote(arg)
 		}
-	})
-}
-
-// formatNumber is a helper function that formats a number according to the given format specifier.
-// It supports formatting for thousands separators and decimal precision.
-func formatNumber(value float64, format string) string {
-	// Split the format string to identify thousands and decimal parts.
-	formatParts := strings.Split(format, ".")
-	if strings.Contains(formatParts[0], ",") && len(formatParts) == 1 {
-		intPart := fmt.Sprintf("%.0f", value) // Get the integer part
-		for i := len(intPart) - 3; i > 0; i -= 3 {
-			intPart = intPart[:i] + "," + intPart[i:]
+		expr += ")"
+	}
+	if spec == "" {
+		return "{{" + expr + "}}", true
+	}
+	if matches := termSpecRe.FindStringSubmatch(spec); matches != nil {
+		// example format: {title:^term} => title centred across the terminal width
+		return fmt.Sprintf("{{alignTerm %s %q}}", expr, matches[1]), true
+	}
+	if change, args, ok := parsePctSpec(spec); ok {
+		return pctAction(expr, change, args), true
+	}
+	if isStyleSpec(spec) {
+		// example format: {status:red,bold} => status in bold red
+		return fmt.Sprintf("{{style %s %q}}", expr, spec), true
+	}
+	if c, ok := parseNamedSpec(spec); ok {
+		if c.name == "dms" && len(c.args) == 0 && !p.literal {
+			// example format: {lat:dms} => {{dms .lat "lat"}}
+			c.args = []string{coordinateAxis(p.key)}
 		}
-		return intPart
-	} else if strings.Contains(formatParts[0], ",") && len(formatParts) == 2 {
-		// example format: {total:,.3f} and total is 123456789.9787968 => 123,456,789.979
-		strNumber := fmt.Sprintf("%."+formatParts[1]+"f", value)
-		parts := strings.Split(strNumber, ".")
-		decimalPart := parts[1]
-		intPart := parts[0]
-		for i := len(intPart) - 3; i > 0; i -= 3 {
-			intPart = intPart[:i] + "," + intPart[i:]
+		// example format: {old:diff(new)} => {{diff .old .new}}
+		// example format: {p:frac(16)} => {{frac .p "16"}}
+		action := "{{" + c.name + " " + expr
+		for _, arg := range c.args {
+			if namedSpecs[c.name].refs {
+				action += " ." + arg
+			} else {
+				action += " " + strconv.Quote(arg)
+			}
 		}
-		return intPart + "." + decimalPart
-	} else if !strings.Contains(formatParts[0], ",") && len(formatParts) == 2 {
-		// example format: {gpa:.4f} and gpa is 3.165789 => 3.1658
-		return fmt.Sprintf("%."+formatParts[1]+"f", value)
-	} else {
-		panic("Invalid format")
+		return action + "}}", true
+	}
+	if !specRe.MatchString(spec) {
+		return "", false
 	}
+	// example format: {balance:,} and balance is 123456789.111 => 123,456,789
+	// example format: {total:.3f} and total is 123456789.9787968 => 123456789.979
+	// example format: {total:,.3f} and total is 123456789.9787968 => 123,456,789.979
+	return fmt.Sprintf("{{formatNumber %s %q}}", expr, spec), true
 }
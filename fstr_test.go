@@ -41,6 +41,37 @@ func TestInterpolate(t *testing.T) {
 			},
 			want: "Ziad Mansour - 23 - 123,456,789 - 3.1657 - 123,456,789.979 - 123,456,789",
 		},
+		{
+			name:   "Diff formatting",
+			format: "user updated: {old:diff(new)}",
+			data: map[string]interface{}{
+				"old": map[string]string{"name": "A", "role": "admin"},
+				"new": map[string]string{"name": "B", "role": "viewer"},
+			},
+			want: "user updated: name: A→B, role: admin→viewer",
+		},
+		{
+			name:   "Expand all",
+			format: "audit: {*}",
+			data: map[string]interface{}{
+				"name":    "Ziad Mansour",
+				"age":     23,
+				"balance": 123.5,
+			},
+			want: `audit: age=23 balance=123.5 name="Ziad Mansour"`,
+		},
+		{
+			name:   "Expand all with kv spec",
+			format: "{*:kv}",
+			data:   map[string]interface{}{"b": "2", "a": 1},
+			want:   "a=1 b=2",
+		},
+		{
+			name:   "Unknown spec is left untouched",
+			format: "{name:unknown}",
+			data:   map[string]interface{}{"name": "Ziad"},
+			want:   "{name:unknown}",
+		},
 		// Add more test cases as needed here.
 	}
 
@@ -102,3 +133,75 @@ func TestEval(t *testing.T) {
 		})
 	}
 }
+
+func TestEvalOr(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		fallback string
+		want     string
+	}{
+		{name: "Success", format: "user {name} logged in", fallback: "user logged in", want: "user ziad logged in"},
+		{name: "Fallback", format: "user {name:,} logged in", fallback: "user logged in", want: "user logged in"},
+		{name: "Raw format", format: "user {name:,} logged in", want: "user {name:,} logged in"},
+		{name: "Bad spec", format: "{name:roman}", fallback: "?", want: "?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvalOr(tt.format, map[string]interface{}{"name": "ziad"}, tt.fallback)
+			if got != tt.want {
+				t.Errorf("EvalOr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimMarkers(t *testing.T) {
+	data := map[string]interface{}{
+		"name":  "Ziad",
+		"items": []map[string]interface{}{{"n": 1}, {"n": 2}},
+	}
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{name: "Trim before", format: "Hello   \n\t{-name}!", want: "HelloZiad!"},
+		{name: "Trim after", format: "{name-}  \n !", want: "Ziad!"},
+		{name: "Both", format: "[ {-name-} ]", want: "[Ziad]"},
+		{name: "Filters", format: "a {-name|snake|truncate(2)-} b", want: "az…b"},
+		{
+			name:   "Loop block lines",
+			format: "Items:\n{#each items-}\n- {n}\n{-/each}\nDone",
+			want:   "Items:\n- 1- 2\nDone",
+		},
+		{
+			name:   "Loop block without blank lines",
+			format: "Items:\n{-#each items-}\n- {n}\n{/each-}\nDone",
+			want:   "Items:- 1\n- 2\nDone",
+		},
+		{name: "Not a tag", format: "{-} a - } {-x|nope} {-1-2}", want: "{-} a - } {-x|nope} {-1-2}"},
+		{name: "Unmarked", format: " {name} ", want: " Ziad "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Interpolate(tt.format, data)
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if reqs, _ := Requirements("{-name-} {-#each items}{n}{/each}"); len(reqs) != 2 || reqs[0].Key != "name" {
+		t.Errorf("Requirements() = %v, want name and items", reqs)
+	}
+	if got, errs := New(WithStrictKeys()).InterpolatePartial("a {-missing-} b {-name}", data); got != "a{missing}bZiad" || len(errs) != 1 {
+		t.Errorf("InterpolatePartial() = %q, %v", got, errs)
+	}
+	if got, err := Extract("id: {-id-} .", "id:42."); err != nil || got["id"] != "42" {
+		t.Errorf("Extract() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,27 @@
+package fstr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// fx formats an exchange rate with as many decimals as its precision needs: {rate:fx} keeps 5
+// significant figures, with 2 to 6 decimal places, and groups the integer part by thousands. It
+// renders 1.087312 as "1.0873", 151.3249 as "151.32", 15234.5 as "15,234.50" and 0.00679341 as
+// "0.006793", where a fixed .2f would destroy the precision of small rates and .6f clutter large
+// ones. The number of significant figures can be given, as in {rate:fx(6)}.
+func (i *Interpolator) fx(loc *numberSymbols, value interface{}, sig ...string) (string, error) {
+	n := 5
+	if len(sig) > 0 {
+		var err error
+		if n, err = strconv.Atoi(sig[0]); err != nil || n < 1 || n > maxSpecPrecision {
+			return "", &Error{Spec: "fx", Err: fmt.Errorf("invalid number of significant figures %q", sig[0]), kind: ErrBadSpec}
+		}
+	}
+	decimals := 2 // for NaN and infinite values, rendered by formatNumber
+	if number, err := formatDecimal(value, -1); err == nil && isDigits(number[len(number)-1:]) {
+		_, _, point := significand(number, n, false)
+		decimals = min(max(n-point, 2), 6)
+	}
+	return i.formatNumber(loc, value, ",."+strconv.Itoa(decimals)+"f")
+}
@@ -0,0 +1,47 @@
+package fstr
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFX(t *testing.T) {
+	tests := []struct {
+		format string
+		rate   interface{}
+		want   string
+	}{
+		{format: "{rate:fx}", rate: 1.087312, want: "1.0873"},
+		{format: "{rate:fx}", rate: 151.3249, want: "151.32"},
+		{format: "{rate:fx}", rate: 15234.5, want: "15,234.50"},
+		{format: "{rate:fx}", rate: 0.00679341, want: "0.006793"},
+		{format: "{rate:fx}", rate: 0.91, want: "0.91000"},
+		{format: "{rate:fx}", rate: 83, want: "83.000"},
+		{format: "{rate:fx(6)}", rate: 1.087312, want: "1.08731"},
+		{format: "{rate:fx(3)}", rate: 1.087312, want: "1.09"},
+		{format: "{rate:fx}", rate: math.NaN(), want: "NaN"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"rate": tt.rate})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.rate, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.rate, got, tt.want)
+		}
+	}
+	got, err := New(WithLocale("de")).Interpolate("{rate:fx}", map[string]interface{}{"rate": 15234.5})
+	if want := "15.234,50"; err != nil || got != want {
+		t.Errorf("Interpolate(de) = %q, %v, want %q", got, err, want)
+	}
+	if _, err := Interpolate("{rate:fx}", map[string]interface{}{"rate": "1.08"}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Interpolate(string) error = %v, want ErrUnsupportedType", err)
+	}
+	for _, format := range []string{"{rate:fx(0)}", "{rate:fx(100000000000)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"rate": 1.08}); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("Interpolate(%q) error = %v, want ErrBadSpec", format, err)
+		}
+	}
+}
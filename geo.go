@@ -0,0 +1,92 @@
+package fstr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// dms renders decimal degrees in degrees, minutes and seconds, for mapping and geo tooling that reports
+// positions in messages: {lat:dms} renders 48.8566 as "48°51′24″N" and {lon:dms} renders 2.3522 as
+// "2°21′08″E". The axis, "lat" or "lon", is the spec's argument, as in {pos:dms(lat)}; without one it
+// is inferred from the key: keys starting with "lat" are latitudes, and those starting with "lon" or
+// "lng" longitudes. Other values are rendered with a sign instead of a hemisphere: "-48°51′24″".
+// Seconds are rounded to the nearest whole second.
+func dms(value interface{}, axis ...string) (string, error) {
+	r, err := toRat(value)
+	if err != nil {
+		return "", &Error{Spec: "dms", Err: err, kind: ErrUnsupportedType}
+	}
+	deg, _ := r.Float64()
+	hemispheres, limit := "", 180.0
+	if len(axis) > 0 {
+		switch axis[0] {
+		case "lat":
+			hemispheres, limit = "NS", 90
+		case "lon":
+			hemispheres = "EW"
+		case "":
+		default:
+			return "", &Error{Spec: "dms", Err: fmt.Errorf("unknown axis %q", axis[0]), kind: ErrBadSpec}
+		}
+	}
+	if math.Abs(deg) > limit {
+		return "", &Error{Spec: "dms", Err: fmt.Errorf("%v degrees out of range", deg), kind: ErrUnsupportedType}
+	}
+	seconds := int64(math.Round(math.Abs(deg) * 3600))
+	s := fmt.Sprintf("%d°%02d′%02d″", seconds/3600, seconds/60%60, seconds%60)
+	switch {
+	case hemispheres == "" && deg < 0 && seconds > 0:
+		return "-" + s, nil
+	case hemispheres == "":
+		return s, nil
+	case deg < 0:
+		return s + hemispheres[1:], nil
+	default:
+		return s + hemispheres[:1], nil
+	}
+}
+
+// coordinateAxis returns the axis of the coordinate stored under key, "lat" or "lon", as inferred by
+// dms, or "" if it is neither.
+func coordinateAxis(key string) string {
+	key = strings.ToLower(key)
+	switch {
+	case strings.HasPrefix(key, "lat"):
+		return "lat"
+	case strings.HasPrefix(key, "lon"), strings.HasPrefix(key, "lng"):
+		return "lon"
+	default:
+		return ""
+	}
+}
+
+// dmsRe matches a position in degrees, minutes and seconds, as rendered by dms or written with ASCII
+// quotes and spaces: 48°51′24″N, -48°51'24.5", 2° 21' 8" E.
+var dmsRe = regexp.MustCompile(`^([-+])?\s*([0-9]+(?:\.[0-9]+)?)\s*°\s*(?:([0-9]+(?:\.[0-9]+)?)\s*['′]\s*)?(?:([0-9]+(?:\.[0-9]+)?)\s*(?:["″]|'')\s*)?([NSEW])?$`)
+
+// deg parses a position in degrees, minutes and seconds into decimal degrees, the reverse of dms:
+// {pos|deg:.4f} renders "48°51′24″N" as "48.8567". Southern and western positions are negative.
+func deg(value interface{}) (float64, error) {
+	s := strings.TrimSpace(fmt.Sprint(value))
+	m := dmsRe.FindStringSubmatch(s)
+	if m == nil || m[1] != "" && m[5] != "" {
+		return 0, &Error{Spec: "deg", Err: fmt.Errorf("invalid position %q", s), kind: ErrUnsupportedType}
+	}
+	var parts [3]float64
+	for n, text := range m[2:5] {
+		if text != "" {
+			parts[n], _ = strconv.ParseFloat(text, 64)
+		}
+	}
+	if parts[1] >= 60 || parts[2] >= 60 {
+		return 0, &Error{Spec: "deg", Err: fmt.Errorf("invalid position %q", s), kind: ErrUnsupportedType}
+	}
+	d := parts[0] + parts[1]/60 + parts[2]/3600
+	if m[1] == "-" || m[5] == "S" || m[5] == "W" {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,78 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDMS(t *testing.T) {
+	tests := []struct {
+		format string
+		value  interface{}
+		want   string
+	}{
+		{format: "{lat:dms}", value: 48.8566, want: "48°51′24″N"},
+		{format: "{lon:dms}", value: 2.3522, want: "2°21′08″E"},
+		{format: "{latitude:dms}", value: -33.8688, want: "33°52′08″S"},
+		{format: "{lng:dms}", value: -151.2093, want: "151°12′33″W"},
+		{format: "{pos:dms(lat)}", value: 0, want: "0°00′00″N"},
+		{format: "{pos:dms}", value: -48.8566, want: "-48°51′24″"},
+		{format: "{lat:dms}", value: 89.99999, want: "90°00′00″N"},
+		{format: "{lat:dms}", value: 45, want: "45°00′00″N"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"lat": tt.value, "lon": tt.value, "latitude": tt.value, "lng": tt.value, "pos": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.value, got, tt.want)
+		}
+	}
+	errs := []struct {
+		format string
+		value  interface{}
+		kind   error
+	}{
+		{format: "{lat:dms}", value: 91.0, kind: ErrUnsupportedType},
+		{format: "{lon:dms}", value: -180.5, kind: ErrUnsupportedType},
+		{format: "{lat:dms}", value: "north", kind: ErrUnsupportedType},
+		{format: "{lat:dms(alt)}", value: 1, kind: ErrBadSpec},
+	}
+	for _, tt := range errs {
+		if _, err := Interpolate(tt.format, map[string]interface{}{"lat": tt.value, "lon": tt.value}); !errors.Is(err, tt.kind) {
+			t.Errorf("Interpolate(%q, %v) error = %v, want %v", tt.format, tt.value, err, tt.kind)
+		}
+	}
+}
+
+func TestDeg(t *testing.T) {
+	tests := []struct {
+		pos  string
+		want string
+	}{
+		{pos: "48°51′24″N", want: "48.8567"},
+		{pos: "2°21′08″E", want: "2.3522"},
+		{pos: "33°52′08″S", want: "-33.8689"},
+		{pos: `151° 12' 33" W`, want: "-151.2092"},
+		{pos: "-48°51'24.5\"", want: "-48.8568"},
+		{pos: "12.5°", want: "12.5000"},
+		{pos: "12°30′", want: "12.5000"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{pos|deg:.4f}", map[string]interface{}{"pos": tt.pos})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.pos, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.pos, got, tt.want)
+		}
+	}
+	for _, pos := range []string{"north", "48°61′00″N", "-48°51′24″S", "48N"} {
+		if _, err := Interpolate("{pos|deg}", map[string]interface{}{"pos": pos}); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Interpolate(%q) error = %v, want ErrUnsupportedType", pos, err)
+		}
+	}
+}
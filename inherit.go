@@ -0,0 +1,64 @@
+package fstr
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// namedBlockRe matches the tags of overridable blocks: {block name} opens a block and {/block} closes it.
+var namedBlockRe = regexp.MustCompile(`{block ([a-zA-Z0-9_]+)}|{/block}`)
+
+// extendsRe matches the {extends name} tag that starts a child format string.
+var extendsRe = regexp.MustCompile(`^\s*{extends ([a-zA-Z0-9_]+)}`)
+
+// namedBlockAction converts an overridable block tag into a text/template action.
+func namedBlockAction(tag string) string {
+	matches := namedBlockRe.FindStringSubmatch(tag)
+	if matches[1] == "" {
+		return "{{end}}"
+	}
+	// example format: {block title}Welcome{/block} => {{block "title" .}}Welcome{{end}}
+	return fmt.Sprintf("{{block %q .}}", matches[1])
+}
+
+// inheritance returns the inheritance chain of format, without the {extends name} tags: format
+// itself, followed by the partial it extends, if any, and so on up to a partial that extends none.
+func (i *Interpolator) inheritance(format string) ([]string, error) {
+	var chain []string
+	seen := make(map[string]bool)
+	for f := format; ; {
+		m := extendsRe.FindStringSubmatchIndex(f)
+		if m == nil {
+			return append(chain, f), nil
+		}
+		chain = append(chain, f[m[1]:])
+		base := f[m[2]:m[3]]
+		if seen[base] {
+			return nil, &Error{Format: format, Err: fmt.Errorf("partial %q extends itself", base), kind: ErrParse}
+		}
+		seen[base] = true
+		var ok bool
+		if f, ok = i.lookupPartial(base); !ok {
+			return nil, &Error{Format: format, Err: fmt.Errorf("unknown partial %q", base), kind: ErrParse}
+		}
+	}
+}
+
+// extends reports whether format extends the partial name, directly or through other partials.
+func (i *Interpolator) extends(format, name string) bool {
+	seen := make(map[string]bool)
+	for f := format; ; {
+		m := extendsRe.FindStringSubmatch(f)
+		if m == nil || seen[m[1]] {
+			return false
+		}
+		if m[1] == name {
+			return true
+		}
+		seen[m[1]] = true
+		var ok bool
+		if f, ok = i.lookupPartial(m[1]); !ok {
+			return false
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInheritance(t *testing.T) {
+	interp := New(WithStrictKeys(), WithPartials(map[string]string{
+		"email":    "Subject: {block subject}News{/block}\n\n{block body}Nothing new.{/block}\n-- {sender}",
+		"invoice":  "{extends email}{block subject}Invoice {id}{/block}{block body}You owe {amount:,.2f}.{/block}",
+		"loop":     "{extends loop}",
+		"orphan":   "{extends nowhere}",
+		"listing":  "{#each items}{block item}- {name}{/block}\n{/each}",
+		"bad_base": "{block body}{/each}",
+	}))
+	data := map[string]interface{}{
+		"sender": "Billing", "id": 42, "amount": 1234.5,
+		"items": []map[string]interface{}{{"name": "tea"}, {"name": "cake"}},
+	}
+	tests := []struct {
+		name   string
+		format string
+		want   string
+		err    error
+	}{
+		{name: "Defaults", format: "{>email}", want: "Subject: News\n\nNothing new.\n-- Billing"},
+		{
+			name:   "Override one block",
+			format: "{extends email}\nignored text {block body}Hello!{/block}",
+			want:   "Subject: News\n\nHello!\n-- Billing",
+		},
+		{
+			name:   "Grandchild",
+			format: "{extends invoice}{block subject}Reminder: invoice {id}{/block}",
+			want:   "Subject: Reminder: invoice 42\n\nYou owe 1,234.50.\n-- Billing",
+		},
+		{name: "Block inside loop block", format: "{extends listing}{block item}* {name|snake}{/block}", want: "* tea\n* cake\n"},
+		{name: "Standalone block", format: "[{block title}Default{/block}]", want: "[Default]"},
+		{name: "Trim markers", format: "{block a-}\n x\n{-/block}", want: "x"},
+		{name: "Unknown base", format: "{extends nowhere}", err: ErrParse},
+		{name: "Cycle", format: "{extends loop}", err: ErrParse},
+		{name: "Unknown base of base", format: "{extends orphan}", err: ErrParse},
+		{name: "Broken base", format: "{extends bad_base}", err: ErrParse},
+		{name: "Mismatched tags", format: "{#each items}{block a}{/each}{/block}", err: ErrParse},
+		{name: "Stray closing tag", format: "x{/block}", err: ErrParse},
+		{name: "Missing key in block", format: "{extends email}{block body}{nope}{/block}", err: ErrMissingKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interp.Interpolate(tt.format, data)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Interpolate() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
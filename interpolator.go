@@ -0,0 +1,679 @@
+package fstr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Interpolator performs string interpolation with a fixed set of options.
+// The package-level functions use an Interpolator with the default options;
+// create one with New when the defaults need to be changed.
+//
+// An Interpolator is safe for concurrent use by multiple goroutines.
+type Interpolator struct {
+	keyOrder      KeyOrder
+	fallbackWidth int
+	nonFinite     NonFiniteFunc
+	decimal       DecimalFunc
+	unused        func(format string, keys []string)
+	missingKey    func(key string) (interface{}, bool)
+	strictKeys    bool
+	partials      map[string]string // guarded by mu
+	secrets       SecretResolver
+	fileRoot      string
+	commands      map[string]bool
+	execTimeout   time.Duration
+	execMaxOutput int
+	depth         int
+	escape        EscapeMode
+	locale        *numberSymbols // nil for the default separators
+	metrics       Metrics
+	errorHook     func(*Error)
+	color         ColorMode
+	allErrors     bool
+	marker        *string
+	wrap          bool
+	wrapWidth     int
+
+	mu sync.RWMutex
+
+	// cache holds the most recently used parsed templates; see WithCacheSize.
+	cache parseCache
+}
+
+// renderMode holds the settings of a render that can change from one render to the next: whether
+// styles emit ANSI escape codes, and the separators of numbers, nil for the default ones.
+type renderMode struct {
+	color  bool
+	locale *numberSymbols
+}
+
+// mode returns the renderMode rendering styles only if color is set, in the Interpolator's locale.
+func (i *Interpolator) mode(color bool) renderMode {
+	return renderMode{color: color, locale: i.locale}
+}
+
+// cacheKey identifies a parsed template: a format string is parsed once for each renderMode it is
+// rendered with, as needed.
+type cacheKey struct {
+	format string
+	mode   renderMode
+	value  bool // parsed as a data value, with valueFuncs
+}
+
+// Option configures an Interpolator.
+type Option func(*Interpolator)
+
+// defaultInterpolator backs the package-level functions.
+var defaultInterpolator = New()
+
+// New returns an Interpolator configured with the given options.
+//
+// Example usage:
+//
+//	interp := fstr.New(fstr.WithKeyOrder(fstr.FixedOrder("id", "name")))
+//	fmt.Println(interp.Eval("{*}", data))
+func New(opts ...Option) *Interpolator {
+	i := &Interpolator{
+		keyOrder:      SortedKeys,
+		fallbackWidth: defaultTerminalWidth,
+		nonFinite:     NonFiniteSymbols,
+	}
+	i.cache.size = defaultCacheSize
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// WithKeyOrder sets the order in which map keys are emitted by placeholders that render
+// whole maps, such as {*}, {labels:kv} and {old:diff(new)}. The default is SortedKeys.
+func WithKeyOrder(order KeyOrder) Option {
+	return func(i *Interpolator) {
+		i.keyOrder = order
+	}
+}
+
+// WithFallbackWidth sets the terminal width assumed by terminal-aligned placeholders such as
+// {title:^term} when stdout is not a terminal and the COLUMNS environment variable is unset.
+// The default is 80.
+func WithFallbackWidth(width int) Option {
+	return func(i *Interpolator) {
+		i.fallbackWidth = width
+	}
+}
+
+// WithNonFinite sets how numeric specifiers render NaN and infinite values.
+// The default is NonFiniteSymbols.
+func WithNonFinite(fn NonFiniteFunc) Option {
+	return func(i *Interpolator) {
+		i.nonFinite = fn
+	}
+}
+
+// WithDecimal registers an exact decimal implementation used by numeric specifiers. Values it
+// accepts are formatted without passing through float64, so {amount:,.2f} never shows binary
+// floating-point artifacts. For example, with github.com/shopspring/decimal:
+//
+//	interp := fstr.New(fstr.WithDecimal(func(v interface{}, precision int) (string, bool) {
+//		d, ok := v.(decimal.Decimal)
+//		if !ok {
+//			return "", false
+//		}
+//		if precision < 0 {
+//			return d.String(), true
+//		}
+//		return d.StringFixed(int32(precision)), true
+//	}))
+func WithDecimal(fn DecimalFunc) Option {
+	return func(i *Interpolator) {
+		i.decimal = fn
+	}
+}
+
+// WithUnusedKeysFunc registers a callback that reports the data map keys a format string never
+// referenced, which usually points at a typo such as {frist_name} for a "first_name" key. Keys used
+// by the partials the format string includes or extends count as referenced. The callback is
+// invoked after every successful interpolation that leaves keys unused, with the keys arranged by
+// the configured KeyOrder.
+//
+// Example usage:
+//
+//	interp := fstr.New(fstr.WithUnusedKeysFunc(func(format string, keys []string) {
+//		log.Printf("fstr: %q ignores %v", format, keys)
+//	}))
+func WithUnusedKeysFunc(fn func(format string, keys []string)) Option {
+	return func(i *Interpolator) {
+		i.unused = fn
+	}
+}
+
+// WithMissingKeyFunc registers a resolver for keys that a format string uses but the data map lacks,
+// enabling fallback lookups in environment variables, a feature-flag service or a secondary map
+// without merging everything into the data map up front. It is called once per missing key and
+// interpolation; if it reports false, the key remains missing. Keys used by the partials the format
+// string includes or extends are resolved too; keys of the elements of loop blocks are not.
+//
+// Example usage:
+//
+//	interp := fstr.New(fstr.WithMissingKeyFunc(func(key string) (interface{}, bool) {
+//		return os.LookupEnv(strings.ToUpper(key))
+//	}))
+func WithMissingKeyFunc(fn func(key string) (interface{}, bool)) Option {
+	return func(i *Interpolator) {
+		i.missingKey = fn
+	}
+}
+
+// WithStrictKeys makes a placeholder whose key is missing from the data map fail with an
+// ErrMissingKey *Error, which suggests a similarly-spelled key if there is one, rather than render
+// "<no value>". Keys resolved WithMissingKeyFunc are not missing.
+func WithStrictKeys() Option {
+	return func(i *Interpolator) {
+		i.strictKeys = true
+	}
+}
+
+// WithPartials registers format strings that other format strings include by name with {>name},
+// such as shared headers and footers of emails. A partial is rendered in place with the same data:
+// the data map, or the element inside a loop block.
+//
+//	interp := fstr.New(fstr.WithPartials(map[string]string{
+//		"signature": "--\n{sender}, {company}",
+//	}))
+//	msg := interp.Eval("Hi {name},\n\n{body}\n\n{>signature}", data)
+//
+// Including an unknown partial, or a partial that includes itself, fails with an ErrParse *Error.
+// See Define for partials taking arguments.
+func WithPartials(partials map[string]string) Option {
+	return func(i *Interpolator) {
+		if i.partials == nil {
+			i.partials = make(map[string]string, len(partials))
+		}
+		for name, format := range partials {
+			i.partials[name] = format
+		}
+	}
+}
+
+// WithMetrics registers m to observe the Interpolator's work: every render with its duration and
+// outcome, and every format string lookup in the parse cache. See Metrics.
+func WithMetrics(m Metrics) Option {
+	return func(i *Interpolator) {
+		i.metrics = m
+	}
+}
+
+// WithCacheSize sets the number of parsed format strings the Interpolator keeps, so that repeated
+// interpolations of a format string parse it only once. When the cache is full, the least recently
+// used format string is dropped. The default is 1000; 0 disables the cache.
+func WithCacheSize(size int) Option {
+	return func(i *Interpolator) {
+		i.cache.size = size
+	}
+}
+
+// WithColor sets when styles such as {status:red,bold} and hyperlinks such as {url:link(Docs)}
+// emit escape codes. The default, ColorAuto, emits them only if the output goes to a terminal: the
+// writer passed to Fprint, or stdout for Print and Println. Functions returning a string, such as
+// Interpolate, Table and CSV, cannot tell where it will be written and emit them only with
+// ColorAlways. Otherwise the values are rendered as plain text. ColorAuto also honours the NO_COLOR,
+// FORCE_COLOR and CLICOLOR_FORCE environment variables; ColorAlways and ColorNever override them.
+func WithColor(mode ColorMode) Option {
+	return func(i *Interpolator) {
+		i.color = mode
+	}
+}
+
+// WithAllErrors makes a failed interpolation report every problem in the format string, such as all
+// of its missing keys and invalid specifiers, rather than only the first one, so that a template can
+// be fixed in one round trip. When there is more than one, the returned error joins the *Error
+// values with errors.Join; use errors.Is and errors.As to inspect them.
+func WithAllErrors() Option {
+	return func(i *Interpolator) {
+		i.allErrors = true
+	}
+}
+
+// WithPartialMarker sets the text that InterpolatePartial substitutes for each placeholder or loop
+// block that failed to render. By default the failed placeholder is kept as written, e.g. "{name}".
+func WithPartialMarker(marker string) Option {
+	return func(i *Interpolator) {
+		i.marker = &marker
+	}
+}
+
+// WithWrap makes Print and Println soft-wrap their output at width columns, or at the terminal width
+// if width is 0, so that long interpolated sentences remain readable. Lines are only broken between
+// words; a word wider than a line is kept whole. ANSI escape sequences take up no width.
+func WithWrap(width int) Option {
+	return func(i *Interpolator) {
+		i.wrap = true
+		i.wrapWidth = width
+	}
+}
+
+// WithErrorHook registers a callback invoked with every interpolation failure, so that services can
+// count or log them centrally, for example in metrics or an error tracker, even where call sites use
+// Eval or Print, which panic. The hook runs before the error is returned or the panic is raised; for
+// an Interpolator configured WithAllErrors, it runs once for each problem found.
+func WithErrorHook(fn func(*Error)) Option {
+	return func(i *Interpolator) {
+		i.errorHook = fn
+	}
+}
+
+// Interpolate performs string interpolation on the provided format string using the given data map.
+// See the package-level Interpolate for the supported placeholder syntax.
+//
+// Parsed format strings are cached, so interpolating the same format string repeatedly only parses it once.
+func (i *Interpolator) Interpolate(format string, data map[string]interface{}) (string, error) {
+	return i.interpolate(format, data, i.mode(i.stringColor()))
+}
+
+// interpolate implements Interpolate and Fprint, rendering in mode m.
+func (i *Interpolator) interpolate(format string, data map[string]interface{}, m renderMode) (string, error) {
+	start := time.Now()
+	result, err := i.execute(format, data, m)
+	if i.metrics != nil {
+		i.metrics.ObserveRender(time.Since(start), err)
+	}
+	if err != nil && i.errorHook != nil {
+		i.reportError(err)
+	}
+	return result, err
+}
+
+// reportError passes each *Error in err to the error hook.
+func (i *Interpolator) reportError(err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			i.reportError(err)
+		}
+		return
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		i.errorHook(e)
+	}
+}
+
+// execute renders format and reports its unused keys.
+func (i *Interpolator) execute(format string, data map[string]interface{}, m renderMode) (string, error) {
+	data = i.resolveMissing(format, data)
+	data, err := i.expandValues(format, data, i.depth, m)
+	if err != nil {
+		return "", err
+	}
+	result, err := i.render(format, data, m)
+	if err != nil {
+		if i.allErrors && !errors.Is(err, ErrParse) {
+			return "", i.renderErrors(format, data, m, err)
+		}
+		return "", err
+	}
+	if i.unused != nil {
+		if keys := i.unusedKeys(format, data); len(keys) > 0 {
+			i.unused(format, keys)
+		}
+	}
+	return result, nil
+}
+
+// resolveMissing returns data extended with the values that the WithMissingKeyFunc resolver provides
+// for the keys format uses but data lacks. data itself is not modified.
+func (i *Interpolator) resolveMissing(format string, data map[string]interface{}) map[string]interface{} {
+	if i.missingKey == nil {
+		return data
+	}
+	reqs, _ := i.requirements(format)
+	var resolved map[string]interface{}
+	for _, r := range reqs {
+		if _, ok := data[r.Key]; ok {
+			continue
+		}
+		if v, ok := i.missingKey(r.Key); ok {
+			if resolved == nil {
+				resolved = make(map[string]interface{}, len(data)+1)
+				for k, v := range data {
+					resolved[k] = v
+				}
+			}
+			resolved[r.Key] = v
+		}
+	}
+	if resolved == nil {
+		return data
+	}
+	return resolved
+}
+
+// renderErrors collects the errors of every fragment of a format string that failed with err,
+// by rendering the fragments one at a time.
+func (i *Interpolator) renderErrors(format string, data map[string]interface{}, m renderMode, err error) error {
+	var errs []error
+	for _, f := range fragments(format) {
+		if _, ferr := i.render(f, data, m); ferr != nil {
+			var e *Error
+			if errors.As(ferr, &e) {
+				e.Format = format
+			}
+			errs = append(errs, ferr)
+		}
+	}
+	switch len(errs) {
+	case 0:
+		return err
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
+
+// render interpolates format with data without invoking any of the hooks. It is used for
+// fragments, such as table cells, that are rendered as part of a larger interpolation.
+func (i *Interpolator) render(format string, data map[string]interface{}, m renderMode) (string, error) {
+	t, err := i.parse(format, m)
+	if err != nil {
+		return "", err
+	}
+	var output bytes.Buffer
+	if err := t.Execute(&output, data); err != nil {
+		return "", executeError(format, t, data, err)
+	}
+	return output.String(), nil
+}
+
+// renderValue is like render for a data value interpolated by WithRecursion, which must not reach
+// secrets, files, commands or partials; see valueFuncs.
+func (i *Interpolator) renderValue(format string, data map[string]interface{}, m renderMode) (string, error) {
+	t, err := i.parseKey(cacheKey{format: format, mode: m, value: true})
+	if err != nil {
+		return "", err
+	}
+	var output bytes.Buffer
+	if err := t.Execute(&output, data); err != nil {
+		return "", executeError(format, t, data, err)
+	}
+	return output.String(), nil
+}
+
+// InterpolatePartial is like Interpolate but never gives up: it always returns the best possible
+// output, substituting a marker for each placeholder that failed to render, along with the problems
+// found. This suits logging paths, where a typo in a template must not lose the rest of the message.
+// See WithPartialMarker.
+func (i *Interpolator) InterpolatePartial(format string, data map[string]interface{}) (string, []error) {
+	m := i.mode(i.stringColor())
+	data = i.resolveMissing(format, data)
+	result, err := i.interpolate(format, data, m)
+	if err == nil {
+		return result, nil
+	}
+	if expanded, xerr := i.expandValues(format, data, i.depth, m); xerr == nil {
+		data = expanded
+	}
+	var (
+		b    strings.Builder
+		errs []error
+	)
+	for _, seg := range segments(format) {
+		if !seg.fragment {
+			b.WriteString(seg.text)
+			continue
+		}
+		s, err := i.render(seg.text, data, m)
+		if err != nil {
+			var e *Error
+			if errors.As(err, &e) {
+				e.Format = format
+			}
+			errs = append(errs, err)
+			s = seg.text
+			if i.marker != nil {
+				s = *i.marker
+			}
+		}
+		b.WriteString(s)
+	}
+	if len(errs) == 0 {
+		errs = append(errs, err)
+	}
+	return b.String(), errs
+}
+
+// Eval is like Interpolate but panics with the *Error if one occurs.
+func (i *Interpolator) Eval(format string, data map[string]interface{}) string {
+	result, err := i.Interpolate(format, data)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// EvalOr is like Eval but returns fallback instead of panicking if an error occurs,
+// or the format string itself if fallback is empty.
+func (i *Interpolator) EvalOr(format string, data map[string]interface{}, fallback string) string {
+	result, err := i.Interpolate(format, data)
+	if err != nil {
+		if fallback == "" {
+			return format
+		}
+		return fallback
+	}
+	return result
+}
+
+// Print interpolates the format string and prints the result to stdout.
+// If an error occurs during interpolation, Print panics with that error.
+func (i *Interpolator) Print(format string, data map[string]interface{}) {
+	fmt.Print(i.wrapped(i.printed(format, data)))
+}
+
+// Println interpolates the format string and prints the result to stdout, followed by a newline.
+// If an error occurs during interpolation, Println panics with that error.
+func (i *Interpolator) Println(format string, data map[string]interface{}) {
+	fmt.Println(i.wrapped(i.printed(format, data)))
+}
+
+// printed is like Eval but renders styles only if stdout is a terminal, for Print and Println.
+func (i *Interpolator) printed(format string, data map[string]interface{}) string {
+	result, err := i.interpolate(format, data, i.mode(i.colorEnabled(os.Stdout)))
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// wrapped soft-wraps output printed to stdout, if configured WithWrap.
+func (i *Interpolator) wrapped(s string) string {
+	if !i.wrap {
+		return s
+	}
+	width := i.wrapWidth
+	if width <= 0 {
+		width = terminalWidth(i.fallbackWidth)
+	}
+	return wrapText(s, width)
+}
+
+// Fprint interpolates the format string and writes the result to w. Unlike Print, it returns
+// interpolation errors, along with write errors and the number of bytes written.
+// Styles are only rendered if w is a terminal; see WithColor.
+func (i *Interpolator) Fprint(w io.Writer, format string, data map[string]interface{}) (int, error) {
+	result, err := i.interpolate(format, data, i.mode(i.colorEnabled(w)))
+	if err != nil {
+		return 0, err
+	}
+	return io.WriteString(w, result)
+}
+
+// Fprintln is like Fprint but adds a newline after the result.
+func (i *Interpolator) Fprintln(w io.Writer, format string, data map[string]interface{}) (int, error) {
+	result, err := i.interpolate(format, data, i.mode(i.colorEnabled(w)))
+	if err != nil {
+		return 0, err
+	}
+	return io.WriteString(w, result+"\n")
+}
+
+// parsed is a template parsed from a format string, along with its preprocessed text and the
+// placeholders that text was generated from.
+type parsed struct {
+	*template.Template
+	text    string
+	sources []source
+}
+
+// parse returns the parsed template for format, consulting the parse cache first.
+// The template renders in mode m.
+func (i *Interpolator) parse(format string, m renderMode) (*parsed, error) {
+	return i.parseKey(cacheKey{format: format, mode: m})
+}
+
+// parseKey implements parse, parsing key.format as a data value if key.value is set.
+func (i *Interpolator) parseKey(key cacheKey) (*parsed, error) {
+	format := key.format
+	if p, ok := i.cache.load(key); ok {
+		if i.metrics != nil {
+			i.metrics.ObserveParse(true)
+		}
+		return p, nil
+	}
+	if i.metrics != nil {
+		i.metrics.ObserveParse(false)
+	}
+	chain, err := i.inheritance(format)
+	if err != nil {
+		return nil, err
+	}
+	// Parse the root of the inheritance chain first, then each descendant, so that the blocks of
+	// format win. The text outside the blocks of a descendant is not rendered.
+	var p *parsed
+	for n := len(chain) - 1; n >= 0; n-- {
+		text, sources, err := preprocess(chain[n], i.escape != EscapeNone)
+		if err != nil {
+			return nil, err
+		}
+		var t *template.Template
+		if p == nil {
+			funcs := i.funcs(key.mode)
+			if key.value {
+				valueFuncs(funcs)
+			}
+			t = template.New("fstr").Funcs(funcs)
+			if i.strictKeys {
+				t.Option("missingkey=error")
+			}
+			p = &parsed{Template: t, text: text, sources: sources}
+		} else {
+			t = p.New(fmt.Sprintf("fstr%d", n))
+		}
+		if _, err := t.Parse(text); err != nil {
+			return nil, &Error{Format: format, Err: fmt.Errorf("failed to parse template: %w", err), kind: ErrParse}
+		}
+	}
+	i.cache.store(key, p)
+	return p, nil
+}
+
+// funcs returns the template functions available to preprocessed format strings.
+// They render in mode m.
+func (i *Interpolator) funcs(m renderMode) template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, spec := range namedSpecs {
+		if spec.fn != nil {
+			funcs[name] = spec.fn
+		}
+	}
+	for name, f := range filters {
+		if f.fn != nil {
+			funcs[name] = f.fn
+		}
+	}
+	for name, fn := range conversionFuncs() {
+		funcs[name] = fn
+	}
+	funcs["formatNumber"] = func(value interface{}, spec string) (string, error) {
+		return i.formatNumber(m.locale, value, spec)
+	}
+	funcs["alignTerm"] = i.alignTerm
+	funcs["center"] = i.center
+	funcs["columns"] = i.columns
+	funcs["fx"] = func(value interface{}, sig ...string) (string, error) {
+		return i.fx(m.locale, value, sig...)
+	}
+	funcs["matrix"] = func(value interface{}, spec ...string) (string, error) {
+		return i.matrix(m.locale, value, spec...)
+	}
+	funcs["money"] = func(value interface{}, options ...string) (string, error) {
+		return i.money(m.locale, value, options...)
+	}
+	funcs["token"] = func(value interface{}, decimals string, symbol ...string) (string, error) {
+		return i.token(m.locale, value, decimals, symbol...)
+	}
+	funcs["table"] = func(rows interface{}) (string, error) {
+		return i.table(rows, m)
+	}
+	funcs["invoice"] = func(rows interface{}, taxRate ...interface{}) (string, error) {
+		if len(taxRate) == 0 {
+			return i.invoice(rows, "", 0, m)
+		}
+		return i.invoice(rows, "", taxRate[0], m)
+	}
+	funcs["csv"] = i.csvRow
+	funcs["tsv"] = i.tsvRow
+	funcs["mdtable"] = func(rows interface{}) (string, error) {
+		return i.mdtable(rows, m)
+	}
+	funcs["each"] = i.each
+	funcs["secret"] = i.secret
+	funcs["file"] = i.file
+	funcs["escape"] = i.escape.escapeValue
+	funcs["exec"] = i.exec
+	funcs["partial"] = func(name string, data map[string]interface{}, args ...interface{}) (string, error) {
+		return i.partial(name, data, m, args...)
+	}
+	funcs["pct"] = func(value interface{}, change bool, args ...string) (string, error) {
+		return i.pct(value, change, m, args...)
+	}
+	funcs["style"] = func(value interface{}, styles string) (string, error) {
+		if !m.color {
+			return fmt.Sprint(value), nil
+		}
+		return style(value, styles)
+	}
+	funcs["link"] = func(value interface{}, text ...string) string {
+		return link(value, m.color, text...)
+	}
+	funcs["color"] = func(value interface{}, name string) (string, error) {
+		if !m.color {
+			if _, ok := ansiCodes[name]; ok {
+				return fmt.Sprint(value), nil
+			}
+		}
+		return colorize(value, name)
+	}
+	funcs["diff"] = func(a, b interface{}) string {
+		return diff(a, b, i.keyOrder)
+	}
+	funcs["kvAll"] = func(data map[string]interface{}) string {
+		return kvAll(data, i.keyOrder)
+	}
+	funcs["toYaml"] = func(value interface{}) string {
+		return toYaml(value, i.keyOrder)
+	}
+	funcs["json"] = i.jsonIndent
+	funcs["jsonc"] = i.jsonCompact
+	funcs["kv"] = func(value interface{}, seps ...string) (string, error) {
+		return kv(value, i.keyOrder, seps...)
+	}
+	return funcs
+}
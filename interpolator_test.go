@@ -0,0 +1,155 @@
+package fstr
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestInterpolatorKeyOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		order  KeyOrder
+		format string
+		data   map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "Sorted by default",
+			format: "{*}",
+			data:   map[string]interface{}{"name": "Ziad", "id": 7, "age": 23},
+			want:   "age=23 id=7 name=Ziad",
+		},
+		{
+			name:   "Fixed order",
+			order:  FixedOrder("id", "name"),
+			format: "{*}",
+			data:   map[string]interface{}{"name": "Ziad", "id": 7, "age": 23, "team": "core"},
+			want:   "id=7 name=Ziad age=23 team=core",
+		},
+		{
+			name:   "Fixed order in diffs",
+			order:  FixedOrder("role", "name"),
+			format: "{old:diff(new)}",
+			data: map[string]interface{}{
+				"old": map[string]string{"name": "A", "role": "admin"},
+				"new": map[string]string{"name": "B", "role": "viewer"},
+			},
+			want: "role: admin→viewer, name: A→B",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.order != nil {
+				opts = append(opts, WithKeyOrder(tt.order))
+			}
+			got, err := New(opts...).Interpolate(tt.format, tt.data)
+			if err != nil {
+				t.Errorf("Interpolate() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolatorUnusedKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   map[string]interface{}
+		want   []string
+	}{
+		{
+			name:   "Typo in template",
+			format: "Hello {frist_name} {last_name}",
+			data:   map[string]interface{}{"first_name": "Ziad", "last_name": "Mansour", "age": 23},
+			want:   []string{"age", "first_name"},
+		},
+		{
+			name:   "Diff references both keys",
+			format: "{old:diff(new)}",
+			data:   map[string]interface{}{"old": 1, "new": 2},
+		},
+		{
+			name:   "Expand all references every key",
+			format: "{*}",
+			data:   map[string]interface{}{"a": 1, "b": 2},
+		},
+		{
+			name:   "Partials reference their keys",
+			format: "{>sig} {>money v=total}",
+			data:   map[string]interface{}{"sender": "Ziad", "total": 3, "v": 1, "cur": "EUR"},
+			want:   []string{"v"},
+		},
+		{
+			name:   "Base templates reference their keys",
+			format: "{extends page}{block body}{name}{/block}",
+			data:   map[string]interface{}{"title": "Hi", "name": "Ziad", "typo": 1},
+			want:   []string{"typo"},
+		},
+	}
+	partials := map[string]string{
+		"sig":   "-- {sender}",
+		"money": "{v:,.2f} {cur}",
+		"page":  "{title}: {block body}{/block}",
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			interp := New(WithPartials(partials), WithUnusedKeysFunc(func(format string, keys []string) {
+				got = keys
+			}))
+			if _, err := interp.Interpolate(tt.format, tt.data); err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("unused keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterpolatorMissingKeyFunc(t *testing.T) {
+	var asked []string
+	fallback := map[string]interface{}{"region": "eu-west-1", "replicas": 3}
+	interp := New(WithStrictKeys(), WithMissingKeyFunc(func(key string) (interface{}, bool) {
+		asked = append(asked, key)
+		v, ok := fallback[key]
+		return v, ok
+	}))
+	data := map[string]interface{}{"service": "api"}
+
+	got, err := interp.Interpolate("{service} in {region} x{replicas:03} ({service})", data)
+	if err != nil {
+		t.Fatalf("Interpolate() error = %v", err)
+	}
+	if want := "api in eu-west-1 x003 (api)"; got != want {
+		t.Errorf("Interpolate() = %q, want %q", got, want)
+	}
+	if want := []string{"region", "replicas"}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("resolver asked for %v, want %v", asked, want)
+	}
+	if len(data) != 1 {
+		t.Errorf("Interpolate() modified the data map: %v", data)
+	}
+
+	_, err = interp.Interpolate("{service} {owner}", data)
+	if !errors.Is(err, ErrMissingKey) {
+		t.Errorf("Interpolate() error = %v, want ErrMissingKey", err)
+	}
+
+	if err := interp.Define("footer", "{service}@{region}"); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	if err := interp.Define("page", "{block body}{/block} x{replicas}"); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	got, err = interp.Interpolate("{extends page}{block body}{>footer}{/block}", data)
+	if want := "api@eu-west-1 x3"; err != nil || got != want {
+		t.Errorf("Interpolate() = %q, %v, want %q with keys resolved for partials", got, err, want)
+	}
+}
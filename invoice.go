@@ -0,0 +1,225 @@
+package fstr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// BasisPoints is a rate in hundredths of a percent, such as 1900 for 19%.
+type BasisPoints int64
+
+// Invoice renders invoice line items as a table with totals. See Interpolator.Invoice.
+func Invoice(rows interface{}, columns string, taxRate interface{}) (string, error) {
+	return defaultInterpolator.Invoice(rows, columns, taxRate)
+}
+
+// Invoice renders invoice line items, a slice of structs or of maps with string keys, as a table like
+// Table, followed by total rows computed from the data:
+//
+//	item      qty   price  amount
+//	--------  ---  ------  ------
+//	Widget      2  $10.00  $20.00
+//	Gadget      1   $5.50   $5.50
+//	--------  ---  ------  ------
+//	Subtotal               $25.50
+//	Tax                     $4.84
+//	Total                  $30.34
+//
+// Amounts are Money values, formatted with the columns' specs, or with the money spec if columns is
+// empty, and right-aligned. The last column of Money values is totalled; its amounts must share a
+// currency. If taxRate is not zero, it is applied to the subtotal, rounded half to even to the
+// subtotal's scale, and Subtotal and Tax rows precede the Total row. The rate is exact: a fraction
+// given as a string such as "0.19" or "19/100", a *big.Rat, BasisPoints such as 1900, or a number,
+// which is taken as its shortest decimal representation, so 0.19 is 19% and not the float's binary
+// approximation. Amounts that overflow an int64 return an ErrUnsupportedType *Error.
+//
+// The {items:invoice} spec renders an invoice with all columns, and {items:invoice(vat)} takes the
+// tax rate from the data map key vat.
+func (i *Interpolator) Invoice(rows interface{}, columns string, taxRate interface{}) (string, error) {
+	return i.invoice(rows, columns, taxRate, i.mode(i.stringColor()))
+}
+
+// invoice implements Invoice and the {items:invoice} spec, rendering cells in mode m.
+func (i *Interpolator) invoice(rows interface{}, columns string, taxRate interface{}, m renderMode) (string, error) {
+	recs, keys, err := records(rows, i.keyOrder)
+	if err != nil {
+		return "", &Error{Spec: "invoice", Err: err, kind: ErrUnsupportedType}
+	}
+	moneyColumn := func(key string) bool {
+		found := false
+		for _, r := range recs {
+			if v, ok := r[key]; ok {
+				if !isMoney(v) {
+					return false
+				}
+				found = true
+			}
+		}
+		return found
+	}
+	if columns == "" {
+		var b strings.Builder
+		for _, k := range keys {
+			if moneyColumn(k) {
+				b.WriteString("{" + k + ":money} ")
+			} else {
+				b.WriteString("{" + k + "} ")
+			}
+		}
+		columns = b.String()
+	}
+	header, formats, err := tableColumns(columns, keys)
+	if err != nil {
+		return "", err
+	}
+	cellHeader, cells, numeric, err := i.tableCells(rows, columns, m)
+	if err != nil {
+		return "", err
+	}
+	total := -1
+	for c := len(header) - 1; c >= 0 && total < 0; c-- {
+		if moneyColumn(header[c]) {
+			total = c
+		}
+	}
+	if total < 0 {
+		return renderTable(cellHeader, cells, numeric), nil
+	}
+	var amounts []Money
+	for _, r := range recs {
+		if v, ok := r[header[total]]; ok {
+			amounts = append(amounts, v.(Money))
+		}
+	}
+	subtotal, err := sumMoney(amounts)
+	if err != nil {
+		return "", &Error{Spec: "invoice", Err: err, kind: ErrUnsupportedType}
+	}
+	rate, err := exactRate(taxRate)
+	if err != nil {
+		return "", &Error{Spec: "invoice", Err: fmt.Errorf("tax rate: %w", err), kind: ErrUnsupportedType}
+	}
+	type line struct {
+		label  string
+		amount Money
+	}
+	lines := []line{{"Total", subtotal}}
+	if rate.Sign() != 0 {
+		tax, err := applyRate(subtotal, rate)
+		if err != nil {
+			return "", &Error{Spec: "invoice", Err: err, kind: ErrUnsupportedType}
+		}
+		amount, ok := addAmounts(subtotal.Amount, tax.Amount)
+		if !ok {
+			return "", &Error{Spec: "invoice", Err: errAmountOverflow, kind: ErrUnsupportedType}
+		}
+		lines = []line{
+			{"Subtotal", subtotal},
+			{"Tax", tax},
+			{"Total", Money{Amount: amount, Currency: subtotal.Currency, Scale: subtotal.Scale}},
+		}
+	}
+	footer := make([][]string, len(lines))
+	for n, l := range lines {
+		row := make([]string, len(header))
+		for c := range row {
+			if c != total {
+				row[c] = l.label
+				break
+			}
+		}
+		if row[total], err = i.render(formats[total], map[string]interface{}{header[total]: l.amount}, m); err != nil {
+			return "", err
+		}
+		footer[n] = row
+	}
+	return renderTable(cellHeader, cells, numeric, footer...), nil
+}
+
+// errAmountOverflow reports an invoice amount that does not fit in a Money value.
+var errAmountOverflow = errors.New("amount overflows an int64")
+
+// exactRate returns the tax rate value as an exact fraction. Numbers are taken as their shortest
+// decimal representation.
+func exactRate(value interface{}) (*big.Rat, error) {
+	switch v := value.(type) {
+	case BasisPoints:
+		return big.NewRat(int64(v), 10000), nil
+	case *big.Rat:
+		if v == nil {
+			return nil, errors.New("nil *big.Rat")
+		}
+		return new(big.Rat).Set(v), nil
+	case string:
+		r, ok := new(big.Rat).SetString(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid rate %q", v)
+		}
+		return r, nil
+	}
+	s, err := formatDecimal(value, -1)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid rate %v", value)
+	}
+	return r, nil
+}
+
+// sumMoney adds up amounts of the same currency, at the largest of their scales.
+func sumMoney(amounts []Money) (Money, error) {
+	var sum Money
+	for n, m := range amounts {
+		if n > 0 && m.Currency != sum.Currency {
+			return Money{}, fmt.Errorf("cannot add %s and %s amounts", sum.Currency, m.Currency)
+		}
+		sum.Currency = m.Currency
+		var ok bool
+		for sum.Scale < m.Scale {
+			if sum.Amount, ok = scaleAmount(sum.Amount); !ok {
+				return Money{}, errAmountOverflow
+			}
+			sum.Scale++
+		}
+		for m.Scale < sum.Scale {
+			if m.Amount, ok = scaleAmount(m.Amount); !ok {
+				return Money{}, errAmountOverflow
+			}
+			m.Scale++
+		}
+		if sum.Amount, ok = addAmounts(sum.Amount, m.Amount); !ok {
+			return Money{}, errAmountOverflow
+		}
+	}
+	return sum, nil
+}
+
+// scaleAmount returns a multiplied by 10, reporting false if the product overflows an int64.
+func scaleAmount(a int64) (int64, bool) {
+	if a > math.MaxInt64/10 || a < math.MinInt64/10 {
+		return 0, false
+	}
+	return a * 10, true
+}
+
+// addAmounts returns a+b, reporting false if the sum overflows an int64.
+func addAmounts(a, b int64) (int64, bool) {
+	sum := a + b
+	return sum, (sum > a) == (b > 0)
+}
+
+// applyRate returns m multiplied by rate, at the scale of m, rounded half to even.
+func applyRate(m Money, rate *big.Rat) (Money, error) {
+	r := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	amount, err := strconv.ParseInt(roundHalfEven(r.FloatString(30), 0), 10, 64)
+	if err != nil {
+		return Money{}, errAmountOverflow
+	}
+	return Money{Amount: amount, Currency: m.Currency, Scale: m.Scale}, nil
+}
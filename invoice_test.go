@@ -0,0 +1,130 @@
+package fstr
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestInvoice(t *testing.T) {
+	type line struct {
+		Item   string `fstr:"item"`
+		Qty    int    `fstr:"qty"`
+		Price  Money  `fstr:"price"`
+		Amount Money  `fstr:"amount"`
+	}
+	usd := func(cents int64) Money { return Money{Amount: cents, Currency: "USD", Scale: 2} }
+	lines := []line{
+		{Item: "Widget", Qty: 2, Price: usd(1000), Amount: usd(2000)},
+		{Item: "Gadget", Qty: 1, Price: usd(550), Amount: usd(550)},
+	}
+	tests := []struct {
+		name    string
+		rows    interface{}
+		columns string
+		taxRate interface{}
+		want    string
+	}{
+		{
+			name:    "Tax",
+			rows:    lines,
+			taxRate: 0.19,
+			want: "item      qty   price  amount\n" +
+				"--------  ---  ------  ------\n" +
+				"Widget      2  $10.00  $20.00\n" +
+				"Gadget      1   $5.50   $5.50\n" +
+				"--------  ---  ------  ------\n" +
+				"Subtotal               $25.50\n" +
+				"Tax                     $4.84\n" +
+				"Total                  $30.34",
+		},
+		{
+			name:    "No tax with column specs",
+			rows:    lines,
+			columns: "{item} {amount:money(code, suffix)}",
+			want: "item       amount\n" +
+				"------  ---------\n" +
+				"Widget  20.00 USD\n" +
+				"Gadget   5.50 USD\n" +
+				"------  ---------\n" +
+				"Total   25.50 USD",
+		},
+		{
+			name: "Mixed scales and tax rounded half to even",
+			rows: []map[string]interface{}{
+				{"item": "Hosting", "amount": usd(1050)},
+				{"item": "Usage", "amount": Money{Amount: 125, Currency: "USD", Scale: 3}},
+			},
+			columns: "{item} {amount:money}",
+			taxRate: 0.1,
+			want: "item      amount\n" +
+				"--------  ------\n" +
+				"Hosting   $10.50\n" +
+				"Usage      $0.12\n" +
+				"--------  ------\n" +
+				"Subtotal  $10.62\n" +
+				"Tax        $1.06\n" +
+				"Total     $11.69",
+		},
+		{
+			name: "No amounts",
+			rows: []map[string]interface{}{{"item": "Widget", "qty": 2}},
+			want: "item    qty\n" +
+				"------  ---\n" +
+				"Widget    2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.taxRate == nil {
+				tt.taxRate = 0
+			}
+			got, err := Invoice(tt.rows, tt.columns, tt.taxRate)
+			if err != nil {
+				t.Fatalf("Invoice() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Invoice() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+	got := Eval("{rows:invoice(vat)}", map[string]interface{}{"rows": lines[:1], "vat": 0.2})
+	want := "item      qty   price  amount\n" +
+		"--------  ---  ------  ------\n" +
+		"Widget      2  $10.00  $20.00\n" +
+		"--------  ---  ------  ------\n" +
+		"Subtotal               $20.00\n" +
+		"Tax                     $4.00\n" +
+		"Total                  $24.00"
+	if got != want {
+		t.Errorf("Eval() =\n%s\nwant\n%s", got, want)
+	}
+	// Exact rates give the same tax as the float 0.19, taken as its decimal representation.
+	for _, rate := range []interface{}{"0.19", "19/100", big.NewRat(19, 100), BasisPoints(1900), float32(0.19)} {
+		got, err := Invoice(lines, "{item} {amount:money}", rate)
+		if err != nil || !strings.Contains(got, "Tax        $4.84\nTotal     $30.34") {
+			t.Errorf("Invoice(%v) = %q, %v, want a $4.84 tax", rate, got, err)
+		}
+	}
+	huge := Money{Amount: math.MaxInt64 / 2, Currency: "USD", Scale: 2}
+	failing := []struct {
+		rows    interface{}
+		taxRate interface{}
+	}{
+		{rows: []map[string]interface{}{{"amount": usd(100)}, {"amount": Money{Amount: 100, Currency: "EUR", Scale: 2}}}, taxRate: 0},
+		{rows: []map[string]interface{}{{"amount": huge}, {"amount": huge}, {"amount": huge}}, taxRate: 0},
+		{rows: []map[string]interface{}{{"amount": huge}, {"amount": Money{Amount: 1, Currency: "USD", Scale: 3}}}, taxRate: 0},
+		{rows: []map[string]interface{}{{"amount": huge}}, taxRate: "3"},
+		{rows: []map[string]interface{}{{"amount": huge}}, taxRate: "1.5"},
+		{rows: lines, taxRate: "19%"},
+		{rows: lines, taxRate: (*big.Rat)(nil)},
+		{rows: lines, taxRate: "x"},
+	}
+	for _, tt := range failing {
+		if _, err := Invoice(tt.rows, "", tt.taxRate); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Invoice(%v, %v) error = %v, want ErrUnsupportedType", tt.rows, tt.taxRate, err)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package fstr
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// defaultJSONIndent is the indent width used by {obj|json} when no width is given.
+const defaultJSONIndent = 2
+
+// jsonIndent renders a value as indented JSON, for embedding payloads readably into log and error
+// messages: {obj|json(4)} indents by four spaces and {obj|json} by two. Map keys follow the
+// Interpolator's KeyOrder, sorted by default, and struct fields keep their declaration order, so the
+// output is stable. Characters such as <, > and & are not escaped.
+func (i *Interpolator) jsonIndent(value interface{}, args ...string) (string, error) {
+	width := defaultJSONIndent
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 || n > maxSpecWidth {
+			return "", &Error{Spec: "json", Err: fmt.Errorf("invalid indent %q", args[0]), kind: ErrBadSpec}
+		}
+		width = n
+	}
+	return marshalJSON(jsonValue(reflect.ValueOf(value), i.keyOrder), "json", strings.Repeat(" ", width))
+}
+
+// jsonCompact renders a value as compact, single-line JSON: {obj|jsonc}.
+func (i *Interpolator) jsonCompact(value interface{}) (string, error) {
+	return marshalJSON(jsonValue(reflect.ValueOf(value), i.keyOrder), "jsonc", "")
+}
+
+// marshalJSON encodes value, indenting each level by indent if it is non-empty.
+func marshalJSON(value interface{}, name, indent string) (string, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
+	if err := enc.Encode(value); err != nil {
+		return "", &Error{Spec: name, Err: err, kind: ErrUnsupportedType}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// jsonObject is a JSON object whose members are encoded in the order of keys.
+type jsonObject struct {
+	keys   []string
+	values []interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	b.WriteByte('{')
+	for n, key := range o.keys {
+		if n > 0 {
+			b.WriteByte(',')
+		}
+		if err := enc.Encode(key); err != nil {
+			return nil, err
+		}
+		b.WriteByte(':')
+		if err := enc.Encode(o.values[n]); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// jsonValue prepares a value for encoding/json, replacing its maps, including those nested in slices
+// and structs, by jsonObjects with their keys arranged by order. Struct fields are encoded as
+// encoding/json does, honouring json tags. Values implementing json.Marshaler or
+// encoding.TextMarshaler are encoded by their own methods.
+func jsonValue(v reflect.Value, order KeyOrder) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonValue(v.Elem(), order)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key, ok := jsonKey(iter.Key())
+			if !ok {
+				return v.Interface() // encoding/json reports the unsupported key type
+			}
+			keys = append(keys, key)
+			values[key] = iter.Value()
+		}
+		order(keys)
+		o := jsonObject{keys: keys, values: make([]interface{}, len(keys))}
+		for n, key := range keys {
+			o.values[n] = jsonValue(values[key], order)
+		}
+		return o
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface()
+		}
+		elems := make([]interface{}, v.Len())
+		for n := range elems {
+			elems[n] = jsonValue(v.Index(n), order)
+		}
+		return elems
+	case reflect.Struct:
+		var o jsonObject
+		jsonStructFields(v, order, &o)
+		return o
+	default:
+		return v.Interface()
+	}
+}
+
+// jsonKey returns the JSON object key of a map key, as encoding/json writes it.
+func jsonKey(k reflect.Value) (string, bool) {
+	if k.Kind() == reflect.String {
+		return k.String(), true
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", true
+		}
+		b, err := tm.MarshalText()
+		return string(b), err == nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), true
+	default:
+		return "", false
+	}
+}
+
+// jsonStructFields appends the fields of a struct to o as encoding/json encodes them: exported
+// fields named by their json tag or their Go name, fields of untagged embedded structs inline, and
+// without the fields tagged "-" or tagged omitempty with an empty value.
+func jsonStructFields(v reflect.Value, order KeyOrder, o *jsonObject) {
+	t := v.Type()
+	for n := 0; n < t.NumField(); n++ {
+		f, fv := t.Field(n), v.Field(n)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				jsonStructFields(fv, order, o)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if hasOption(opts, "omitempty") && isEmptyJSON(fv) {
+			continue
+		}
+		var value interface{}
+		if hasOption(opts, "string") {
+			b, _ := json.Marshal(fv.Interface())
+			value = string(b)
+		} else {
+			value = jsonValue(fv, order)
+		}
+		o.keys = append(o.keys, name)
+		o.values = append(o.values, value)
+	}
+}
+
+// hasOption reports whether the comma-separated options of a struct tag include option.
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyJSON reports whether a value is empty in the sense of the omitempty tag option.
+func isEmptyJSON(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
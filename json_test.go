@@ -0,0 +1,96 @@
+package fstr
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestJSONFilter(t *testing.T) {
+	type user struct {
+		Name  string   `json:"name"`
+		Roles []string `json:"roles"`
+	}
+	data := map[string]interface{}{
+		"obj":  map[string]interface{}{"b": 1, "a": "<x & y>"},
+		"user": user{Name: "Ziad", Roles: []string{"admin"}},
+		"n":    3,
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{obj|jsonc}", want: `{"a":"<x & y>","b":1}`},
+		{format: "{obj|json}", want: "{\n  \"a\": \"<x & y>\",\n  \"b\": 1\n}"},
+		{format: "{user|json(4)}", want: "{\n    \"name\": \"Ziad\",\n    \"roles\": [\n        \"admin\"\n    ]\n}"},
+		{format: "{user|jsonc}", want: `{"name":"Ziad","roles":["admin"]}`},
+		{format: "{n|json}", want: "3"},
+		{format: "payload={obj|jsonc}", want: `payload={"a":"<x & y>","b":1}`},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestJSONFilterErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		data   map[string]interface{}
+	}{
+		{format: "{obj|json(x)}", data: map[string]interface{}{"obj": 1}},
+		{format: "{obj|json(-1)}", data: map[string]interface{}{"obj": 1}},
+		{format: "{obj|json(100000000000)}", data: map[string]interface{}{"obj": []int{1}}},
+		{format: "{obj|jsonc}", data: map[string]interface{}{"obj": math.NaN()}},
+		{format: "{obj|json}", data: map[string]interface{}{"obj": make(chan int)}},
+	}
+	for _, tt := range tests {
+		_, err := Interpolate(tt.format, tt.data)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("Interpolate(%q) error = %v, want *Error", tt.format, err)
+		}
+	}
+}
+
+func TestJSONFilterKeyOrder(t *testing.T) {
+	type meta struct {
+		ID     int               `json:"id"`
+		Labels map[string]string `json:"labels,omitempty"`
+		Note   string            `json:"note,omitempty"`
+		Skip   bool              `json:"-"`
+	}
+	type event struct {
+		meta
+		Name string
+	}
+	interp := New(WithKeyOrder(FixedOrder("z", "y")))
+	data := map[string]interface{}{
+		"obj": map[string]interface{}{"a": 1, "y": []interface{}{map[string]int{"b": 2, "z": 3}}, "z": true},
+		"ev":  event{meta: meta{ID: 7, Labels: map[string]string{"env": "prod", "z": "last"}, Skip: true}, Name: "deploy"},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{obj|jsonc}", want: `{"z":true,"y":[{"z":3,"b":2}],"a":1}`},
+		{format: "{ev|jsonc}", want: `{"id":7,"labels":{"z":"last","env":"prod"},"Name":"deploy"}`},
+		{format: "{obj|json(1)}", want: "{\n \"z\": true,\n \"y\": [\n  {\n   \"z\": 3,\n   \"b\": 2\n  }\n ],\n \"a\": 1\n}"},
+	}
+	for _, tt := range tests {
+		got, err := interp.Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
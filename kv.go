@@ -0,0 +1,71 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// kvAll renders every key/value pair in data as space-separated key=value pairs, e.g. "age=23 name=Ziad".
+// Keys are emitted in the given order. Values containing spaces, quotes or '=' are quoted.
+func kvAll(data map[string]interface{}, order KeyOrder) string {
+	keys := orderedKeys(data, order)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + kvValue(data[k])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// kvValue renders a single value for key=value output, quoting it when it would otherwise be ambiguous.
+func kvValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// kv renders a map with string keys as key/value pairs, the way labels and tags usually appear in
+// logs and CLIs: {labels:kv} renders "env=prod,region=eu". The separators between a key and its value
+// and between pairs default to "=" and ","; {labels|kv(": ", "; ")} renders "env: prod; region: eu".
+// Keys are emitted in the given order, and values are quoted by the same rule as in {*}:
+// {x:kv} renders "a=\"has space\"".
+func kv(value interface{}, order KeyOrder, seps ...string) (string, error) {
+	m, ok := stringMap(value)
+	if !ok {
+		return "", &Error{Spec: "kv", Err: fmt.Errorf("cannot format %T as key/value pairs", value), kind: ErrUnsupportedType}
+	}
+	sep, pairSep := "=", ","
+	if len(seps) > 0 {
+		sep = seps[0]
+	}
+	if len(seps) > 1 {
+		pairSep = seps[1]
+	}
+	keys := orderedKeys(m, order)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + sep + kvValue(m[k])
+	}
+	return strings.Join(pairs, pairSep), nil
+}
+
+// stringMap converts any map with string keys, such as map[string]string, to a map[string]interface{}.
+// It reports false if value is not such a map.
+func stringMap(value interface{}) (map[string]interface{}, bool) {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m, true
+	}
+	v := indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	m := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m, true
+}
@@ -0,0 +1,47 @@
+package fstr
+
+import "testing"
+
+func TestKV(t *testing.T) {
+	labels := map[string]string{"region": "eu", "env": "prod", "tier": "web"}
+	tests := []struct {
+		name   string
+		interp *Interpolator
+		format string
+		want   string
+	}{
+		{name: "Spec", format: "{labels:kv}", want: "env=prod,region=eu,tier=web"},
+		{name: "Filter with separators", format: `{labels|kv(": ", "; ")}`, want: "env: prod; region: eu; tier: web"},
+		{name: "Filter with key separator", format: `{labels|kv(":")}`, want: "env:prod,region:eu,tier:web"},
+		{name: "Spec with separators", format: `{labels:kv("=", " ")}`, want: "env=prod region=eu tier=web"},
+		{name: "Key order", interp: New(WithKeyOrder(FixedOrder("tier"))), format: "{labels:kv}", want: "tier=web,env=prod,region=eu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp := tt.interp
+			if interp == nil {
+				interp = New()
+			}
+			got, err := interp.Interpolate(tt.format, map[string]interface{}{"labels": labels})
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	// Values are quoted as in {*}, so that log lines stay parseable.
+	data := map[string]interface{}{"x": map[string]string{"a": "has space", "b": "", "c": "k=v"}}
+	got, err := Interpolate("{x:kv} {x|kv(\"=\", \" \")}", data)
+	if want := `a="has space",b="",c="k=v" a="has space" b="" c="k=v"`; err != nil || got != want {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, want)
+	}
+	got, err = Interpolate("{*}", map[string]interface{}{"a": "has space", "b": "", "c": "k=v"})
+	if want := `a="has space" b="" c="k=v"`; err != nil || got != want {
+		t.Errorf("Interpolate({*}) = %q, %v, want %q", got, err, want)
+	}
+	if _, err := Interpolate("{labels:kv}", map[string]interface{}{"labels": []string{"a"}}); err == nil {
+		t.Errorf("Interpolate() error = nil, want error")
+	}
+}
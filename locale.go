@@ -0,0 +1,168 @@
+package fstr
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// numberSymbols are the decimal and grouping separators of a locale.
+type numberSymbols struct {
+	decimal rune
+	group   rune
+}
+
+// locales lists the number separators of the supported locales, by lower-case language tag.
+// Regional tags fall back to their language.
+var locales = map[string]*numberSymbols{
+	"en":    {'.', ','},
+	"ja":    {'.', ','},
+	"ko":    {'.', ','},
+	"zh":    {'.', ','},
+	"hi":    {'.', ','},
+	"he":    {'.', ','},
+	"de":    {',', '.'},
+	"de-ch": {'.', '’'},
+	"es":    {',', '.'},
+	"it":    {',', '.'},
+	"nl":    {',', '.'},
+	"pt":    {',', '.'},
+	"tr":    {',', '.'},
+	"id":    {',', '.'},
+	"da":    {',', '.'},
+	"fr":    {',', '\u202f'},
+	"ru":    {',', '\u00a0'},
+	"uk":    {',', '\u00a0'},
+	"pl":    {',', '\u00a0'},
+	"cs":    {',', '\u00a0'},
+	"sv":    {',', '\u00a0'},
+	"nb":    {',', '\u00a0'},
+	"fi":    {',', '\u00a0'},
+}
+
+// lookupLocale returns the language tag of the supported locale matching tag, such as "de" for
+// "de-AT", or "" if there is none.
+func lookupLocale(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	for tag != "" {
+		if _, ok := locales[tag]; ok {
+			return tag
+		}
+		n := strings.LastIndexByte(tag, '-')
+		if n < 0 {
+			break
+		}
+		tag = tag[:n]
+	}
+	return ""
+}
+
+// WithLocale makes numeric specifiers use the decimal and grouping separators of the locale given
+// by a language tag such as "de" or "fr-CA": {n:,.2f} renders 1234.5 as "1.234,50" for "de". Tags
+// are matched by language when their region is not supported; unsupported languages keep the default
+// separators, those of "en". See InterpolateContext for a locale per request.
+func WithLocale(tag string) Option {
+	return func(i *Interpolator) {
+		i.locale = localeSymbols(tag)
+	}
+}
+
+// localeSymbols returns the separators of the locale given by a language tag, or nil for the default
+// separators.
+func localeSymbols(tag string) *numberSymbols {
+	if s, ok := locales[lookupLocale(tag)]; ok && *s != *locales["en"] {
+		return s
+	}
+	return nil
+}
+
+// localize replaces the separators of a number formatted by a numeric specifier with those of the
+// locale. A nil locale keeps the default separators.
+func (s *numberSymbols) localize(number string) string {
+	if s == nil {
+		return number
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.':
+			return s.decimal
+		case ',':
+			return s.group
+		}
+		return r
+	}, number)
+}
+
+// localeKey is the context key of the locale set with ContextWithLocale.
+type localeKey struct{}
+
+// ContextWithLocale returns a copy of ctx carrying the locale given by a language tag, for
+// InterpolateContext.
+func ContextWithLocale(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, localeKey{}, tag)
+}
+
+// LocaleFromContext returns the locale set with ContextWithLocale, or "" if there is none.
+func LocaleFromContext(ctx context.Context) string {
+	tag, _ := ctx.Value(localeKey{}).(string)
+	return tag
+}
+
+// LocaleFromRequest returns the supported locale the client of r prefers, according to its
+// Accept-Language header, such as "de" for "de-AT,de;q=0.9,en;q=0.8", or "" if it accepts none.
+func LocaleFromRequest(r *http.Request) string {
+	type choice struct {
+		tag string
+		q   float64
+	}
+	var choices []choice
+	for _, field := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(field, ";")
+		c := choice{tag: strings.TrimSpace(tag), q: 1}
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			c.q = q
+		}
+		if c.tag != "" && c.tag != "*" && c.q > 0 {
+			choices = append(choices, c)
+		}
+	}
+	sort.SliceStable(choices, func(a, b int) bool { return choices[a].q > choices[b].q })
+	for _, c := range choices {
+		if tag := lookupLocale(c.tag); tag != "" {
+			return tag
+		}
+	}
+	return ""
+}
+
+// LocaleHandler sets the locale of every request to next from its Accept-Language header, with
+// ContextWithLocale, so that handlers rendering with InterpolateContext need no plumbing of their own.
+func LocaleHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tag := LocaleFromRequest(r); tag != "" {
+			r = r.WithContext(ContextWithLocale(r.Context(), tag))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InterpolateContext is like Interpolate but renders numbers in the locale carried by ctx, if any,
+// rather than the one set WithLocale; see ContextWithLocale and LocaleHandler:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		msg, err := interp.InterpolateContext(r.Context(), "Total: {total:,.2f} EUR", data)
+//		// Accept-Language: de => "Total: 1.234,50 EUR"
+//	}
+func (i *Interpolator) InterpolateContext(ctx context.Context, format string, data map[string]interface{}) (string, error) {
+	m := i.mode(i.stringColor())
+	if tag := lookupLocale(LocaleFromContext(ctx)); tag != "" {
+		m.locale = localeSymbols(tag)
+	}
+	return i.interpolate(format, data, m)
+}
@@ -0,0 +1,116 @@
+package fstr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithLocale(t *testing.T) {
+	data := map[string]interface{}{"n": 1234567.891, "i": 42}
+	tests := []struct {
+		tag    string
+		format string
+		want   string
+	}{
+		{tag: "en", format: "{n:,.2f}", want: "1,234,567.89"},
+		{tag: "de", format: "{n:,.2f}", want: "1.234.567,89"},
+		{tag: "de-AT", format: "{n:,.2f}", want: "1.234.567,89"},
+		{tag: "de-CH", format: "{n:,.2f}", want: "1’234’567.89"},
+		{tag: "fr_FR", format: "{n:,.2f}", want: "1\u202f234\u202f567,89"},
+		{tag: "ru", format: "[{n:>14,.1f}]", want: "[   1\u00a0234\u00a0567,9]"},
+		{tag: "de", format: "{i:05} {n:.0f} {i}", want: "00042 1234568 42"},
+		{tag: "xx", format: "{n:,.2f}", want: "1,234,567.89"},
+	}
+	for _, tt := range tests {
+		got, err := New(WithLocale(tt.tag)).Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) with %q error = %v", tt.format, tt.tag, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) with %q = %q, want %q", tt.format, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleFromRequest(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "de-AT,de;q=0.9,en;q=0.8", want: "de"},
+		{header: "en-US,en;q=0.9", want: "en"},
+		{header: "xx, fr;q=0.5, de;q=0.7", want: "de"},
+		{header: "de-CH", want: "de-ch"},
+		{header: "de;q=0, fr", want: "fr"},
+		{header: "*", want: ""},
+		{header: "", want: ""},
+		{header: "fr;q=abc, es", want: "es"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", tt.header)
+		if got := LocaleFromRequest(r); got != tt.want {
+			t.Errorf("LocaleFromRequest(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestInterpolateContext(t *testing.T) {
+	interp := New(WithPartials(map[string]string{"total": "{total:,.2f} EUR"}))
+	data := map[string]interface{}{"total": 1234.5}
+
+	got, err := interp.InterpolateContext(context.Background(), "Total: {>total}", data)
+	if want := "Total: 1,234.50 EUR"; err != nil || got != want {
+		t.Errorf("InterpolateContext() = %q, %v, want %q", got, err, want)
+	}
+	if err := interp.Define("total", "{total:,.1f} €"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := ContextWithLocale(context.Background(), "de-DE")
+	got, err = interp.InterpolateContext(ctx, "Total: {>total}", data)
+	if want := "Total: 1.234,5 €"; err != nil || got != want {
+		t.Errorf("InterpolateContext() = %q, %v, want %q", got, err, want)
+	}
+
+	handler := LocaleHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := interp.InterpolateContext(r.Context(), "{total:,.2f}", data)
+		if err != nil {
+			t.Errorf("InterpolateContext() error = %v", err)
+		}
+		w.Write([]byte(LocaleFromContext(r.Context()) + " " + s))
+	}))
+	for header, want := range map[string]string{"de;q=0.9, en-GB": "en 1,234.50", "pt-BR": "pt 1.234,50", "": " 1,234.50"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		if got := rec.Body.String(); got != want {
+			t.Errorf("response for %q = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestInterpolateContextShared(t *testing.T) {
+	m := &countingMetrics{}
+	var reported int
+	interp := New(WithMetrics(m), WithErrorHook(func(*Error) { reported++ }), WithLocale("fr"))
+	ctx := ContextWithLocale(context.Background(), "de")
+	for n := 0; n < 2; n++ {
+		if got, err := interp.InterpolateContext(ctx, "{n:,.2f}", map[string]interface{}{"n": 1234.5}); err != nil || got != "1.234,50" {
+			t.Errorf("InterpolateContext() = %q, %v, want %q", got, err, "1.234,50")
+		}
+	}
+	if got := interp.Eval("{n:,.2f}", map[string]interface{}{"n": 1234.5}); got != "1\u202f234,50" {
+		t.Errorf("Eval() = %q, want the locale set WithLocale", got)
+	}
+	if _, err := interp.InterpolateContext(ctx, "{n:roman}", map[string]interface{}{"n": 0}); err == nil {
+		t.Error("InterpolateContext() error = nil, want error")
+	}
+	// Renders in any locale share the Interpolator's cache, metrics and hooks.
+	if m.renders != 4 || m.cacheHits != 1 || m.cacheMiss != 3 || reported != 1 {
+		t.Errorf("renders, cache hits, misses, reported = %d, %d, %d, %d, want 4, 1, 3, 1", m.renders, m.cacheHits, m.cacheMiss, reported)
+	}
+}
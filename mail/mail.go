@@ -0,0 +1,109 @@
+// Package mail renders email bodies from fstr templates: a plain-text template and an HTML template
+// sharing one data map, combined into a multipart/alternative body ready to send.
+//
+//	var welcome = mail.MustCompile(
+//		"Hi {name},\n\nYour order {id} has shipped.",
+//		"<p>Hi {name},</p><p>Your order <b>{id}</b> has shipped.</p>",
+//	)
+//
+//	body, err := welcome.Render(map[string]interface{}{"name": "Ziad", "id": "A-42"})
+//	// send body.Raw with the header Content-Type: body.ContentType
+//
+// The values of placeholders are HTML-escaped in the HTML part and left as they are in the text part.
+package mail
+
+import (
+	"bytes"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+
+	"github.com/ZiadMansourM/fstr"
+)
+
+// Template is a compiled pair of plain-text and HTML email templates. A Template is safe for
+// concurrent use by multiple goroutines.
+type Template struct {
+	text *fstr.Template
+	html *fstr.Template
+}
+
+// Body is a rendered email body.
+type Body struct {
+	// Text and HTML are the rendered parts.
+	Text string
+	HTML string
+	// ContentType is the value of the message's Content-Type header for Raw, with its boundary.
+	ContentType string
+	// Raw is the multipart/alternative body, holding the text part, then the HTML part, each encoded
+	// as quoted-printable UTF-8 with CRLF line breaks.
+	Raw []byte
+}
+
+// Compile parses the plain-text and HTML templates of an email, to interpolate with the given options.
+// It returns an *fstr.Error if either cannot be parsed.
+func Compile(text, html string, opts ...fstr.Option) (*Template, error) {
+	opts = append([]fstr.Option{fstr.WithColor(fstr.ColorNever)}, opts...)
+	t, err := fstr.New(append(opts, fstr.WithEscape(fstr.EscapeNone))...).Compile(text)
+	if err != nil {
+		return nil, err
+	}
+	h, err := fstr.New(append(opts, fstr.WithEscape(fstr.EscapeHTML))...).Compile(html)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{text: t, html: h}, nil
+}
+
+// MustCompile is like Compile but panics if either template cannot be parsed.
+func MustCompile(text, html string, opts ...fstr.Option) *Template {
+	t, err := Compile(text, html, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render renders both templates with data and combines them into a multipart/alternative body.
+// It returns the *fstr.Error of the first template that fails to render.
+func (t *Template) Render(data map[string]interface{}) (*Body, error) {
+	text, err := t.text.Execute(data)
+	if err != nil {
+		return nil, err
+	}
+	html, err := t.html.Execute(data)
+	if err != nil {
+		return nil, err
+	}
+	var raw bytes.Buffer
+	w := multipart.NewWriter(&raw)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &Body{
+		Text:        text,
+		HTML:        html,
+		ContentType: mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": w.Boundary()}),
+		Raw:         raw.Bytes(),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package mail
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"strings"
+	"testing"
+
+	"github.com/ZiadMansourM/fstr"
+)
+
+func TestRender(t *testing.T) {
+	tmpl := MustCompile(
+		"Hi {name},\n\nYour order {id} of {total:,.2f} EUR has shipped.",
+		"<p>Hi {name},</p><p>Your order <b>{id}</b> of {total:,.2f} EUR has shipped.</p>",
+		fstr.WithStrictKeys(),
+	)
+	body, err := tmpl.Render(map[string]interface{}{"name": "Zoë <Admin>", "id": "A&B-42", "total": 1234.5})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	wantText := "Hi Zoë <Admin>,\n\nYour order A&B-42 of 1,234.50 EUR has shipped."
+	wantHTML := "<p>Hi Zoë &lt;Admin&gt;,</p><p>Your order <b>A&amp;B-42</b> of 1,234.50 EUR has shipped.</p>"
+	if body.Text != wantText {
+		t.Errorf("Render() Text = %q, want %q", body.Text, wantText)
+	}
+	if body.HTML != wantHTML {
+		t.Errorf("Render() HTML = %q, want %q", body.HTML, wantHTML)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(body.ContentType)
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Render() ContentType = %q, %v", body.ContentType, err)
+	}
+	r := multipart.NewReader(strings.NewReader(string(body.Raw)), params["boundary"])
+	for _, want := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", wantText},
+		{"text/html; charset=utf-8", wantHTML},
+	} {
+		part, err := r.NextRawPart()
+		if err != nil {
+			t.Fatalf("NextRawPart() error = %v", err)
+		}
+		if got := part.Header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("part Content-Type = %q, want %q", got, want.contentType)
+		}
+		if got := part.Header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+			t.Errorf("part Content-Transfer-Encoding = %q", got)
+		}
+		content, err := io.ReadAll(quotedprintable.NewReader(part))
+		// Line breaks are sent as CRLF.
+		if err != nil || string(content) != strings.ReplaceAll(want.content, "\n", "\r\n") {
+			t.Errorf("part content = %q, %v, want %q", content, err, want.content)
+		}
+	}
+	if _, err := r.NextRawPart(); err != io.EOF {
+		t.Errorf("NextRawPart() error = %v, want io.EOF", err)
+	}
+
+	if _, err := tmpl.Render(map[string]interface{}{"name": "Zoë"}); !errors.Is(err, fstr.ErrMissingKey) {
+		t.Errorf("Render() error = %v, want ErrMissingKey", err)
+	}
+	if _, err := Compile("{name}", "{#each items}"); !errors.Is(err, fstr.ErrParse) {
+		t.Errorf("Compile() error = %v, want ErrParse", err)
+	}
+}
@@ -0,0 +1,107 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// MarkdownTable renders rows as a GitHub-flavored Markdown table. See Interpolator.MarkdownTable.
+func MarkdownTable(rows interface{}, columns string) (string, error) {
+	return defaultInterpolator.MarkdownTable(rows, columns)
+}
+
+// MarkdownTable renders rows, a slice of structs or of maps with string keys, as a GitHub-flavored
+// Markdown table, for output that ends up in pull request comments or chat messages:
+//
+//	| name | balance |
+//	| --- | ---: |
+//	| Alice | 1,234.50 |
+//
+// columns selects and formats the columns as in Table, and numeric columns are likewise right-aligned.
+// Pipes in cells are escaped and line breaks become <br>. The {rows:mdtable} spec renders a table
+// with all columns.
+func (i *Interpolator) MarkdownTable(rows interface{}, columns string) (string, error) {
+	return i.markdownTable(rows, columns, i.mode(i.stringColor()))
+}
+
+// markdownTable implements MarkdownTable, rendering cells in mode m.
+func (i *Interpolator) markdownTable(rows interface{}, columns string, m renderMode) (string, error) {
+	header, cells, numeric, err := i.tableCells(rows, columns, m)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	writeRow := func(row []string) {
+		b.WriteByte('|')
+		for _, cell := range row {
+			b.WriteString(" " + markdownCell(cell) + " |")
+		}
+		b.WriteByte('\n')
+	}
+	writeRow(header)
+	rule := make([]string, len(header))
+	for c := range header {
+		rule[c] = "---"
+		if numeric[c] {
+			rule[c] = "---:"
+		}
+	}
+	b.WriteByte('|')
+	for _, r := range rule {
+		b.WriteString(" " + r + " |")
+	}
+	b.WriteByte('\n')
+	for _, row := range cells {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// mdtable implements the {rows:mdtable} spec.
+func (i *Interpolator) mdtable(rows interface{}, m renderMode) (string, error) {
+	return i.markdownTable(rows, "", m)
+}
+
+// markdownCell escapes text for use in a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// mdlist renders a slice as a Markdown bullet list: {items:mdlist} renders []string{"a", "b"} as
+// "- a\n- b". Nested slices become nested lists, and the continuation lines of multi-line items are
+// indented to stay within their item.
+func mdlist(items interface{}) (string, error) {
+	v := indirect(reflect.ValueOf(items))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", &Error{Spec: "mdlist", Err: fmt.Errorf("cannot format %T as a list", items), kind: ErrUnsupportedType}
+	}
+	var lines []string
+	writeList(&lines, v, 0)
+	return strings.Join(lines, "\n"), nil
+}
+
+// writeList appends the items of the list v to lines, indented for the given nesting depth.
+func writeList(lines *[]string, v reflect.Value, depth int) {
+	prefix := strings.Repeat("  ", depth)
+	for n := 0; n < v.Len(); n++ {
+		item := indirect(v.Index(n))
+		if (item.Kind() == reflect.Slice || item.Kind() == reflect.Array) && item.Type().Elem().Kind() != reflect.Uint8 {
+			writeList(lines, item, depth+1)
+			continue
+		}
+		text := "<nil>"
+		if item.IsValid() {
+			text = fmt.Sprint(item.Interface())
+		}
+		for l, line := range strings.Split(text, "\n") {
+			if l == 0 {
+				*lines = append(*lines, prefix+"- "+line)
+			} else {
+				*lines = append(*lines, prefix+"  "+line)
+			}
+		}
+	}
+}
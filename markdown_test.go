@@ -0,0 +1,62 @@
+package fstr
+
+import "testing"
+
+func TestMarkdownTable(t *testing.T) {
+	type account struct {
+		Name    string  `fstr:"name"`
+		Note    string  `fstr:"note"`
+		Balance float64 `fstr:"balance"`
+	}
+	accounts := []account{
+		{Name: "Alice", Note: "a|b", Balance: 1234.5},
+		{Name: "Bob", Note: "line\nbreak", Balance: 12},
+	}
+	got, err := MarkdownTable(accounts, "{name} {balance:,.2f}")
+	if err != nil {
+		t.Fatalf("MarkdownTable() error = %v", err)
+	}
+	want := "| name | balance |\n" +
+		"| --- | ---: |\n" +
+		"| Alice | 1,234.50 |\n" +
+		"| Bob | 12.00 |"
+	if got != want {
+		t.Errorf("MarkdownTable() =\n%s\nwant\n%s", got, want)
+	}
+
+	got, err = Interpolate("{rows:mdtable}", map[string]interface{}{"rows": accounts})
+	if err != nil {
+		t.Fatalf("Interpolate() error = %v", err)
+	}
+	want = "| name | note | balance |\n" +
+		"| --- | --- | ---: |\n" +
+		"| Alice | a\\|b | 1234.5 |\n" +
+		"| Bob | line<br>break | 12 |"
+	if got != want {
+		t.Errorf("Interpolate() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarkdownList(t *testing.T) {
+	tests := []struct {
+		items interface{}
+		want  string
+	}{
+		{items: []string{"a", "b"}, want: "- a\n- b"},
+		{items: []interface{}{1, []string{"x", "y"}, "two\nlines"}, want: "- 1\n  - x\n  - y\n- two\n  lines"},
+		{items: []string{}, want: ""},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{items:mdlist}", map[string]interface{}{"items": tt.items})
+		if err != nil {
+			t.Errorf("Interpolate(%v) error = %v", tt.items, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%v) = %q, want %q", tt.items, got, tt.want)
+		}
+	}
+	if _, err := Interpolate("{items:mdlist}", map[string]interface{}{"items": 3}); err == nil {
+		t.Error("Interpolate() with a non-slice expected an error")
+	}
+}
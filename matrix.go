@@ -0,0 +1,98 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Matrix is a two-dimensional array of numbers, such as gonum's mat.Dense, which {m:matrix} renders
+// without fstr depending on the package that defines it.
+type Matrix interface {
+	// Dims returns the number of rows and columns.
+	Dims() (r, c int)
+	// At returns the element at row i, column j.
+	At(i, j int) float64
+}
+
+// matrix renders a Matrix or a slice of slices of numbers, such as [][]float64, as rows of
+// right-aligned columns, for scientific output and debugging numeric code. The optional spec is a
+// numeric specifier applied to every cell, quoted if it contains a comma: {m:matrix(.2f)} renders
+// [][]float64{{1, -2.5}, {10, 0}} as
+//
+//	 1.00  -2.50
+//	10.00   0.00
+//
+// Every row must have the same length; ragged rows yield an ErrUnsupportedType *Error.
+func (i *Interpolator) matrix(loc *numberSymbols, value interface{}, spec ...string) (string, error) {
+	cells, err := matrixCells(value)
+	if err != nil {
+		return "", err
+	}
+	var widths []int
+	rows := make([][]string, len(cells))
+	for r, row := range cells {
+		rows[r] = make([]string, len(row))
+		for c, cell := range row {
+			s := fmt.Sprint(cell)
+			if len(spec) > 0 {
+				if s, err = i.formatNumber(loc, cell, spec[0]); err != nil {
+					return "", err
+				}
+			}
+			rows[r][c] = s
+			if c == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[c] = max(widths[c], utf8.RuneCountInString(s))
+		}
+	}
+	lines := make([]string, len(rows))
+	for r, row := range rows {
+		line := make([]string, len(row))
+		for c, s := range row {
+			line[c] = strings.Repeat(" ", widths[c]-utf8.RuneCountInString(s)) + s
+		}
+		lines[r] = strings.Join(line, "  ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// matrixCells returns the elements of a Matrix or a slice of slices of numbers, row by row.
+func matrixCells(value interface{}) ([][]interface{}, error) {
+	if m, ok := value.(Matrix); ok {
+		r, c := m.Dims()
+		cells := make([][]interface{}, r)
+		for i := range cells {
+			cells[i] = make([]interface{}, c)
+			for j := range cells[i] {
+				cells[i][j] = m.At(i, j)
+			}
+		}
+		return cells, nil
+	}
+	v := indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, &Error{Spec: "matrix", Err: fmt.Errorf("cannot format %T as a matrix", value), kind: ErrUnsupportedType}
+	}
+	cells := make([][]interface{}, v.Len())
+	for i := range cells {
+		row := indirect(v.Index(i))
+		if row.Kind() != reflect.Slice && row.Kind() != reflect.Array {
+			return nil, &Error{Spec: "matrix", Err: fmt.Errorf("cannot format %T as a matrix", value), kind: ErrUnsupportedType}
+		}
+		if i > 0 && row.Len() != len(cells[0]) {
+			return nil, &Error{Spec: "matrix", Err: fmt.Errorf("row %d has %d elements, row 0 has %d", i, row.Len(), len(cells[0])), kind: ErrUnsupportedType}
+		}
+		cells[i] = make([]interface{}, row.Len())
+		for j := range cells[i] {
+			cell := row.Index(j).Interface()
+			if !isNumber(cell) {
+				return nil, &Error{Spec: "matrix", Err: fmt.Errorf("cannot format %T as a number", cell), kind: ErrUnsupportedType}
+			}
+			cells[i][j] = cell
+		}
+	}
+	return cells, nil
+}
@@ -0,0 +1,52 @@
+package fstr
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// dense is a minimal Matrix, laid out like gonum's mat.Dense.
+type dense struct {
+	rows, cols int
+	data       []float64
+}
+
+func (m dense) Dims() (int, int)    { return m.rows, m.cols }
+func (m dense) At(i, j int) float64 { return m.data[i*m.cols+j] }
+
+func TestMatrix(t *testing.T) {
+	tests := []struct {
+		format string
+		m      interface{}
+		want   string
+	}{
+		{format: "{m:matrix(.2f)}", m: [][]float64{{1, -2.5}, {10, 0}}, want: " 1.00  -2.50\n10.00   0.00"},
+		{format: "{m:matrix}", m: [][]int{{1, 200}, {30, 4}}, want: " 1  200\n30    4"},
+		{format: `{m:matrix(",.0f")}`, m: [2][2]float64{{1234, 5}, {6, 7}}, want: "1,234  5\n    6  7"},
+		{format: "{m:matrix(.1f)}", m: dense{rows: 2, cols: 2, data: []float64{1, 2, 3, math.NaN()}}, want: "1.0  2.0\n3.0  NaN"},
+		{format: "{m:matrix}", m: [][]float64{}, want: ""},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"m": tt.m})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.m, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.m, got, tt.want)
+		}
+	}
+	for _, m := range []interface{}{42, []float64{1, 2}, [][]string{{"a"}}, [][]float64{{1}, {-10, 3}}, [][]float64{{1, 2, 3}, {4}}} {
+		if _, err := Interpolate("{m:matrix}", map[string]interface{}{"m": m}); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Interpolate(%v) error = %v, want ErrUnsupportedType", m, err)
+		}
+	}
+}
+
+func TestMatrixLocale(t *testing.T) {
+	got, err := New(WithLocale("de")).Interpolate("{m:matrix(.1f)}", map[string]interface{}{"m": [][]float64{{1.5, 10}}})
+	if want := "1,5  10,0"; err != nil || got != want {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, want)
+	}
+}
@@ -0,0 +1,39 @@
+package fstr
+
+import "time"
+
+// Metrics observes the work done by an Interpolator, so that heavy users can see where
+// template rendering costs go. Register an implementation with WithMetrics.
+//
+// The interface is deliberately small so that it can be backed by any metrics system.
+// For example, with Prometheus:
+//
+//	type promMetrics struct {
+//		renders  *prometheus.HistogramVec // labelled by "outcome"
+//		parses   *prometheus.CounterVec   // labelled by "cache"
+//	}
+//
+//	func (m promMetrics) ObserveRender(d time.Duration, err error) {
+//		outcome := "ok"
+//		if err != nil {
+//			outcome = "error"
+//		}
+//		m.renders.WithLabelValues(outcome).Observe(d.Seconds())
+//	}
+//
+//	func (m promMetrics) ObserveParse(cacheHit bool) {
+//		m.parses.WithLabelValues(strconv.FormatBool(cacheHit)).Inc()
+//	}
+//
+// Render counts, error counts and render durations follow from ObserveRender;
+// the parse-cache hit rate follows from ObserveParse.
+//
+// Implementations must be safe for concurrent use by multiple goroutines.
+type Metrics interface {
+	// ObserveRender is called after every interpolation with the time it took and
+	// the error it returned, if any.
+	ObserveRender(d time.Duration, err error)
+	// ObserveParse is called whenever a format string is looked up in the parse cache,
+	// reporting whether it was found there.
+	ObserveParse(cacheHit bool)
+}
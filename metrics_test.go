@@ -0,0 +1,78 @@
+package fstr
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingMetrics is a Metrics implementation that counts observations.
+type countingMetrics struct {
+	mu                   sync.Mutex
+	renders, errors      int
+	cacheHits, cacheMiss int
+	renderTime           time.Duration
+}
+
+func (m *countingMetrics) ObserveRender(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.renders++
+	m.renderTime += d
+	if err != nil {
+		m.errors++
+	}
+}
+
+func (m *countingMetrics) ObserveParse(cacheHit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cacheHit {
+		m.cacheHits++
+	} else {
+		m.cacheMiss++
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := &countingMetrics{}
+	interp := New(WithMetrics(m))
+	data := map[string]interface{}{"name": "Ziad"}
+	for n := 0; n < 3; n++ {
+		if _, err := interp.Interpolate("Hello {name}", data); err != nil {
+			t.Fatalf("Interpolate() error = %v", err)
+		}
+	}
+	if _, err := interp.Interpolate("{#each items}unclosed", data); err == nil {
+		t.Fatalf("Interpolate() error = nil, want parse error")
+	}
+	if m.renders != 4 || m.errors != 1 {
+		t.Errorf("renders, errors = %d, %d, want 4, 1", m.renders, m.errors)
+	}
+	if m.cacheHits != 2 || m.cacheMiss != 2 {
+		t.Errorf("cache hits, misses = %d, %d, want 2, 2", m.cacheHits, m.cacheMiss)
+	}
+}
+
+func TestCacheSize(t *testing.T) {
+	m := &countingMetrics{}
+	interp := New(WithMetrics(m), WithCacheSize(2))
+	data := map[string]interface{}{"name": "Ziad"}
+	for _, format := range []string{"a {name}", "b {name}", "a {name}", "c {name}", "a {name}", "b {name}"} {
+		if _, err := interp.Interpolate(format, data); err != nil {
+			t.Fatalf("Interpolate(%q) error = %v", format, err)
+		}
+	}
+	// "b" is evicted by "c", having been used less recently than "a".
+	if m.cacheHits != 2 || m.cacheMiss != 4 {
+		t.Errorf("cache hits, misses = %d, %d, want 2, 4", m.cacheHits, m.cacheMiss)
+	}
+	m = &countingMetrics{}
+	interp = New(WithMetrics(m), WithCacheSize(0))
+	for n := 0; n < 2; n++ {
+		interp.Eval("{name}", data)
+	}
+	if m.cacheHits != 0 || m.cacheMiss != 2 {
+		t.Errorf("uncached hits, misses = %d, %d, want 0, 2", m.cacheHits, m.cacheMiss)
+	}
+}
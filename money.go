@@ -0,0 +1,147 @@
+package fstr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact monetary amount, for templates that must never pass money through float64:
+// Money{Amount: 123456, Currency: "USD", Scale: 2} is $1,234.56. Numeric specs format its amount
+// exactly, as in {price:,.2f}, and the money spec with the rules of its currency: {price:money}
+// renders "$1,234.56". Without a spec, it renders as its String method does, "1234.56 USD".
+type Money struct {
+	// Amount is the amount in units of 10^-Scale, e.g. cents for a Scale of 2.
+	Amount int64
+	// Currency is the ISO 4217 code of the currency, such as "USD".
+	Currency string
+	// Scale is the number of decimal places of Amount.
+	Scale int
+}
+
+// String returns the amount with all its decimal places, followed by the currency code: "1234.56 USD".
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.decimal()
+	}
+	return m.decimal() + " " + m.Currency
+}
+
+// decimal returns the amount in plain decimal notation, with Scale decimal places: "-1234.56".
+func (m Money) decimal() string {
+	s := strconv.FormatInt(m.Amount, 10)
+	if m.Scale <= 0 {
+		if m.Amount == 0 {
+			return s
+		}
+		return s + strings.Repeat("0", -m.Scale)
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= m.Scale {
+		s = strings.Repeat("0", m.Scale-len(s)+1) + s
+	}
+	return sign + s[:len(s)-m.Scale] + "." + s[len(s)-m.Scale:]
+}
+
+// isMoney reports whether value is a Money.
+func isMoney(value interface{}) bool {
+	_, ok := value.(Money)
+	return ok
+}
+
+// currency describes the formatting rules of a currency.
+type currency struct {
+	symbol string
+	digits int // decimal places of the minor unit
+}
+
+// currencies lists the formatting rules of common currencies by ISO 4217 code. Other currencies are
+// rendered with their code as symbol and the Money's own Scale.
+var currencies = map[string]currency{
+	"AUD": {symbol: "A$", digits: 2},
+	"BHD": {symbol: "BD", digits: 3},
+	"BRL": {symbol: "R$", digits: 2},
+	"CAD": {symbol: "CA$", digits: 2},
+	"CHF": {symbol: "CHF", digits: 2},
+	"CNY": {symbol: "CN¥", digits: 2},
+	"CZK": {symbol: "Kč", digits: 2},
+	"DKK": {symbol: "kr.", digits: 2},
+	"EGP": {symbol: "E£", digits: 2},
+	"EUR": {symbol: "€", digits: 2},
+	"GBP": {symbol: "£", digits: 2},
+	"HKD": {symbol: "HK$", digits: 2},
+	"INR": {symbol: "₹", digits: 2},
+	"JPY": {symbol: "¥", digits: 0},
+	"KRW": {symbol: "₩", digits: 0},
+	"KWD": {symbol: "KD", digits: 3},
+	"MXN": {symbol: "MX$", digits: 2},
+	"NOK": {symbol: "kr", digits: 2},
+	"NZD": {symbol: "NZ$", digits: 2},
+	"PLN": {symbol: "zł", digits: 2},
+	"SEK": {symbol: "kr", digits: 2},
+	"SGD": {symbol: "S$", digits: 2},
+	"TRY": {symbol: "₺", digits: 2},
+	"USD": {symbol: "$", digits: 2},
+	"ZAR": {symbol: "R", digits: 2},
+}
+
+// money renders a Money with the rules of its currency: {price:money} renders the currency's symbol
+// followed by the amount, grouped and rounded to the decimal places of the currency's minor unit,
+// e.g. "$1,234.56", "-€0.50" or "¥1,235". Separators follow the Interpolator's locale.
+//
+// Options choose the conventions a document demands: "code" writes the ISO code instead of the symbol,
+// as in "USD 1,234.56", and "suffix" places it after the amount: {price:money(code, suffix)} renders
+// "1,234.56 USD", and {price:money(suffix)} renders "1.234,56 €" with a German locale. "symbol" and
+// "prefix" select the defaults explicitly.
+//
+// Amounts with more decimal places than the currency's minor unit are rounded half to even, as
+// accounting ledgers do, so that displayed amounts reconcile with the ledger: 0.125 USD renders as
+// "$0.12". The "halfup" option rounds ties away from zero instead, rendering "$0.13", and "halfeven"
+// selects the default explicitly.
+func (i *Interpolator) money(loc *numberSymbols, value interface{}, options ...string) (string, error) {
+	m, ok := value.(Money)
+	if !ok {
+		return "", &Error{Spec: "money", Err: fmt.Errorf("cannot format %T as money", value), kind: ErrUnsupportedType}
+	}
+	c, ok := currencies[m.Currency]
+	if !ok {
+		c = currency{symbol: m.Currency, digits: max(m.Scale, 0)}
+	}
+	code, suffix, rounding := false, false, "!e"
+	for _, option := range options {
+		switch option {
+		case "symbol":
+			code = false
+		case "code":
+			code = true
+		case "prefix":
+			suffix = false
+		case "suffix":
+			suffix = true
+		case "halfeven":
+			rounding = "!e"
+		case "halfup":
+			rounding = ""
+		default:
+			return "", &Error{Spec: "money", Err: fmt.Errorf("unknown option %q", option), kind: ErrBadSpec}
+		}
+	}
+	s, err := i.formatNumber(loc, m, ",."+strconv.Itoa(c.digits)+"f"+rounding)
+	if err != nil {
+		return "", err
+	}
+	unit := c.symbol
+	if code {
+		unit = m.Currency + " " // a code prefix is separated from the amount: "USD 1,234.56"
+	}
+	if suffix {
+		return s + " " + strings.TrimSpace(unit), nil
+	}
+	if strings.HasPrefix(s, "-") {
+		return "-" + unit + s[1:], nil
+	}
+	return unit + s, nil
+}
@@ -0,0 +1,103 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	tests := []struct {
+		format string
+		price  Money
+		want   string
+	}{
+		{format: "{price}", price: Money{Amount: 123456, Currency: "USD", Scale: 2}, want: "1234.56 USD"},
+		{format: "{price}", price: Money{Amount: -5, Currency: "EUR", Scale: 2}, want: "-0.05 EUR"},
+		{format: "{price:money}", price: Money{Amount: 123456, Currency: "USD", Scale: 2}, want: "$1,234.56"},
+		{format: "{price:money}", price: Money{Amount: -50, Currency: "EUR", Scale: 2}, want: "-€0.50"},
+		{format: "{price:money}", price: Money{Amount: 1234567, Currency: "JPY", Scale: 3}, want: "¥1,235"},
+		{format: "{price:money}", price: Money{Amount: 1500, Currency: "KWD", Scale: 2}, want: "KD15.000"},
+		{format: "{price:money}", price: Money{Amount: 42, Currency: "XTS", Scale: 1}, want: "XTS4.2"},
+		{format: "{price:,.2f}", price: Money{Amount: 900719925474099123, Currency: "USD", Scale: 2}, want: "9,007,199,254,740,991.23"},
+		{format: "{price:.1f}", price: Money{Amount: 125, Scale: 2}, want: "1.3"},
+		{format: "{price:.1f!e}", price: Money{Amount: 125, Scale: 2}, want: "1.2"},
+		{format: "{price:=10.2f}", price: Money{Amount: -350, Scale: 2}, want: "-     3.50"},
+		{format: "{price:,}", price: Money{Amount: 12, Scale: -3}, want: "12,000"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"price": tt.price})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.price, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.price, got, tt.want)
+		}
+	}
+	got, err := New(WithLocale("de")).Interpolate("{price:money}", map[string]interface{}{"price": Money{Amount: 123456, Currency: "EUR", Scale: 2}})
+	if want := "€1.234,56"; err != nil || got != want {
+		t.Errorf("Interpolate(de) = %q, %v, want %q", got, err, want)
+	}
+	if _, err := Interpolate("{price:money}", map[string]interface{}{"price": 12.5}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Interpolate(float) error = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestMoneyOptions(t *testing.T) {
+	usd := Money{Amount: 123456, Currency: "USD", Scale: 2}
+	tests := []struct {
+		format string
+		price  Money
+		want   string
+	}{
+		{format: "{price:money(symbol, prefix)}", price: usd, want: "$1,234.56"},
+		{format: "{price:money(code)}", price: usd, want: "USD 1,234.56"},
+		{format: "{price:money(code, suffix)}", price: usd, want: "1,234.56 USD"},
+		{format: "{price:money(suffix)}", price: usd, want: "1,234.56 $"},
+		{format: "{price:money(code)}", price: Money{Amount: -1200, Currency: "CHF", Scale: 2}, want: "-CHF 12.00"},
+		{format: "{price:money(suffix)}", price: Money{Amount: -50, Currency: "EUR", Scale: 2}, want: "-0.50 €"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"price": tt.price})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.price, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.price, got, tt.want)
+		}
+	}
+	got, err := New(WithLocale("de")).Interpolate("{price:money(suffix)}", map[string]interface{}{"price": Money{Amount: 123456, Currency: "EUR", Scale: 2}})
+	if want := "1.234,56 €"; err != nil || got != want {
+		t.Errorf("Interpolate(de) = %q, %v, want %q", got, err, want)
+	}
+	if _, err := Interpolate("{price:money(left)}", map[string]interface{}{"price": usd}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("Interpolate(money(left)) error = %v, want ErrBadSpec", err)
+	}
+}
+
+func TestMoneyRounding(t *testing.T) {
+	tests := []struct {
+		format string
+		price  Money
+		want   string
+	}{
+		{format: "{price:money}", price: Money{Amount: 125, Currency: "USD", Scale: 3}, want: "$0.12"},
+		{format: "{price:money}", price: Money{Amount: 135, Currency: "USD", Scale: 3}, want: "$0.14"},
+		{format: "{price:money}", price: Money{Amount: -125, Currency: "USD", Scale: 3}, want: "-$0.12"},
+		{format: "{price:money}", price: Money{Amount: 1251, Currency: "USD", Scale: 4}, want: "$0.13"},
+		{format: "{price:money(halfeven)}", price: Money{Amount: 25, Currency: "JPY", Scale: 1}, want: "¥2"},
+		{format: "{price:money(halfup)}", price: Money{Amount: 125, Currency: "USD", Scale: 3}, want: "$0.13"},
+		{format: "{price:money(code, suffix, halfup)}", price: Money{Amount: -25, Currency: "JPY", Scale: 1}, want: "-3 JPY"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"price": tt.price})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.price, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.price, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package fstr
+
+import "sort"
+
+// KeyOrder arranges map keys, in place, into the order in which they are rendered.
+//
+// Go maps are unordered, so every placeholder that renders a whole map (such as {*},
+// {labels:kv} or {old:diff(new)}) passes the map's keys through a KeyOrder to keep its output stable,
+// which matters for golden tests and log diffing. Configure it with WithKeyOrder.
+type KeyOrder func(keys []string)
+
+// SortedKeys orders keys lexically. It is the default KeyOrder.
+func SortedKeys(keys []string) {
+	sort.Strings(keys)
+}
+
+// FixedOrder returns a KeyOrder that emits the given keys first, in the given order,
+// followed by any remaining keys in sorted order. Use it to preserve the order in which
+// keys were inserted into a map, which Go itself does not record.
+func FixedOrder(first ...string) KeyOrder {
+	rank := make(map[string]int, len(first))
+	for i, k := range first {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	return func(keys []string) {
+		sort.Slice(keys, func(a, b int) bool {
+			ra, oka := rank[keys[a]]
+			rb, okb := rank[keys[b]]
+			switch {
+			case oka && okb:
+				return ra < rb
+			case oka != okb:
+				return oka
+			default:
+				return keys[a] < keys[b]
+			}
+		})
+	}
+}
+
+// orderedKeys returns the keys of m arranged by order.
+func orderedKeys[V any](m map[string]V, order KeyOrder) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	order(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package fstr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// partialRe matches partials of the form {>name}, which render the named format string in place, and
+// {>name arg=key arg="text"}, which also bind arguments for it: data map keys or string literals.
+var partialRe = regexp.MustCompile(`{>([a-zA-Z0-9_]+)((?: +[a-zA-Z0-9_]+=(?:[a-zA-Z0-9_]+|"(?:[^"\\{}]|\\.)*"))*) *}`)
+
+// partialArgRe matches one argument of a partial.
+var partialArgRe = regexp.MustCompile(`([a-zA-Z0-9_]+)=([a-zA-Z0-9_]+|"(?:[^"\\{}]|\\.)*")`)
+
+// Define registers the partial name, or replaces it, as WithPartials does. Unlike WithPartials it can be
+// called at any time, so that packages can register reusable formatting macros, invoked with arguments
+// bound to data map keys or string literals:
+//
+//	interp.Define("money", "{v:,.2f} {cur}")
+//	interp.Eval(`Total: {>money v=amount cur=currency}, fee: {>money v=fee cur="EUR"}`, data)
+//
+// It returns an *Error if format cannot be parsed.
+func (i *Interpolator) Define(name, format string) error {
+	if _, _, err := preprocess(format, false); err != nil {
+		return err
+	}
+	i.mu.Lock()
+	if i.partials == nil {
+		i.partials = make(map[string]string)
+	}
+	i.partials[name] = format
+	i.mu.Unlock()
+	// Only the format strings extending the partial were parsed with its previous definition; the
+	// partials a format string includes are parsed when they are rendered.
+	i.cache.removeIf(func(key cacheKey) bool {
+		return i.extends(key.format, name)
+	})
+	return nil
+}
+
+// lookupPartial returns the format string of the partial name.
+func (i *Interpolator) lookupPartial(name string) (string, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	format, ok := i.partials[name]
+	return format, ok
+}
+
+// partialAction converts a partial, matched by partialRe, into a text/template action.
+func partialAction(m string) string {
+	matches := partialRe.FindStringSubmatch(m)
+	// example format: {>signature} => {{partial "signature" .}}
+	// example format: {>money v=amount cur="EUR"} => {{partial "money" . "v" .amount "cur" "EUR"}}
+	action := fmt.Sprintf("{{partial %q .", matches[1])
+	for _, arg := range partialArgRe.FindAllStringSubmatch(matches[2], -1) {
+		value := arg[2]
+		if !strings.HasPrefix(value, `"`) {
+			value = "." + value
+		}
+		action += fmt.Sprintf(" %q %s", arg[1], value)
+	}
+	return action + "}}"
+}
+
+// partial renders the named partial with data, the data map or loop block element of the placeholder
+// including it, extended with args: alternating argument names and values, in mode m.
+func (i *Interpolator) partial(name string, data map[string]interface{}, m renderMode, args ...interface{}) (string, error) {
+	format, ok := i.lookupPartial(name)
+	if !ok {
+		return "", &Error{Err: fmt.Errorf("unknown partial %q", name), kind: ErrParse}
+	}
+	if i.includes(name, name, make(map[string]bool)) {
+		return "", &Error{Err: fmt.Errorf("partial %q includes itself", name), kind: ErrParse}
+	}
+	if len(args) > 0 {
+		scope := make(map[string]interface{}, len(data)+len(args)/2)
+		for k, v := range data {
+			scope[k] = v
+		}
+		for n := 0; n+1 < len(args); n += 2 {
+			scope[fmt.Sprint(args[n])] = args[n+1]
+		}
+		data = scope
+	}
+	return i.render(format, data, m)
+}
+
+// includes reports whether the partial from includes the partial target, directly or through other
+// partials. visited records the partials already searched.
+func (i *Interpolator) includes(from, target string, visited map[string]bool) bool {
+	visited[from] = true
+	format, _ := i.lookupPartial(from)
+	for _, m := range partialRe.FindAllStringSubmatch(trimMarkers(format), -1) {
+		if m[1] == target || !visited[m[1]] && i.includes(m[1], target, visited) {
+			return true
+		}
+	}
+	return false
+}
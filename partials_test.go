@@ -0,0 +1,157 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPartials(t *testing.T) {
+	interp := New(WithStrictKeys(), WithPartials(map[string]string{
+		"signature": "-- {sender}{>company}",
+		"company":   ", {company}",
+		"item":      "{name}: {price:,.2f}",
+		"loop":      "{>loop}",
+		"ping":      "{>pong}",
+		"pong":      "{>ping}",
+		"broken":    "{missing}",
+	}), WithPartials(map[string]string{"company": " ({company})"}))
+	data := map[string]interface{}{
+		"sender":  "Ziad",
+		"company": "Acme",
+		"items":   []map[string]interface{}{{"name": "tea", "price": 2.5}, {"name": "cake", "price": 1200}},
+	}
+	tests := []struct {
+		name   string
+		format string
+		want   string
+		err    error
+	}{
+		{name: "Include", format: "Thanks!\n{>signature}", want: "Thanks!\n-- Ziad (Acme)"},
+		{name: "Loop block scope", format: "{#each items}[{>item}]{/each}", want: "[tea: 2.50][cake: 1,200.00]"},
+		{name: "Unknown partial", format: "{>footer}", err: ErrParse},
+		{name: "Self include", format: "{>loop}", err: ErrParse},
+		{name: "Indirect cycle", format: "{>ping}", err: ErrParse},
+		{name: "Error inside partial", format: "a {>broken}", err: ErrMissingKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interp.Interpolate(tt.format, data)
+			if tt.err != nil {
+				var e *Error
+				if !errors.Is(err, tt.err) || !errors.As(err, &e) || e.Format != tt.format {
+					t.Errorf("Interpolate() error = %#v, want %v for the format", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	_, err := interp.Interpolate("a {>broken}", data)
+	if want := `fstr: {>broken}: missing key "missing"`; err == nil || err.Error() != want {
+		t.Errorf("Interpolate() error = %v, want %s", err, want)
+	}
+	got, errs := interp.InterpolatePartial("{sender} {>broken} {>signature}", data)
+	if got != "Ziad {>broken} -- Ziad (Acme)" || len(errs) != 1 {
+		t.Errorf("InterpolatePartial() = %q, %v", got, errs)
+	}
+	if _, err := Extract("{>signature}", "x"); !errors.Is(err, ErrParse) {
+		t.Errorf("Extract() error = %v, want ErrParse", err)
+	}
+}
+
+func TestDefine(t *testing.T) {
+	interp := New(WithStrictKeys())
+	if err := interp.Define("money", "{v:,.2f} {cur}"); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	data := map[string]interface{}{
+		"amount": 1234.5, "currency": "USD", "fee": 2, "v": "outer",
+		"lines": []map[string]interface{}{{"price": 3, "cur": "GBP"}},
+	}
+	tests := []struct {
+		name   string
+		format string
+		want   string
+		err    error
+	}{
+		{name: "Key arguments", format: "Total: {>money v=amount cur=currency}", want: "Total: 1,234.50 USD"},
+		{name: "Literal argument", format: `{>money v=fee cur="EUR"}`, want: "2.00 EUR"},
+		{name: "Arguments shadow the data", format: "{>money v=fee cur=currency} {v}", want: "2.00 USD outer"},
+		{name: "Loop block element", format: "{#each lines}{>money v=price}{/each}", want: "3.00 GBP"},
+		{name: "Trim markers", format: "a {->money v=fee cur=currency-} b", want: "a2.00 USDb"},
+		{name: "Missing argument key", format: "{>money v=total cur=currency}", err: ErrMissingKey},
+		{name: "Missing key in partial", format: "{>money v=amount}", err: ErrMissingKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := interp.Interpolate(tt.format, data)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Interpolate() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	_, err := interp.Interpolate("{>money v=total cur=currency}", data)
+	if want := `fstr: {>money v=total cur=currency}: missing key "total"`; err == nil || err.Error() != want {
+		t.Errorf("Interpolate() error = %v, want %s", err, want)
+	}
+
+	// Redefining a partial takes effect for format strings parsed before, including children.
+	if err := interp.Define("base", "[{block b}base{/block}]"); err != nil {
+		t.Fatal(err)
+	}
+	if got := interp.Eval("{extends base}", nil); got != "[base]" {
+		t.Errorf("Eval() = %q, want [base]", got)
+	}
+	if err := interp.Define("base", "<{block b}base{/block}>"); err != nil {
+		t.Fatal(err)
+	}
+	if got := interp.Eval("{extends base}", nil); got != "<base>" {
+		t.Errorf("Eval() after Define() = %q, want <base>", got)
+	}
+	if err := interp.Define("bad", "{#each x sortby=y}{/each}"); !errors.Is(err, ErrParse) {
+		t.Errorf("Define() error = %v, want ErrParse", err)
+	}
+}
+
+func TestDefineInvalidation(t *testing.T) {
+	m := &countingMetrics{}
+	interp := New(WithMetrics(m), WithPartials(map[string]string{
+		"base": "[{block b}base{/block}]",
+		"mid":  "{extends base}{block b}mid{/block}",
+		"sig":  "-- {name}",
+	}))
+	data := map[string]interface{}{"name": "Ziad"}
+	formats := []string{"{extends mid}", "Hi {name}", "{>sig}"}
+	for _, format := range formats {
+		interp.Eval(format, data)
+	}
+	if err := interp.Define("base", "<{block b}base{/block}>"); err != nil {
+		t.Fatal(err)
+	}
+	m.cacheHits, m.cacheMiss = 0, 0
+	if got := interp.Eval("{extends mid}", data); got != "<mid>" {
+		t.Errorf("Eval() after Define() = %q, want <mid>", got)
+	}
+	interp.Eval("Hi {name}", data)
+	interp.Eval("{>sig}", data)
+	// Only the format string extending base, through mid, is parsed again.
+	if m.cacheHits != 3 || m.cacheMiss != 1 {
+		t.Errorf("cache hits, misses = %d, %d, want 3, 1", m.cacheHits, m.cacheMiss)
+	}
+}
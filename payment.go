@@ -0,0 +1,173 @@
+package fstr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maskRune replaces the hidden characters of masked values.
+const maskRune = '•'
+
+// mask hides all but the last characters of account numbers, card numbers (PANs) and other payment
+// data: {card|mask(4)} renders "4111111111111234" as "•••• •••• •••• 1234". Letters and digits are
+// masked, keeping the given number of trailing ones (4 by default), and separators such as spaces and
+// dashes are kept as is. A value without separators is split into groups of four characters. Values
+// with no more characters than would be kept are masked entirely, so that short values are never shown.
+func mask(value interface{}, keep ...string) (string, error) {
+	n := 4
+	if len(keep) > 0 {
+		var err error
+		if n, err = strconv.Atoi(keep[0]); err != nil || n < 0 {
+			return "", &Error{Spec: "mask", Err: fmt.Errorf("invalid count %q", keep[0]), kind: ErrBadSpec}
+		}
+	}
+	rs := []rune(strings.TrimSpace(fmt.Sprint(value)))
+	total := 0
+	for _, r := range rs {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			total++
+		}
+	}
+	if total <= n {
+		n = 0
+	}
+	grouped := !strings.ContainsFunc(string(rs), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	seen := 0
+	for _, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			b.WriteRune(r)
+			continue
+		}
+		if grouped && seen > 0 && seen%4 == 0 {
+			b.WriteByte(' ')
+		}
+		if seen < total-n {
+			r = maskRune
+		}
+		b.WriteRune(r)
+		seen++
+	}
+	return b.String(), nil
+}
+
+// strictArg reports whether the arguments of the spec name select strict mode, in which invalid values
+// are errors rather than formatted as they are.
+func strictArg(name string, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	if args[0] != "strict" {
+		return false, &Error{Spec: name, Err: fmt.Errorf("unknown mode %q", args[0]), kind: ErrBadSpec}
+	}
+	return true, nil
+}
+
+// compact removes the spaces and dashes of an account number or code.
+func compact(value interface{}) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return r
+	}, fmt.Sprint(value))
+}
+
+// iban renders an IBAN in the standard groups of four characters: {iban:iban} renders
+// "GB82WEST12345698765432" as "GB82 WEST 1234 5698 7654 32". With {iban:iban(strict)}, an IBAN with
+// an invalid length, characters or checksum is an error.
+func iban(value interface{}, mode ...string) (string, error) {
+	strict, err := strictArg("iban", mode)
+	if err != nil {
+		return "", err
+	}
+	s := strings.ToUpper(compact(value))
+	if strict && !validIBAN(s) {
+		return "", &Error{Spec: "iban", Err: fmt.Errorf("invalid IBAN %q", s), kind: ErrUnsupportedType}
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
+
+// validIBAN reports whether s, an IBAN without spaces, is well-formed and has a valid ISO 7064
+// MOD 97-10 checksum.
+func validIBAN(s string) bool {
+	if len(s) < 15 || len(s) > 34 || !isUpperLetters(s[:2]) || !isDigits(s[2:4]) {
+		return false
+	}
+	rem := 0
+	for _, r := range s[4:] + s[:4] {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return rem == 1
+}
+
+// sortcode renders a UK sort code as three pairs of digits: {code:sortcode} renders "123456" as
+// "12-34-56". With {code:sortcode(strict)}, a sort code that is not six digits is an error.
+func sortcode(value interface{}, mode ...string) (string, error) {
+	strict, err := strictArg("sortcode", mode)
+	if err != nil {
+		return "", err
+	}
+	s := compact(value)
+	if len(s) != 6 || !isDigits(s) {
+		if strict {
+			return "", &Error{Spec: "sortcode", Err: fmt.Errorf("invalid sort code %q", s), kind: ErrUnsupportedType}
+		}
+		return s, nil
+	}
+	return s[:2] + "-" + s[2:4] + "-" + s[4:], nil
+}
+
+// routing renders a US ABA routing number as its nine digits, without separators: {rtn:routing}.
+// With {rtn:routing(strict)}, a routing number that is not nine digits or fails the ABA checksum is
+// an error.
+func routing(value interface{}, mode ...string) (string, error) {
+	strict, err := strictArg("routing", mode)
+	if err != nil {
+		return "", err
+	}
+	s := compact(value)
+	if strict && !validRouting(s) {
+		return "", &Error{Spec: "routing", Err: fmt.Errorf("invalid routing number %q", s), kind: ErrUnsupportedType}
+	}
+	return s, nil
+}
+
+// validRouting reports whether s is nine digits with a valid ABA checksum.
+func validRouting(s string) bool {
+	if len(s) != 9 || !isDigits(s) {
+		return false
+	}
+	weights := [3]int{3, 7, 1}
+	sum := 0
+	for i, r := range s {
+		sum += int(r-'0') * weights[i%3]
+	}
+	return sum%10 == 0
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// isUpperLetters reports whether s is a non-empty string of ASCII capital letters.
+func isUpperLetters(s string) bool {
+	return s != "" && strings.Trim(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == ""
+}
@@ -0,0 +1,80 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		format string
+		value  interface{}
+		want   string
+	}{
+		{format: "{v|mask(4)}", value: "4111111111111234", want: "•••• •••• •••• 1234"},
+		{format: "{v|mask}", value: "4111 1111 1111 1234", want: "•••• •••• •••• 1234"},
+		{format: "{v|mask}", value: "GB82WEST12345698765432", want: "•••• •••• •••• •••• ••54 32"},
+		{format: "{v|mask(2)}", value: "12-34-56", want: "••-••-56"},
+		{format: "{v|mask(0)}", value: "1234", want: "••••"},
+		{format: "{v|mask}", value: "1234", want: "••••"},
+		{format: "{v|mask}", value: 12345678, want: "•••• 5678"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"v": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.value, got, tt.want)
+		}
+	}
+	if _, err := Interpolate("{v|mask(-1)}", map[string]interface{}{"v": "1234"}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("Interpolate(mask(-1)) error = %v, want ErrBadSpec", err)
+	}
+}
+
+func TestBankFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		value  interface{}
+		want   string
+	}{
+		{format: "{v:iban}", value: "GB82WEST12345698765432", want: "GB82 WEST 1234 5698 7654 32"},
+		{format: "{v:iban(strict)}", value: "gb82 west 1234 5698 7654 32", want: "GB82 WEST 1234 5698 7654 32"},
+		{format: "{v:iban(strict)}", value: "DE89370400440532013000", want: "DE89 3704 0044 0532 0130 00"},
+		{format: "{v:iban}", value: "GB00WEST12345698765432", want: "GB00 WEST 1234 5698 7654 32"},
+		{format: "{v:sortcode}", value: "123456", want: "12-34-56"},
+		{format: "{v:sortcode(strict)}", value: "12 34 56", want: "12-34-56"},
+		{format: "{v:sortcode}", value: "1234", want: "1234"},
+		{format: "{v:routing(strict)}", value: "021-000-021", want: "021000021"},
+		{format: "{v:routing}", value: "123456789", want: "123456789"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"v": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.value, got, tt.want)
+		}
+	}
+	errs := []struct {
+		format string
+		value  interface{}
+		kind   error
+	}{
+		{format: "{v:iban(strict)}", value: "GB00WEST12345698765432", kind: ErrUnsupportedType},
+		{format: "{v:iban(strict)}", value: "GB82", kind: ErrUnsupportedType},
+		{format: "{v:iban(strict)}", value: "GB82WEST1234569876543!", kind: ErrUnsupportedType},
+		{format: "{v:sortcode(strict)}", value: "12345", kind: ErrUnsupportedType},
+		{format: "{v:routing(strict)}", value: "123456789", kind: ErrUnsupportedType},
+		{format: "{v:iban(lax)}", value: "GB82WEST12345698765432", kind: ErrBadSpec},
+	}
+	for _, tt := range errs {
+		if _, err := Interpolate(tt.format, map[string]interface{}{"v": tt.value}); !errors.Is(err, tt.kind) {
+			t.Errorf("Interpolate(%q, %v) error = %v, want %v", tt.format, tt.value, err, tt.kind)
+		}
+	}
+}
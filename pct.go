@@ -0,0 +1,91 @@
+package fstr
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// parsePctSpec parses the percentage specifiers `pct` and `pct+`, with optional arguments such as
+// `pct(2, whole)`. It reports whether the spec is the percent-change form `pct+`, and false for ok if
+// spec is not a percentage specifier.
+func parsePctSpec(spec string) (change bool, args []string, ok bool) {
+	if strings.HasPrefix(spec, "pct+") {
+		change, spec = true, "pct"+spec[len("pct+"):]
+	}
+	c, ok := parseCall(spec)
+	if !ok || c.name != "pct" || len(c.args) > 3 {
+		return false, nil, false
+	}
+	return change, c.args, true
+}
+
+// pct renders a percentage. The value is a fraction by default, so {vat:pct(2)} renders 0.19 as
+// "19.00%", while the "whole" option takes it as a whole-number percent, as stored by many invoicing
+// systems: {vat:pct(2, whole)} renders 19 as "19.00%". Choosing the basis in the template prevents
+// the classic ×100 bugs; "fraction" selects the default explicitly. The number of decimals is 1 by
+// default.
+//
+// The percent-change form pct+, for metric and finance summaries, adds a sign and a direction
+// indicator: {delta:pct+} renders 0.125 as "+12.5% ▲" and -0.032 as "-3.2% ▼", in green and red if
+// m.color is set. The "noarrow" option omits the indicator, as in {delta:pct+(2, noarrow)}. No change
+// renders as "0.0%", without a sign or an indicator.
+func (i *Interpolator) pct(value interface{}, change bool, m renderMode, args ...string) (string, error) {
+	decimals, whole, arrow := "1", false, change
+	for _, arg := range args {
+		switch {
+		case arg == "fraction":
+			whole = false
+		case arg == "whole":
+			whole = true
+		case arg == "noarrow" && change:
+			arrow = false
+		case isDigits(arg):
+			decimals = arg
+		default:
+			return "", &Error{Spec: "pct", Err: fmt.Errorf("unknown option %q", arg), kind: ErrBadSpec}
+		}
+	}
+	r, err := toRat(value)
+	if err != nil {
+		return "", &Error{Spec: "pct", Err: err, kind: ErrUnsupportedType}
+	}
+	if !whole {
+		r.Mul(r, big.NewRat(100, 1))
+	}
+	f, _ := r.Float64()
+	s, err := i.formatNumberSpec(f, "."+decimals+"f")
+	if err != nil {
+		return "", err
+	}
+	zero := strings.Trim(s, "0.") == ""
+	s = m.locale.localize(s) + "%"
+	if !change || zero {
+		return s, nil
+	}
+	indicator, name := " ▲", "green"
+	if r.Sign() < 0 {
+		indicator, name = " ▼", "red"
+	} else {
+		s = "+" + s
+	}
+	if arrow {
+		s += indicator
+	}
+	if m.color {
+		return style(s, name)
+	}
+	return s, nil
+}
+
+// pctAction converts a percentage placeholder's expression and spec args into a text/template action.
+func pctAction(expr string, change bool, args []string) string {
+	// example format: {delta:pct+} => {{pct .delta true}}
+	// example format: {vat:pct(2, whole)} => {{pct .vat false "2" "whole"}}
+	action := "{{pct " + expr + " " + strconv.FormatBool(change)
+	for _, arg := range args {
+		action += " " + strconv.Quote(arg)
+	}
+	return action + "}}"
+}
@@ -0,0 +1,89 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPct(t *testing.T) {
+	tests := []struct {
+		format string
+		delta  interface{}
+		want   string
+	}{
+		{format: "{delta:pct+}", delta: 0.125, want: "+12.5% ▲"},
+		{format: "{delta:pct+}", delta: -0.032, want: "-3.2% ▼"},
+		{format: "{delta:pct}", delta: 0.125, want: "12.5%"},
+		{format: "{delta:pct(2)}", delta: -0.03217, want: "-3.22%"},
+		{format: "{delta:pct+(0)}", delta: 2, want: "+200% ▲"},
+		{format: "{delta:pct+}", delta: 0, want: "0.0%"},
+		{format: "{delta:pct+}", delta: -0.0001, want: "0.0%"},
+		{format: "{delta:pct+(noarrow)}", delta: 0.125, want: "+12.5%"},
+		{format: "{delta:pct+(2, noarrow)}", delta: -0.03217, want: "-3.22%"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"delta": tt.delta})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.delta, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.delta, got, tt.want)
+		}
+	}
+	if _, err := Interpolate("{delta:pct}", map[string]interface{}{"delta": "up"}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Interpolate(string) error = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestPctColor(t *testing.T) {
+	setTerminal(t, 0)
+	interp := New(WithColor(ColorAlways))
+	got, err := interp.Interpolate("{up:pct+} {down:pct+}", map[string]interface{}{"up": 0.125, "down": -0.032})
+	if want := "\x1b[32m+12.5% ▲\x1b[0m \x1b[31m-3.2% ▼\x1b[0m"; err != nil || got != want {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, want)
+	}
+	got, err = interp.Interpolate("{vat:pct(2)}", map[string]interface{}{"vat": 0.19})
+	if want := "19.00%"; err != nil || got != want {
+		t.Errorf("Interpolate(pct) = %q, %v, want %q", got, err, want)
+	}
+	got, err = New(WithLocale("de")).Interpolate("{d:pct+}", map[string]interface{}{"d": 0.125})
+	if want := "+12,5% ▲"; err != nil || got != want {
+		t.Errorf("Interpolate(de) = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestPctBasis(t *testing.T) {
+	tests := []struct {
+		format string
+		vat    interface{}
+		want   string
+	}{
+		{format: "{vat:pct(2)}", vat: 0.19, want: "19.00%"},
+		{format: "{vat:pct(2, fraction)}", vat: 0.19, want: "19.00%"},
+		{format: "{vat:pct(2, whole)}", vat: 19, want: "19.00%"},
+		{format: "{vat:pct(whole)}", vat: 7.7, want: "7.7%"},
+		{format: "{vat:pct}", vat: 0.055, want: "5.5%"},
+		{format: "{vat:pct(0)}", vat: -0.2, want: "-20%"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"vat": tt.vat})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.vat, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.vat, got, tt.want)
+		}
+	}
+	got, err := Interpolate("{delta:pct+(whole)} {delta:pct+(0, whole, noarrow)}", map[string]interface{}{"delta": 12.5})
+	if want := "+12.5% ▲ +12%"; err != nil || got != want {
+		t.Errorf("Interpolate(pct+(whole)) = %q, %v, want %q", got, err, want)
+	}
+	if _, err := Interpolate("{vat:pct(noarrow)}", map[string]interface{}{"vat": 0.19}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("Interpolate(pct(noarrow)) error = %v, want ErrBadSpec", err)
+	}
+	if _, err := Interpolate("{vat:pct(percent)}", map[string]interface{}{"vat": 19}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("Interpolate(pct(percent)) error = %v, want ErrBadSpec", err)
+	}
+}
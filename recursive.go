@@ -0,0 +1,168 @@
+package fstr
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// WithRecursion makes values that contain placeholders themselves, such as a "greeting" of
+// "Hello {name}", be interpolated against the same data map before they are rendered, as in config
+// systems layering templates. Values are interpolated up to depth levels deep: with a depth of 1, the
+// {name} of the greeting is filled in, but placeholders within the value of name are rendered as
+// they are. The default depth, 0, disables recursive interpolation.
+//
+// Only the string values of the keys a format string uses directly, or of every key for {*}, are
+// interpolated, including the keys used by partials; the fields of the elements of loop blocks are not.
+// A value referring back to itself, directly or through other values, fails with an ErrParse *Error
+// naming the chain of keys, such as "reference cycle: a -> b -> a".
+//
+// Data values often come from users, so they are interpolated without access to anything beyond the
+// data map: {secret:...} placeholders, the file and exec filters and partials within a value fail
+// with an ErrBadSpec *Error, and text/template actions such as {{call .f}} are rendered as written.
+func WithRecursion(depth int) Option {
+	return func(i *Interpolator) {
+		i.depth = depth
+	}
+}
+
+// valueFuncs replaces the template functions of funcs that reach outside the data map, so that data
+// values interpolated by WithRecursion, which may come from users, cannot read secrets or files, run
+// commands or include partials that do: {secret:...} placeholders, the file and exec filters and
+// partials fail with an ErrBadSpec *Error.
+func valueFuncs(funcs template.FuncMap) {
+	for _, name := range []string{"secret", "file", "exec", "partial"} {
+		name := name
+		funcs[name] = func(...interface{}) (string, error) {
+			return "", &Error{Spec: name, Err: fmt.Errorf("%s is not available in data values", name), kind: ErrBadSpec}
+		}
+	}
+}
+
+// expandValues returns data with the string values of the keys format uses interpolated against data,
+// themselves expanded up to depth-1 levels deep. data itself is not modified.
+func (i *Interpolator) expandValues(format string, data map[string]interface{}, depth int, m renderMode) (map[string]interface{}, error) {
+	if depth <= 0 {
+		return data, nil
+	}
+	x := &expander{i: i, data: data, mode: m, memo: make(map[expansionKey]*expansion)}
+	scope, _, err := x.scope(format, depth, nil)
+	return scope, err
+}
+
+// expander interpolates the values of a data map for expandValues. It memoizes the values it has
+// expanded, so that a key used by several values is expanded once per depth rather than once per use.
+type expander struct {
+	i    *Interpolator
+	data map[string]interface{}
+	mode renderMode
+	memo map[expansionKey]*expansion
+}
+
+// expansionKey identifies a value expanded to a given depth.
+type expansionKey struct {
+	key   string
+	depth int
+}
+
+// expansion is an expanded value.
+type expansion struct {
+	value string
+	// refs are the keys checked for reference cycles while expanding the value.
+	refs map[string]bool
+}
+
+// scope returns data with the string values of the keys format uses expanded up to depth levels deep,
+// and the keys it checked for reference cycles. chain lists the keys whose values are being expanded,
+// outermost first; a key referring back to one of them is a reference cycle.
+func (x *expander) scope(format string, depth int, chain []string) (map[string]interface{}, map[string]bool, error) {
+	var expanded map[string]interface{}
+	refs := make(map[string]bool)
+	for _, key := range x.i.usedKeys(format, x.data) {
+		s, ok := x.data[key].(string)
+		if !ok || !hasPlaceholders(s) {
+			continue
+		}
+		refs[key] = true
+		if n := slices.Index(chain, key); n >= 0 {
+			cycle := append(slices.Clone(chain[n:]), key)
+			return nil, nil, &Error{Format: format, Err: fmt.Errorf("reference cycle: %s", strings.Join(cycle, " -> ")), kind: ErrParse}
+		}
+		if depth <= 0 {
+			continue
+		}
+		e, err := x.expand(key, s, depth, chain)
+		if err != nil {
+			var xe *Error
+			if len(chain) == 0 && errors.As(err, &xe) {
+				xe.Format = format
+			}
+			return nil, nil, err
+		}
+		for k := range e.refs {
+			refs[k] = true
+		}
+		if expanded == nil {
+			expanded = make(map[string]interface{}, len(x.data))
+			for k, v := range x.data {
+				expanded[k] = v
+			}
+		}
+		expanded[key] = e.value
+	}
+	if expanded == nil {
+		return x.data, refs, nil
+	}
+	return expanded, refs, nil
+}
+
+// expand returns s, the value of key, interpolated with the values of its own keys expanded up to
+// depth-1 levels deep. A memoized expansion is reused unless it checked a key of chain, which would
+// now be a reference cycle.
+func (x *expander) expand(key, s string, depth int, chain []string) (*expansion, error) {
+	mk := expansionKey{key: key, depth: depth}
+	if e, ok := x.memo[mk]; ok && !slices.ContainsFunc(chain, func(k string) bool { return e.refs[k] }) {
+		return e, nil
+	}
+	scope, refs, err := x.scope(s, depth-1, append(chain, key))
+	if err != nil {
+		return nil, err
+	}
+	v, err := x.i.renderValue(s, scope, x.mode)
+	if err != nil {
+		return nil, &Error{Format: s, Err: fmt.Errorf("value of %q: %w", key, err)}
+	}
+	e := &expansion{value: v, refs: refs}
+	x.memo[mk] = e
+	return e, nil
+}
+
+// usedKeys returns the keys of data that format and its partials use, in sorted order.
+func (i *Interpolator) usedKeys(format string, data map[string]interface{}) []string {
+	reqs, expandAll := i.requirements(format)
+	var keys []string
+	if expandAll {
+		for k := range data {
+			keys = append(keys, k)
+		}
+	} else {
+		for _, r := range reqs {
+			keys = append(keys, r.Key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasPlaceholders reports whether s contains any placeholder, partial or block tag, or is
+// malformed as a format string.
+func hasPlaceholders(s string) bool {
+	if !strings.Contains(s, "{") {
+		return false
+	}
+	_, sources, err := preprocess(s, false)
+	return err != nil || len(sources) > 0
+}
@@ -0,0 +1,137 @@
+package fstr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRecursion(t *testing.T) {
+	data := map[string]interface{}{
+		"name":     "Ziad",
+		"greeting": "Hello {name}",
+		"banner":   "{greeting}!",
+		"url":      "https://{host}:{port}",
+		"host":     "db.{domain}",
+		"domain":   "internal",
+		"port":     5432,
+		"literal":  "{not a placeholder}",
+	}
+	tests := []struct {
+		name   string
+		depth  int
+		format string
+		want   string
+	}{
+		{name: "Disabled", depth: 0, format: "{greeting}", want: "Hello {name}"},
+		{name: "One level", depth: 1, format: "{greeting}", want: "Hello Ziad"},
+		{name: "Depth reached", depth: 1, format: "{banner}", want: "Hello {name}!"},
+		{name: "Two levels", depth: 2, format: "{banner}", want: "Hello Ziad!"},
+		{name: "Several keys", depth: 3, format: "{url}", want: "https://db.internal:5432"},
+		{name: "Filters", depth: 1, format: "{greeting|snake}", want: "hello_ziad"},
+		{name: "No placeholders", depth: 1, format: "{literal}", want: "{not a placeholder}"},
+		{name: "Partial", depth: 1, format: "{>hello}", want: "Hello Ziad"},
+		{name: "Partial argument", depth: 1, format: "{>quote s=greeting}", want: `"Hello Ziad"`},
+		{name: "Expand all", depth: 1, format: "{*}", want: `banner="Hello {name}!" domain=internal greeting="Hello Ziad" host=db.internal literal="{not a placeholder}" name=Ziad port=5432 url=https://db.{domain}:5432`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interp := New(WithRecursion(tt.depth), WithPartials(map[string]string{"hello": "{greeting}", "quote": `"{s}"`}))
+			got, err := interp.Interpolate(tt.format, data)
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if data["greeting"] != "Hello {name}" {
+		t.Errorf("Interpolate() modified the data map: %v", data)
+	}
+
+	_, err := New(WithRecursion(1), WithStrictKeys()).Interpolate("{msg}", map[string]interface{}{"msg": "Hi {nobody}"})
+	if !errors.Is(err, ErrMissingKey) {
+		t.Errorf("Interpolate() error = %v, want ErrMissingKey", err)
+	}
+	if want := `fstr: value of "msg": fstr: {nobody}: missing key "nobody"`; err == nil || err.Error() != want {
+		t.Errorf("Interpolate() error = %v, want %s", err, want)
+	}
+}
+
+func TestRecursionCycle(t *testing.T) {
+	data := map[string]interface{}{
+		"a":    "x{b}",
+		"b":    "y{c}",
+		"c":    "z{a}",
+		"self": "{self}!",
+		"ok":   "{name}",
+		"name": "Ziad",
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{a}", want: "reference cycle: a -> b -> c -> a"},
+		{format: "{ok} {b}", want: "reference cycle: b -> c -> a -> b"},
+		{format: "{self}", want: "reference cycle: self -> self"},
+	}
+	interp := New(WithRecursion(10))
+	for _, tt := range tests {
+		_, err := interp.Interpolate(tt.format, data)
+		var e *Error
+		if !errors.Is(err, ErrParse) || !errors.As(err, &e) || e.Err.Error() != tt.want || e.Format != tt.format {
+			t.Errorf("Interpolate(%q) error = %#v, want %q", tt.format, err, tt.want)
+		}
+	}
+	// A cycle deeper than the recursion depth is not reached.
+	if got, err := New(WithRecursion(1)).Interpolate("{a}", data); err != nil || got != "xy{c}" {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, "xy{c}")
+	}
+	if got, err := interp.Interpolate("{ok}", data); err != nil || got != "Ziad" {
+		t.Errorf("Interpolate() = %q, %v", got, err)
+	}
+}
+
+func TestRecursionRestricted(t *testing.T) {
+	interp := New(
+		WithRecursion(2),
+		WithExec("hostname"),
+		WithSecretResolver(func(path, key string) (string, error) { return "s3cr3t", nil }),
+		WithPartials(map[string]string{"token": "{secret:app#token}"}),
+	)
+	for _, value := range []string{"{secret:app#token}", "{cmd|exec}", "{path|file}", "{>token}", "x{inner}"} {
+		data := map[string]interface{}{"msg": value, "inner": "{cmd|exec}", "cmd": "hostname", "path": "/etc/hostname"}
+		if got, err := interp.Interpolate("{msg}", data); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("Interpolate(%q) = %q, %v, want ErrBadSpec", value, got, err)
+		}
+	}
+	// Template actions in a value are text, not calls into the data map.
+	data := map[string]interface{}{
+		"name": "Ziad", "token": "s3cr3t", "f": func() string { return "called" },
+		"bio": "{name} {{call .f}} {{printf \"%v\" .token}} {{.token}} {{kvAll .}}",
+	}
+	got, err := interp.Interpolate("{bio}", data)
+	if want := "Ziad {{call .f}} {{printf \"%v\" .token}} {{.token}} {{kvAll .}}"; err != nil || got != want {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, want)
+	}
+	// The format string itself keeps access to them.
+	if got, err := interp.Interpolate("{>token}", nil); err != nil || got != "s3cr3t" {
+		t.Errorf("Interpolate() = %q, %v, want the secret", got, err)
+	}
+}
+
+func TestRecursionShared(t *testing.T) {
+	// Each k value uses a and b, which both use the next k: expanding every use separately would take
+	// 2^30 renders.
+	data := map[string]interface{}{"k30": "x"}
+	for n := 29; n >= 0; n-- {
+		data[fmt.Sprintf("k%d", n)] = fmt.Sprintf("{a%d}{b%d}", n+1, n+1)
+		data[fmt.Sprintf("a%d", n+1)] = fmt.Sprintf("{k%d|truncate(2)}", n+1)
+		data[fmt.Sprintf("b%d", n+1)] = fmt.Sprintf("{k%d|truncate(2)}", n+1)
+	}
+	got, err := New(WithRecursion(60)).Interpolate("{k0}", data)
+	if want := "x…x…"; err != nil || got != want {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, want)
+	}
+}
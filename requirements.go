@@ -0,0 +1,182 @@
+package fstr
+
+import (
+	"slices"
+	"strings"
+)
+
+// Kind describes the kind of value a placeholder expects, as implied by its format specifier.
+type Kind string
+
+const (
+	// KindAny is expected by placeholders without a specifier; any value can be rendered.
+	KindAny Kind = "any"
+	// KindNumber is expected by numeric specifiers such as {key:,} or {key:.2f}.
+	KindNumber Kind = "number"
+	// KindString is expected by text filters such as {key|snake}.
+	KindString Kind = "string"
+)
+
+// Requirement describes a key that a format string expects to find in the data map.
+type Requirement struct {
+	// Key is the data map key.
+	Key string
+	// Specs lists the format specifiers the key is rendered with, in order of appearance, without duplicates.
+	// It is empty if the key is only used in plain placeholders such as {key}.
+	Specs []string
+	// Kind is the kind of value the key's specifiers expect.
+	Kind Kind
+}
+
+// Requirements reports the keys that the format string expects in its data map, without rendering it.
+// This allows callers to ask for exactly the inputs a stored template needs before interpolating it.
+//
+// Requirements are listed in order of first appearance, with one entry per key. The expand-all
+// placeholder {*} does not require any particular key and is not listed.
+// An error is returned if the format string cannot be parsed.
+//
+// Example usage:
+//
+//	reqs, _ := fstr.Requirements("Hello {name}, your balance is {balance:,.2f}")
+//	// reqs: [{name [] any} {balance [,.2f] number}]
+func Requirements(format string) ([]Requirement, error) {
+	if _, err := defaultInterpolator.parse(format, renderMode{}); err != nil {
+		return nil, err
+	}
+	reqs, _ := requirements(format)
+	return reqs, nil
+}
+
+// requirements implements Requirements for a format string that is known to parse.
+// It also reports whether the format string contains the expand-all placeholder {*}.
+func requirements(format string) (reqs []Requirement, expandAll bool) {
+	var s requirementSet
+	s.scan(format, nil)
+	return s.reqs, s.expandAll
+}
+
+// requirements is like the requirements function, but also lists the keys used by the partials that
+// format includes, outside loop blocks, and by the partials it extends, directly or through others.
+// Keys bound as partial arguments are not listed; the data map keys bound to them are.
+func (i *Interpolator) requirements(format string) ([]Requirement, bool) {
+	var s requirementSet
+	i.scanRequirements(&s, format, nil, make(map[string]bool))
+	return s.reqs, s.expandAll
+}
+
+// scanRequirements adds the requirements of format and of its partials to s, skipping the keys bound
+// as partial arguments. visited records the partials already scanned.
+func (i *Interpolator) scanRequirements(s *requirementSet, format string, bound map[string]bool, visited map[string]bool) {
+	chain, err := i.inheritance(trimMarkers(format))
+	if err != nil {
+		chain = []string{format}
+	}
+	for _, f := range chain {
+		s.scan(f, bound)
+		for _, m := range partialRe.FindAllStringSubmatch(stripBlocks(trimMarkers(f)), -1) {
+			scope := make(map[string]bool, len(bound))
+			for k := range bound {
+				scope[k] = true
+			}
+			for _, arg := range partialArgRe.FindAllStringSubmatch(m[2], -1) {
+				if !strings.HasPrefix(arg[2], `"`) && !bound[arg[2]] {
+					s.require(arg[2], "", KindAny)
+				}
+				scope[arg[1]] = true
+			}
+			if partial, ok := i.lookupPartial(m[1]); ok && !visited[m[1]] {
+				visited[m[1]] = true
+				i.scanRequirements(s, partial, scope, visited)
+			}
+		}
+	}
+}
+
+// requirementSet collects requirements in order of first appearance, with one entry per key.
+type requirementSet struct {
+	reqs      []Requirement
+	index     map[string]int
+	expandAll bool
+}
+
+// require records that key is rendered with spec, expecting a value of the given kind.
+func (s *requirementSet) require(key, spec string, kind Kind) {
+	n, ok := s.index[key]
+	if !ok {
+		if s.index == nil {
+			s.index = make(map[string]int)
+		}
+		n = len(s.reqs)
+		s.index[key] = n
+		s.reqs = append(s.reqs, Requirement{Key: key, Kind: KindAny})
+	}
+	r := &s.reqs[n]
+	if spec != "" && !slices.Contains(r.Specs, spec) {
+		r.Specs = append(r.Specs, spec)
+	}
+	if kind != KindAny {
+		r.Kind = kind
+	}
+}
+
+// scan adds the requirements of the placeholders of format, skipping the keys that are bound.
+func (s *requirementSet) scan(format string, bound map[string]bool) {
+	format = trimMarkers(format)
+	// Placeholders inside loop blocks refer to the fields of the elements, not to data map keys.
+	for _, m := range placeholderRe.FindAllString(stripBlocks(format), -1) {
+		p, ok := parsePlaceholder(m)
+		if !ok {
+			continue
+		}
+		if p.key == "*" {
+			s.expandAll = true
+			continue
+		}
+		if p.literal || p.secret || bound[p.key] {
+			continue
+		}
+		kind := KindAny
+		_, _, pct := parsePctSpec(p.spec)
+		if p.spec != "" && specRe.MatchString(p.spec) || pct {
+			kind = KindNumber
+		}
+		c, named := parseNamedSpec(p.spec)
+		if named {
+			kind = namedSpecs[c.name].kind
+		}
+		if len(p.filters) > 0 {
+			// The spec formats the filtered value; the key itself must suit the first filter.
+			def, _ := lookupFilter(p.filters[0].name)
+			s.require(p.key, p.spec, def.kind)
+		} else {
+			s.require(p.key, p.spec, kind)
+		}
+		if named && namedSpecs[c.name].refs {
+			for _, ref := range c.args {
+				if !bound[ref] {
+					s.require(ref, "", kind)
+				}
+			}
+		}
+	}
+}
+
+// unusedKeys returns the keys of data that format and its partials never reference, arranged by the
+// Interpolator's key order.
+func (i *Interpolator) unusedKeys(format string, data map[string]interface{}) []string {
+	reqs, expandAll := i.requirements(format)
+	if expandAll {
+		return nil
+	}
+	used := make(map[string]bool, len(reqs))
+	for _, r := range reqs {
+		used[r.Key] = true
+	}
+	var unused []string
+	for _, k := range orderedKeys(data, i.keyOrder) {
+		if !used[k] {
+			unused = append(unused, k)
+		}
+	}
+	return unused
+}
@@ -0,0 +1,66 @@
+package fstr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequirements(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		want    []Requirement
+		wantErr bool
+	}{
+		{
+			name:   "Plain and numeric placeholders",
+			format: "Hello {name}, your balance is {balance:,.2f} ({balance:,}). {name=}",
+			want: []Requirement{
+				{Key: "name", Kind: KindAny},
+				{Key: "balance", Specs: []string{",.2f", ","}, Kind: KindNumber},
+			},
+		},
+		{
+			name:   "Diff and expand all",
+			format: "{old:diff(new)} {*} {\"-\"|repeat(3)}",
+			want: []Requirement{
+				{Key: "old", Specs: []string{"diff(new)"}, Kind: KindAny},
+				{Key: "new", Kind: KindAny},
+			},
+		},
+		{
+			name:   "Named specs referencing other keys",
+			format: "{w:ratio(h)} {p:frac(8)}",
+			want: []Requirement{
+				{Key: "w", Specs: []string{"ratio(h)"}, Kind: KindNumber},
+				{Key: "h", Kind: KindNumber},
+				{Key: "p", Specs: []string{"frac(8)"}, Kind: KindNumber},
+			},
+		},
+		{
+			name:   "Filters",
+			format: "{name|snake} {title|pascal:diff(old)}",
+			want: []Requirement{
+				{Key: "name", Kind: KindString},
+				{Key: "title", Specs: []string{"diff(old)"}, Kind: KindString},
+				{Key: "old", Kind: KindAny},
+			},
+		},
+		{
+			name:    "Invalid template",
+			format:  "{#each items}unclosed",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Requirements(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Requirements() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Requirements() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package fstr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// romanNumerals lists the Roman numeral symbols, including the subtractive pairs, by descending value.
+var romanNumerals = []struct {
+	value  int64
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// roman renders a whole number between 1 and 3999 as a Roman numeral, e.g. 42 => "XLII".
+func roman(value interface{}) (string, error) {
+	n, err := toInt64(value)
+	if err != nil {
+		return "", &Error{Spec: "roman", Err: err, kind: ErrUnsupportedType}
+	}
+	if n < 1 || n > 3999 {
+		return "", &Error{Spec: "roman", Err: fmt.Errorf("%d is out of range [1, 3999]", n), kind: ErrUnsupportedType}
+	}
+	var b strings.Builder
+	for _, r := range romanNumerals {
+		for ; n >= r.value; n -= r.value {
+			b.WriteString(r.symbol)
+		}
+	}
+	return b.String(), nil
+}
+
+// toInt64 converts a numeric value to an int64, failing if it is not a whole number that fits.
+func toInt64(value interface{}) (int64, error) {
+	r, err := toRat(value)
+	if err != nil {
+		return 0, err
+	}
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, errors.New(r.FloatString(3) + " is not a whole number")
+	}
+	return r.Num().Int64(), nil
+}
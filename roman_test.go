@@ -0,0 +1,34 @@
+package fstr
+
+import "testing"
+
+func TestRoman(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{value: 1, want: "I"},
+		{value: 4, want: "IV"},
+		{value: 9, want: "IX"},
+		{value: 14, want: "XIV"},
+		{value: 42, want: "XLII"},
+		{value: uint8(99), want: "XCIX"},
+		{value: 2024.0, want: "MMXXIV"},
+		{value: 3999, want: "MMMCMXCIX"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{n:roman}", map[string]interface{}{"n": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%v) error = %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+	for _, value := range []interface{}{0, -3, 4000, 2.5, "XII"} {
+		if _, err := Interpolate("{n:roman}", map[string]interface{}{"n": value}); err == nil {
+			t.Errorf("Interpolate(%v) error = nil, want error", value)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package fstr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// secretRefRe matches the reference of a secret placeholder, such as `vault/db/prod#password`: a
+// slash-separated path, optionally followed by '#' and a key within the secret.
+var secretRefRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]+(?:/[a-zA-Z0-9_.-]+)*(?:#[a-zA-Z0-9_.-]+)?$`)
+
+// SecretResolver fetches the secret a {secret:path#key} placeholder refers to, such as the password
+// key of the vault/db/prod secret for {secret:vault/db/prod#password}. key is empty if the reference
+// has no '#'.
+type SecretResolver func(path, key string) (string, error)
+
+// WithSecretResolver registers fn to resolve {secret:path#key} placeholders at render time, so that
+// config templates can inject credentials without the caller fetching every secret up front:
+//
+//	interp := fstr.New(fstr.WithSecretResolver(func(path, key string) (string, error) {
+//		s, err := vault.KVv2("secret").Get(ctx, path)
+//		if err != nil {
+//			return "", err
+//		}
+//		return fmt.Sprint(s.Data[key]), nil
+//	}))
+//	dsn := interp.Eval("postgres://app:{secret:db/prod#password}@{host}/app", data)
+//
+// Resolved secrets are not cached: fn is called every time a placeholder renders. Filters apply to
+// the secret, as in {secret|b64enc:db/prod#password}. Without a resolver, secret placeholders fail
+// with an ErrMissingKey *Error.
+func WithSecretResolver(fn SecretResolver) Option {
+	return func(i *Interpolator) {
+		i.secrets = fn
+	}
+}
+
+// isSecretRef reports whether a placeholder with the given key and spec refers to a secret: its key
+// is "secret" and its spec, which is not otherwise a valid specifier, is a secret reference.
+func isSecretRef(key, spec string) bool {
+	return key == "secret" && secretRefRe.MatchString(spec)
+}
+
+// secret resolves the secret named by ref with the Interpolator's SecretResolver.
+func (i *Interpolator) secret(ref string) (string, error) {
+	if i.secrets == nil {
+		return "", &Error{Err: fmt.Errorf("no secret resolver for %q", ref), kind: ErrMissingKey}
+	}
+	path, key, _ := strings.Cut(ref, "#")
+	s, err := i.secrets(path, key)
+	if err != nil {
+		return "", &Error{Err: fmt.Errorf("secret %q: %w", ref, err)}
+	}
+	return s, nil
+}
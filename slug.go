@@ -0,0 +1,68 @@
+package fstr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// transliterations maps common non-ASCII Latin letters to their closest ASCII spelling.
+var transliterations = func() map[rune]string {
+	m := map[rune]string{
+		'ß': "ss", 'æ': "ae", 'Æ': "ae", 'œ': "oe", 'Œ': "oe", 'ø': "o", 'Ø': "o",
+		'ł': "l", 'Ł': "l", 'đ': "d", 'Đ': "d", 'ð': "d", 'Ð': "d", 'þ': "th", 'Þ': "th",
+		'ı': "i",
+	}
+	for ascii, letters := range map[string]string{
+		"a": "àáâãäåāăąÀÁÂÃÄÅĀĂĄ",
+		"c": "çćĉċčÇĆĈĊČ",
+		"e": "èéêëēĕėęěÈÉÊËĒĔĖĘĚ",
+		"g": "ĝğġģĜĞĠĢ",
+		"i": "ìíîïĩīĭįÌÍÎÏĨĪĬĮİ",
+		"n": "ñńņňÑŃŅŇ",
+		"o": "òóôõöōŏőÒÓÔÕÖŌŎŐ",
+		"r": "ŕŗřŔŖŘ",
+		"s": "śŝşšșŚŜŞŠȘ",
+		"t": "ţťțŢŤȚ",
+		"u": "ùúûüũūŭůűųÙÚÛÜŨŪŬŮŰŲ",
+		"y": "ýÿŷÝŸŶ",
+		"z": "źżžŹŻŽ",
+	} {
+		for _, r := range letters {
+			m[r] = ascii
+		}
+	}
+	return m
+}()
+
+// slug converts a title to a URL-safe slug: lower-case ASCII letters and digits separated by single
+// hyphens, e.g. "Héllo, Wörld & Co.!" => "hello-world-and-co". Accented Latin letters are
+// transliterated; any other character separates words.
+func slug(value interface{}) string {
+	var b strings.Builder
+	pendingHyphen := false
+	write := func(s string) {
+		if pendingHyphen && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		pendingHyphen = false
+		b.WriteString(s)
+	}
+	for _, r := range fmt.Sprint(value) {
+		switch {
+		case 'a' <= r && r <= 'z', '0' <= r && r <= '9':
+			write(string(r))
+		case 'A' <= r && r <= 'Z':
+			write(string(r - 'A' + 'a'))
+		case r == '&':
+			// "&" is a word of its own.
+			pendingHyphen = true
+			write("and")
+			pendingHyphen = true
+		case transliterations[r] != "":
+			write(transliterations[r])
+		default:
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
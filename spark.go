@@ -0,0 +1,52 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// sparkLevels are the glyphs of a sparkline, from the lowest value to the highest.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// spark renders a slice of numbers as a sparkline for compact metric displays: {series:spark}
+// renders []float64{1, 2, 3, 5, 8} as "▁▂▃▅█". Values are scaled between the minimum and the
+// maximum of the series; a flat series renders at the lowest level.
+func spark(series interface{}) (string, error) {
+	values, err := floats(series, "spark")
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+	lo, hi := values[0], values[0]
+	for _, f := range values {
+		lo, hi = min(lo, f), max(hi, f)
+	}
+	line := make([]rune, len(values))
+	for n, f := range values {
+		level := 0
+		if hi > lo {
+			level = int((f-lo)/(hi-lo)*float64(len(sparkLevels)-1) + 0.5)
+		}
+		line[n] = sparkLevels[level]
+	}
+	return string(line), nil
+}
+
+// floats converts a slice of numbers to float64s, for the spec or filter name.
+func floats(series interface{}, name string) ([]float64, error) {
+	v := indirect(reflect.ValueOf(series))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, &Error{Spec: name, Err: fmt.Errorf("cannot format %T as a series", series), kind: ErrUnsupportedType}
+	}
+	values := make([]float64, v.Len())
+	for n := range values {
+		r, err := toRat(v.Index(n).Interface())
+		if err != nil {
+			return nil, &Error{Spec: name, Err: err, kind: ErrUnsupportedType}
+		}
+		values[n], _ = r.Float64()
+	}
+	return values, nil
+}
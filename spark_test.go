@@ -0,0 +1,31 @@
+package fstr
+
+import "testing"
+
+func TestSpark(t *testing.T) {
+	tests := []struct {
+		series interface{}
+		want   string
+	}{
+		{series: []float64{1, 2, 3, 5, 8}, want: "▁▂▃▅█"},
+		{series: []int{0, 7, 14}, want: "▁▅█"},
+		{series: []interface{}{3, 1.5, uint8(0)}, want: "█▅▁"},
+		{series: []float64{4, 4, 4}, want: "▁▁▁"},
+		{series: []float64{}, want: ""},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{series:spark}", map[string]interface{}{"series": tt.series})
+		if err != nil {
+			t.Errorf("Interpolate(%v) error = %v", tt.series, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%v) = %q, want %q", tt.series, got, tt.want)
+		}
+	}
+	for _, series := range []interface{}{42, []string{"a"}} {
+		if _, err := Interpolate("{series:spark}", map[string]interface{}{"series": series}); err == nil {
+			t.Errorf("Interpolate(%v) error = nil, want error", series)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package fstr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// keyRe matches a data map key.
+var keyRe = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// callRe matches a named specifier or filter with optional arguments, such as `frac`, `frac(16)`,
+// `diff(new)` or `kv("=", ",")`.
+var callRe = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9]*)(?:\(([^()]*)\))?$`)
+
+// call is a parsed named specifier or filter.
+type call struct {
+	name string
+	args []string
+}
+
+// parseCall parses a named specifier or filter. Arguments are separated by commas and may be
+// double-quoted Go string literals, which allows them to contain commas and spaces.
+// It reports false if s is not well-formed.
+func parseCall(s string) (call, bool) {
+	matches := callRe.FindStringSubmatch(s)
+	if matches == nil {
+		return call{}, false
+	}
+	c := call{name: matches[1]}
+	rest := strings.TrimSpace(matches[2])
+	for rest != "" {
+		var arg string
+		if rest[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return call{}, false
+			}
+			if arg, err = strconv.Unquote(quoted); err != nil {
+				return call{}, false
+			}
+			rest = strings.TrimSpace(rest[len(quoted):])
+			if rest != "" && rest[0] != ',' {
+				return call{}, false
+			}
+		} else {
+			raw, _, _ := strings.Cut(rest, ",")
+			arg = strings.TrimSpace(raw)
+			rest = rest[len(raw):]
+		}
+		c.args = append(c.args, arg)
+		if rest != "" {
+			rest = strings.TrimSpace(rest[1:])
+			if rest == "" {
+				return call{}, false
+			}
+		}
+	}
+	return c, true
+}
+
+// splitFilters splits a chain of filters such as `snake|truncate(80)` into its filters,
+// ignoring any '|' inside parentheses.
+func splitFilters(chain string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range chain {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				parts = append(parts, chain[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, chain[start:])
+}
+
+// namedSpec describes a named format specifier. Each one is rendered by its template function,
+// which is called with the placeholder's value followed by the spec's arguments.
+type namedSpec struct {
+	fn      interface{} // nil for specs bound to an Interpolator; see Interpolator.funcs
+	kind    Kind        // kind of value the spec formats
+	refs    bool        // the arguments name other data map keys, rather than being literal strings
+	minArgs int
+	maxArgs int
+}
+
+// namedSpecs lists the named format specifiers.
+var namedSpecs = map[string]namedSpec{
+	"bar":      {fn: bar, kind: KindNumber, maxArgs: 1},
+	"csv":      {kind: KindAny},
+	"diff":     {kind: KindAny, refs: true, minArgs: 1, maxArgs: 1},
+	"dms":      {fn: dms, kind: KindNumber, maxArgs: 1},
+	"frac":     {fn: frac, kind: KindNumber, maxArgs: 1},
+	"fx":       {kind: KindNumber, maxArgs: 1},
+	"iban":     {fn: iban, kind: KindString, maxArgs: 1},
+	"invoice":  {kind: KindAny, refs: true, maxArgs: 1},
+	"kv":       {kind: KindAny, maxArgs: 2},
+	"link":     {kind: KindString, maxArgs: 1},
+	"matrix":   {kind: KindAny, maxArgs: 1},
+	"mdlist":   {fn: mdlist, kind: KindAny},
+	"mdtable":  {kind: KindAny},
+	"money":    {kind: KindAny, maxArgs: 3},
+	"q":        {fn: quote, kind: KindAny},
+	"query":    {fn: Query, kind: KindAny},
+	"ratio":    {fn: ratio, kind: KindNumber, refs: true, minArgs: 1, maxArgs: 1},
+	"roman":    {fn: roman, kind: KindNumber},
+	"routing":  {fn: routing, kind: KindString, maxArgs: 1},
+	"sortcode": {fn: sortcode, kind: KindString, maxArgs: 1},
+	"spark":    {fn: spark, kind: KindAny},
+	"table":    {kind: KindAny},
+	"token":    {kind: KindAny, minArgs: 1, maxArgs: 2},
+	"tsv":      {kind: KindAny},
+	"words":    {fn: inWords, kind: KindAny, maxArgs: 1},
+	"xml":      {fn: xmlText, kind: KindAny},
+	"xmlattr":  {fn: xmlAttr, kind: KindAny},
+}
+
+// parseNamedSpec parses a named format specifier.
+// It reports false if spec is not a known named spec or has the wrong number of arguments.
+func parseNamedSpec(spec string) (call, bool) {
+	c, ok := parseCall(spec)
+	if !ok {
+		return call{}, false
+	}
+	def, ok := namedSpecs[c.name]
+	if !ok || len(c.args) < def.minArgs || len(c.args) > def.maxArgs {
+		return call{}, false
+	}
+	if def.refs {
+		for _, arg := range c.args {
+			if !keyRe.MatchString(arg) {
+				return call{}, false
+			}
+		}
+	}
+	return c, true
+}
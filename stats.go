@@ -0,0 +1,121 @@
+package fstr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// stats summarizes a slice of numbers for benchmark and metrics output: {samples|stats} renders
+// "n=1000 min=0.2 max=9.8 mean=4.97 p95=9.1". Statistics are rounded to two decimal places, and the
+// 95th percentile is interpolated linearly between the closest ranks. An empty slice renders "n=0".
+func stats(series interface{}) (string, error) {
+	values, err := floats(series, "stats")
+	if err != nil {
+		return "", err
+	}
+	if len(values) == 0 {
+		return "n=0", nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, f := range sorted {
+		sum += f
+	}
+	return fmt.Sprintf("n=%d min=%s max=%s mean=%s p95=%s", len(sorted),
+		statString(sorted[0]), statString(sorted[len(sorted)-1]),
+		statString(sum/float64(len(sorted))), statString(percentile(sorted, 0.95))), nil
+}
+
+// percentile returns the p-th quantile (0 <= p <= 1) of sorted, interpolating linearly between the
+// closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	if lo+1 >= len(sorted) {
+		return sorted[lo]
+	}
+	return sorted[lo] + (rank-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
+
+// statString formats a statistic rounded to two decimal places, without trailing zeros.
+func statString(f float64) string {
+	if math.Abs(f) < 1e15 { // larger numbers have no decimal places to round, and could overflow
+		f = math.Round(f*100) / 100
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// histWidth is the length of the bar of a histogram's largest bucket.
+const histWidth = 40
+
+// maxHistBuckets is the largest number of buckets a histogram may have.
+const maxHistBuckets = 1000
+
+// hist renders a slice of numbers as a text histogram for quick distribution views in terminal tools:
+// {values|hist(4)} splits the range between the minimum and the maximum into 4 buckets of equal width,
+// and renders a line per bucket with its range, a bar of '#' and its count:
+//
+//	   1 - 3.25 | ######################################## 4
+//	3.25 -  5.5 | #################### 2
+//	...
+//
+// The largest bucket has a bar of 40 characters. There are 10 buckets by default, and at most 1000; a
+// flat series has one.
+func hist(series interface{}, buckets ...string) (string, error) {
+	n := 10
+	if len(buckets) > 0 {
+		var err error
+		if n, err = strconv.Atoi(buckets[0]); err != nil || n < 1 || n > maxHistBuckets {
+			return "", &Error{Spec: "hist", Err: fmt.Errorf("invalid bucket count %q", buckets[0]), kind: ErrBadSpec}
+		}
+	}
+	values, err := floats(series, "hist")
+	if err != nil || len(values) == 0 {
+		return "", err
+	}
+	lo, hi := values[0], values[0]
+	for _, f := range values {
+		lo, hi = min(lo, f), max(hi, f)
+	}
+	if hi == lo {
+		n = 1
+	}
+	counts := make([]int, n)
+	for _, f := range values {
+		b := n - 1 // the maximum closes the last bucket
+		if hi > lo {
+			// Halving keeps the range finite for values near the limits of float64.
+			b = min(max(int((f/2-lo/2)/(hi/2-lo/2)*float64(n)), 0), b)
+		}
+		counts[b]++
+	}
+	bounds := make([]string, n+1)
+	for b := range bounds {
+		t := float64(b) / float64(n)
+		bounds[b] = statString(lo*(1-t) + hi*t)
+	}
+	width, most := 0, 0
+	for b := range bounds {
+		width = max(width, len(bounds[b]))
+	}
+	for _, c := range counts {
+		most = max(most, c)
+	}
+	lines := make([]string, n)
+	for b, c := range counts {
+		line := fmt.Sprintf("%*s - %*s | ", width, bounds[b], width, bounds[b+1])
+		if bar := strings.Repeat("#", int(math.Round(float64(c)/float64(most)*histWidth))); bar != "" {
+			line += bar + " "
+		}
+		lines[b] = line + strconv.Itoa(c)
+	}
+	return strings.Join(lines, "\n"), nil
+}
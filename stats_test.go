@@ -0,0 +1,81 @@
+package fstr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		samples interface{}
+		want    string
+	}{
+		{samples: []float64{9.8, 0.2, 5, 4.88}, want: "n=4 min=0.2 max=9.8 mean=4.97 p95=9.08"},
+		{samples: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21}, want: "n=21 min=1 max=21 mean=11 p95=20"},
+		{samples: []float64{-1.005, -2}, want: "n=2 min=-2 max=-1 mean=-1.5 p95=-1.05"},
+		{samples: []float64{3}, want: "n=1 min=3 max=3 mean=3 p95=3"},
+		{samples: []float64{}, want: "n=0"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{samples|stats}", map[string]interface{}{"samples": tt.samples})
+		if err != nil {
+			t.Errorf("Interpolate(%v) error = %v", tt.samples, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%v) = %q, want %q", tt.samples, got, tt.want)
+		}
+	}
+	for _, samples := range []interface{}{42, []string{"a"}} {
+		if _, err := Interpolate("{samples|stats}", map[string]interface{}{"samples": samples}); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Interpolate(%v) error = %v, want ErrUnsupportedType", samples, err)
+		}
+	}
+}
+
+func TestHist(t *testing.T) {
+	tests := []struct {
+		format string
+		values interface{}
+		want   string
+	}{
+		{
+			format: "{values|hist(4)}",
+			values: []float64{1, 2, 2, 3, 4, 5, 5.5, 7, 8, 10},
+			want: "   1 - 3.25 | ######################################## 4\n" +
+				"3.25 -  5.5 | #################### 2\n" +
+				" 5.5 - 7.75 | #################### 2\n" +
+				"7.75 -   10 | #################### 2",
+		},
+		{
+			format: "{values|hist(3)}",
+			values: []int{0, 0, 0, 0, 9},
+			want:   "0 - 3 | ######################################## 4\n3 - 6 | 0\n6 - 9 | ########## 1",
+		},
+		{format: "{values|hist}", values: []int{7, 7}, want: "7 - 7 | ######################################## 2"},
+		{format: "{values|hist}", values: []float64{}, want: ""},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"values": tt.values})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.values, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.values, got, tt.want)
+		}
+	}
+	for _, format := range []string{"{values|hist(0)}", "{values|hist(1001)}", "{values|hist(1000000000)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"values": []int{1}}); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("Interpolate(%q) error = %v, want ErrBadSpec", format, err)
+		}
+	}
+	got, err := Interpolate("{values|hist(2)}", map[string]interface{}{"values": []float64{-1e308, 1e308, 0.5}})
+	if err != nil || strings.Count(got, "\n") != 1 || !strings.HasSuffix(got, "| ######################################## 2") {
+		t.Errorf("Interpolate(hist of extreme values) = %q, %v", got, err)
+	}
+	if _, err := Interpolate("{values|hist}", map[string]interface{}{"values": "1 2 3"}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Interpolate(string) error = %v, want ErrUnsupportedType", err)
+	}
+}
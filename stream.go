@@ -0,0 +1,164 @@
+package fstr
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// KV is a key and its value, sent to Template.Stream as it becomes available.
+type KV struct {
+	Key   string
+	Value interface{}
+}
+
+// Stream renders the template to w incrementally, as the values of its keys arrive from values, for
+// server-sent events and chat-style streaming responses. Text is written as soon as the placeholders
+// before it are rendered, and each placeholder or loop block as soon as values has provided the keys
+// it uses; after each write, w is flushed if it is an http.Flusher or has a Flush() error method,
+// such as a *bufio.Writer.
+//
+//	values := make(chan fstr.KV)
+//	go func() {
+//		defer close(values)
+//		values <- fstr.KV{Key: "user", Value: "Ziad"}
+//		values <- fstr.KV{Key: "answer", Value: slowAnswer()}
+//	}()
+//	err := reply.Stream(w, values) // "Hi Ziad, " is sent before slowAnswer returns
+//
+// Partials, {*} and templates using inheritance are rendered once values is closed. A key still
+// missing when values is closed is resolved by the WithMissingKeyFunc resolver, if any, and
+// otherwise fails like a missing key in Execute; the text rendered up to the failing placeholder has
+// been written by then, so Stream reports the first error only, even WithAllErrors. Later values of
+// a key replace earlier ones until the key is used. Values containing placeholders are expanded
+// WithRecursion once values is closed, as they may refer to any key. Metrics and the error hook
+// observe the whole stream, and with WithUnusedKeysFunc, Stream reads values until it is closed to
+// report the keys that were not used.
+func (t *Template) Stream(w io.Writer, values <-chan KV) error {
+	i := t.interp
+	start := time.Now()
+	err := t.stream(w, values, i.mode(i.colorEnabled(w)))
+	if i.metrics != nil {
+		i.metrics.ObserveRender(time.Since(start), err)
+	}
+	if err != nil && i.errorHook != nil {
+		i.reportError(err)
+	}
+	return err
+}
+
+// stream implements Stream, rendering in mode m.
+func (t *Template) stream(w io.Writer, values <-chan KV, m renderMode) error {
+	i := t.interp
+	data := make(map[string]interface{})
+	receive := func(keys []string) {
+		for !hasKeys(data, keys) {
+			kv, ok := <-values
+			if !ok {
+				return
+			}
+			data[kv.Key] = kv.Value
+		}
+	}
+	drain := func() {
+		for kv := range values {
+			data[kv.Key] = kv.Value
+		}
+	}
+	if _, err := i.parse(t.format, m); err != nil {
+		return err
+	}
+	if extendsRe.MatchString(trimMarkers(t.format)) || namedBlockRe.MatchString(t.format) {
+		drain()
+		s, err := i.execute(t.format, data, m)
+		if err != nil {
+			return err
+		}
+		return writeFlush(w, s)
+	}
+	for _, seg := range segments(t.format) {
+		s := seg.text
+		if seg.fragment {
+			reqs, expandAll := requirements(seg.text)
+			if expandAll || partialRe.MatchString(seg.text) {
+				drain()
+			} else {
+				keys := make([]string, len(reqs))
+				for n, r := range reqs {
+					keys[n] = r.Key
+				}
+				receive(keys)
+				if i.depth > 0 && hasTemplateValue(data, keys) {
+					drain() // the values it refers to may come later
+				}
+			}
+			var err error
+			if s, err = t.renderFragment(seg.text, data, m); err != nil {
+				var e *Error
+				if errors.As(err, &e) {
+					e.Format = t.format
+				}
+				return err
+			}
+		}
+		if err := writeFlush(w, s); err != nil {
+			return err
+		}
+	}
+	if i.unused != nil {
+		drain()
+		if keys := i.unusedKeys(t.format, data); len(keys) > 0 {
+			i.unused(t.format, keys)
+		}
+	}
+	return nil
+}
+
+// renderFragment renders a fragment of the template with the values received so far, resolving
+// missing keys and expanding values as Execute does.
+func (t *Template) renderFragment(fragment string, data map[string]interface{}, m renderMode) (string, error) {
+	data = t.interp.resolveMissing(fragment, data)
+	data, err := t.interp.expandValues(fragment, data, t.interp.depth, m)
+	if err != nil {
+		return "", err
+	}
+	return t.interp.render(fragment, data, m)
+}
+
+// hasTemplateValue reports whether the value of any of keys in data is a string containing
+// placeholders, to be expanded WithRecursion.
+func hasTemplateValue(data map[string]interface{}, keys []string) bool {
+	for _, k := range keys {
+		if s, ok := data[k].(string); ok && hasPlaceholders(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKeys reports whether data holds every one of keys.
+func hasKeys(data map[string]interface{}, keys []string) bool {
+	for _, k := range keys {
+		if _, ok := data[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// writeFlush writes s to w and flushes w, if it can be flushed.
+func writeFlush(w io.Writer, s string) error {
+	if s != "" {
+		if _, err := io.WriteString(w, s); err != nil {
+			return err
+		}
+	}
+	switch f := w.(type) {
+	case http.Flusher:
+		f.Flush()
+	case interface{ Flush() error }:
+		return f.Flush()
+	}
+	return nil
+}
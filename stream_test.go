@@ -0,0 +1,141 @@
+package fstr
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chunkWriter sends every write to a channel.
+type chunkWriter chan string
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestStream(t *testing.T) {
+	tmpl := MustCompile("Hi {user}, {answer|trimSpace} ({n:,} tokens){#each refs} [{url}]{/each}.")
+	values := make(chan KV)
+	chunks := make(chunkWriter, 10)
+	done := make(chan error)
+	go func() {
+		done <- tmpl.Stream(chunks, values)
+	}()
+
+	if got := <-chunks; got != "Hi " {
+		t.Errorf("first chunk = %q, want %q", got, "Hi ")
+	}
+	values <- KV{Key: "user", Value: "Ziad"}
+	for _, want := range []string{"Ziad", ", "} {
+		if got := <-chunks; got != want {
+			t.Errorf("chunk = %q, want %q", got, want)
+		}
+	}
+	values <- KV{Key: "n", Value: 1234} // not needed yet
+	values <- KV{Key: "answer", Value: " 42 "}
+	for _, want := range []string{"42", " (", "1,234", " tokens)"} {
+		if got := <-chunks; got != want {
+			t.Errorf("chunk = %q, want %q", got, want)
+		}
+	}
+	values <- KV{Key: "refs", Value: []map[string]interface{}{{"url": "a"}, {"url": "b"}}}
+	for _, want := range []string{" [a] [b]", "."} {
+		if got := <-chunks; got != want {
+			t.Errorf("chunk = %q, want %q", got, want)
+		}
+	}
+	close(values)
+	if err := <-done; err != nil {
+		t.Errorf("Stream() error = %v", err)
+	}
+}
+
+func TestStreamClosed(t *testing.T) {
+	var b strings.Builder
+	w := bufio.NewWriter(&b)
+	values := make(chan KV, 1)
+	values <- KV{Key: "a", Value: 1}
+	close(values)
+	err := New(WithStrictKeys()).MustCompile("{a} then {b} never").Stream(w, values)
+	if !errors.Is(err, ErrMissingKey) {
+		t.Errorf("Stream() error = %v, want ErrMissingKey", err)
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Format != "{a} then {b} never" || e.Placeholder != "{b}" {
+		t.Errorf("Stream() error = %#v, want *Error for the format and placeholder", err)
+	}
+	if got := b.String(); got != "1 then " {
+		t.Errorf("Stream() wrote %q, want %q (flushed)", got, "1 then ")
+	}
+
+	interp := New(WithPartials(map[string]string{
+		"sig":  "-- {sender}",
+		"base": "[{block body}{/block}]",
+	}))
+	for format, want := range map[string]string{
+		"{msg} {>sig}": "hi -- bot",
+		"{extends base}{block body}{msg}{/block}": "[hi]",
+		"{*}": "msg=hi sender=bot",
+	} {
+		values := make(chan KV, 2)
+		values <- KV{Key: "msg", Value: "hi"}
+		values <- KV{Key: "sender", Value: "bot"}
+		close(values)
+		var b strings.Builder
+		if err := interp.MustCompile(format).Stream(&b, values); err != nil || b.String() != want {
+			t.Errorf("Stream(%q) = %q, %v, want %q", format, b.String(), err, want)
+		}
+	}
+}
+
+func TestStreamOptions(t *testing.T) {
+	m := &countingMetrics{}
+	var reported []string
+	var unused []string
+	interp := New(
+		WithMissingKeyFunc(func(key string) (interface{}, bool) { return "resolved", key == "extra" }),
+		WithRecursion(1),
+		WithMetrics(m),
+		WithErrorHook(func(e *Error) { reported = append(reported, e.Error()) }),
+		WithUnusedKeysFunc(func(format string, keys []string) { unused = keys }),
+		WithStrictKeys(),
+	)
+	tmpl := interp.MustCompile("Hi {user} {extra}")
+	stream := func(kvs ...KV) (string, error) {
+		values := make(chan KV, len(kvs))
+		for _, kv := range kvs {
+			values <- kv
+		}
+		close(values)
+		var b strings.Builder
+		err := tmpl.Stream(&b, values)
+		return b.String(), err
+	}
+	data := map[string]interface{}{"user": "{name}", "name": "Z", "spare": 1}
+	want, err := tmpl.Execute(data)
+	if err != nil || want != "Hi Z resolved" {
+		t.Fatalf("Execute() = %q, %v", want, err)
+	}
+	wantUnused := unused
+	unused = nil
+	got, err := stream(KV{Key: "user", Value: "{name}"}, KV{Key: "name", Value: "Z"}, KV{Key: "spare", Value: 1})
+	if err != nil || got != want {
+		t.Errorf("Stream() = %q, %v, want %q as from Execute", got, err, want)
+	}
+	if len(unused) == 0 || !reflect.DeepEqual(unused, wantUnused) {
+		t.Errorf("unused keys = %v, want %v as from Execute", unused, wantUnused)
+	}
+	reported = nil
+	if _, err := stream(); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("Stream() error = %v, want ErrMissingKey", err)
+	}
+	if want := []string{`fstr: {user}: missing key "user"`}; len(reported) != 1 || reported[0] != want[0] {
+		t.Errorf("reported %q, want %q", reported, want)
+	}
+	if m.renders != 3 || m.errors != 1 {
+		t.Errorf("renders, errors = %d, %d, want 3, 1", m.renders, m.errors)
+	}
+}
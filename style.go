@@ -0,0 +1,137 @@
+package fstr
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ColorMode selects when styles emit ANSI escape codes. See WithColor.
+type ColorMode int
+
+const (
+	// ColorAuto emits escape codes only if the output is written to a terminal, by Fprint, Print
+	// or their variants, following the
+	// conventions of the environment: a non-empty NO_COLOR variable disables them, and a
+	// FORCE_COLOR or CLICOLOR_FORCE variable set to anything but "0" enables them regardless
+	// of the output. NO_COLOR takes precedence.
+	ColorAuto ColorMode = iota
+	// ColorAlways emits escape codes regardless of the output, for example to force colors
+	// in CI logs that render them.
+	ColorAlways
+	// ColorNever renders styled values as plain text.
+	ColorNever
+)
+
+// stringColor reports whether styles emit escape codes in results returned as strings, such as those
+// of Interpolate and Table. Since such results may be written anywhere, including files, JSON or
+// Markdown documents, they are styled only if the Interpolator is configured WithColor(ColorAlways).
+func (i *Interpolator) stringColor() bool {
+	return i.color == ColorAlways
+}
+
+// colorEnabled reports whether styles written to w emit escape codes.
+func (i *Interpolator) colorEnabled(w io.Writer) bool {
+	switch i.color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	for _, name := range []string{"FORCE_COLOR", "CLICOLOR_FORCE"} {
+		if v := os.Getenv(name); v != "" && v != "0" {
+			return true
+		}
+	}
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && isTerminal(f.Fd())
+}
+
+// ansiCodes maps style names to their ANSI SGR parameters.
+var ansiCodes = map[string]int{
+	"bold":          1,
+	"dim":           2,
+	"italic":        3,
+	"underline":     4,
+	"blink":         5,
+	"reverse":       7,
+	"strikethrough": 9,
+
+	"black":   30,
+	"red":     31,
+	"green":   32,
+	"yellow":  33,
+	"blue":    34,
+	"magenta": 35,
+	"cyan":    36,
+	"white":   37,
+	"gray":    90,
+
+	"bgBlack":   40,
+	"bgRed":     41,
+	"bgGreen":   42,
+	"bgYellow":  43,
+	"bgBlue":    44,
+	"bgMagenta": 45,
+	"bgCyan":    46,
+	"bgWhite":   47,
+}
+
+// ansiReset ends styled text.
+const ansiReset = "\x1b[0m"
+
+// isStyleSpec reports whether spec is a comma-separated list of style names, as in {status:red,bold}.
+func isStyleSpec(spec string) bool {
+	for _, name := range strings.Split(spec, ",") {
+		if _, ok := ansiCodes[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// style wraps a value in the ANSI escape codes of a comma-separated list of styles, for colorizing
+// command-line output: {status:red,bold}. See ansiCodes for the style names.
+func style(value interface{}, styles string) (string, error) {
+	var codes []string
+	for _, name := range strings.Split(styles, ",") {
+		code, ok := ansiCodes[strings.TrimSpace(name)]
+		if !ok {
+			return "", &Error{Spec: styles, Err: fmt.Errorf("unknown style %q", name), kind: ErrBadSpec}
+		}
+		codes = append(codes, strconv.Itoa(code))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + fmt.Sprint(value) + ansiReset, nil
+}
+
+// colorize is the filter form of a single style: {msg|color(green)}.
+func colorize(value interface{}, name string) (string, error) {
+	s, err := style(value, name)
+	if err != nil {
+		return "", &Error{Spec: "color", Err: fmt.Errorf("unknown color %q", name), kind: ErrBadSpec}
+	}
+	return s, nil
+}
+
+// link renders a URL as a terminal hyperlink showing text, or the URL itself if no text is given:
+// {url:link(Docs)}. It uses the OSC 8 escape sequence if color is set; otherwise it falls back to
+// plain text, "Docs (https://example.com)".
+func link(value interface{}, color bool, text ...string) string {
+	url := fmt.Sprint(value)
+	label := url
+	if len(text) > 0 && text[0] != "" {
+		label = text[0]
+	}
+	if color {
+		return "\x1b]8;;" + url + "\x1b\\" + label + "\x1b]8;;\x1b\\"
+	}
+	if label == url {
+		return url
+	}
+	return label + " (" + url + ")"
+}
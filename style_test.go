@@ -0,0 +1,180 @@
+package fstr
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStyles(t *testing.T) {
+	data := map[string]interface{}{"status": "FAIL", "msg": "ok", "n": 1234.5}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{status:red}", want: "\x1b[31mFAIL\x1b[0m"},
+		{format: "{status:red,bold}", want: "\x1b[31;1mFAIL\x1b[0m"},
+		{format: "{status:bgYellow,black,underline}", want: "\x1b[43;30;4mFAIL\x1b[0m"},
+		{format: "{msg|color(green)}", want: "\x1b[32mok\x1b[0m"},
+		{format: "{n|color(cyan)}", want: "\x1b[36m1234.5\x1b[0m"},
+		{format: "{status:purple}", want: "{status:purple}"},
+		{format: "{n:,}", want: "1,234"},
+	}
+	interp := New(WithColor(ColorAlways))
+	for _, tt := range tests {
+		got, err := interp.Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if _, err := interp.Interpolate("{msg|color(purple)}", data); err == nil {
+		t.Errorf("Interpolate() error = nil, want error")
+	}
+}
+
+func TestColorModes(t *testing.T) {
+	for _, name := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE"} {
+		t.Setenv(name, "")
+	}
+	data := map[string]interface{}{"status": "FAIL"}
+	const format = "{status:red} {status|color(green)}"
+
+	var b bytes.Buffer
+	if _, err := New().Fprint(&b, format, data); err != nil {
+		t.Fatalf("Fprint() error = %v", err)
+	}
+	if got, want := b.String(), "FAIL FAIL"; got != want {
+		t.Errorf("Fprint() to a buffer wrote %q, want %q", got, want)
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := Fprintln(f, format, data); err != nil {
+		t.Fatalf("Fprintln() error = %v", err)
+	}
+	if got, _ := os.ReadFile(f.Name()); string(got) != "FAIL FAIL\n" {
+		t.Errorf("Fprintln() to a file wrote %q, want %q", got, "FAIL FAIL\n")
+	}
+
+	b.Reset()
+	n, err := New(WithColor(ColorAlways)).Fprint(&b, format, data)
+	if err != nil {
+		t.Fatalf("Fprint() error = %v", err)
+	}
+	if got, want := b.String(), "\x1b[31mFAIL\x1b[0m \x1b[32mFAIL\x1b[0m"; got != want || n != len(want) {
+		t.Errorf("Fprint() with ColorAlways wrote %q (%d bytes), want %q", got, n, want)
+	}
+
+	got, err := New(WithColor(ColorNever)).Interpolate(format, data)
+	if err != nil {
+		t.Fatalf("Interpolate() error = %v", err)
+	}
+	if got != "FAIL FAIL" {
+		t.Errorf("Interpolate() with ColorNever = %q, want %q", got, "FAIL FAIL")
+	}
+
+	if _, err := Fprint(&b, "{status|color(purple)}", data); err == nil {
+		t.Errorf("Fprint() with an unknown color: error = nil, want error")
+	}
+}
+
+func TestLink(t *testing.T) {
+	data := map[string]interface{}{"url": "https://example.com/docs"}
+	tests := []struct {
+		format string
+		mode   ColorMode
+		want   string
+	}{
+		{format: "{url:link(Docs)}", mode: ColorAlways, want: "\x1b]8;;https://example.com/docs\x1b\\Docs\x1b]8;;\x1b\\"},
+		{format: "{url:link}", mode: ColorAlways, want: "\x1b]8;;https://example.com/docs\x1b\\https://example.com/docs\x1b]8;;\x1b\\"},
+		{format: `{url:link("Read the docs")}`, mode: ColorNever, want: "Read the docs (https://example.com/docs)"},
+		{format: "{url:link}", mode: ColorNever, want: "https://example.com/docs"},
+	}
+	for _, tt := range tests {
+		got, err := New(WithColor(tt.mode)).Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestStringColor(t *testing.T) {
+	setTerminal(t, 80)
+	for _, name := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE"} {
+		t.Setenv(name, "")
+	}
+	data := map[string]interface{}{"status": "FAIL"}
+	rows := []map[string]interface{}{data}
+
+	// Output written to a terminal is styled, but strings are not, wherever stdout goes.
+	f, err := os.Create(filepath.Join(t.TempDir(), "tty"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := Fprint(f, "{status:red}", data); err != nil {
+		t.Fatalf("Fprint() error = %v", err)
+	}
+	if got, _ := os.ReadFile(f.Name()); string(got) != "\x1b[31mFAIL\x1b[0m" {
+		t.Errorf("Fprint() to a terminal wrote %q, want it styled", got)
+	}
+	if got, err := Interpolate("{status:red}", data); err != nil || got != "FAIL" {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, "FAIL")
+	}
+	if got, err := Table(rows, "{status:red}"); err != nil || strings.Contains(got, "\x1b") {
+		t.Errorf("Table() = %q, %v, want no escape codes", got, err)
+	}
+	if got, err := CSV(rows, "{status:red}"); err != nil || strings.Contains(got, "\x1b") {
+		t.Errorf("CSV() = %q, %v, want no escape codes", got, err)
+	}
+	if got, err := MarkdownTable(rows, "{status:red}"); err != nil || strings.Contains(got, "\x1b") {
+		t.Errorf("MarkdownTable() = %q, %v, want no escape codes", got, err)
+	}
+	if got, err := New(WithColor(ColorAlways)).Interpolate("{status:red}", data); err != nil || got != "\x1b[31mFAIL\x1b[0m" {
+		t.Errorf("Interpolate() with ColorAlways = %q, %v, want it styled", got, err)
+	}
+}
+
+func TestColorEnvironment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		mode ColorMode
+		want string
+	}{
+		{name: "Not a terminal", want: "FAIL"},
+		{name: "FORCE_COLOR", env: map[string]string{"FORCE_COLOR": "1"}, want: "\x1b[31mFAIL\x1b[0m"},
+		{name: "FORCE_COLOR=0", env: map[string]string{"FORCE_COLOR": "0"}, want: "FAIL"},
+		{name: "CLICOLOR_FORCE", env: map[string]string{"CLICOLOR_FORCE": "1"}, want: "\x1b[31mFAIL\x1b[0m"},
+		{name: "NO_COLOR wins", env: map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, want: "FAIL"},
+		{name: "ColorAlways overrides NO_COLOR", env: map[string]string{"NO_COLOR": "1"}, mode: ColorAlways, want: "\x1b[31mFAIL\x1b[0m"},
+		{name: "ColorNever overrides FORCE_COLOR", env: map[string]string{"FORCE_COLOR": "1"}, mode: ColorNever, want: "FAIL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE"} {
+				t.Setenv(name, tt.env[name])
+			}
+			var b bytes.Buffer
+			if _, err := New(WithColor(tt.mode)).Fprint(&b, "{status:red}", map[string]interface{}{"status": "FAIL"}); err != nil {
+				t.Fatalf("Fprint() error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("Fprint() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Table renders rows as an aligned plain-text table with a header line. See Interpolator.Table.
+func Table(rows interface{}, columns string) (string, error) {
+	return defaultInterpolator.Table(rows, columns)
+}
+
+// Table renders rows, a slice of structs or of maps with string keys, as an aligned plain-text table:
+//
+//	name   balance
+//	-----  ---------
+//	Alice  1,234.50
+//	Bob       12.00
+//
+// columns lists the columns as placeholders, each formatting one field of a row, e.g.
+// "{name} {balance:,.2f}"; the header of each column is its key. If columns is empty, every field is
+// shown with default formatting: struct fields in declaration order, map keys in the configured KeyOrder.
+// Struct fields are named by their `fstr` struct tag, if any, as in Diff.
+//
+// Columns whose values are all numbers are right-aligned. The {rows:table} spec renders a table with
+// all columns.
+func (i *Interpolator) Table(rows interface{}, columns string) (string, error) {
+	return i.renderedTable(rows, columns, i.mode(i.stringColor()))
+}
+
+// renderedTable implements Table, rendering cells in mode m.
+func (i *Interpolator) renderedTable(rows interface{}, columns string, m renderMode) (string, error) {
+	header, cells, numeric, err := i.tableCells(rows, columns, m)
+	if err != nil {
+		return "", err
+	}
+	return renderTable(header, cells, numeric), nil
+}
+
+// table implements the {rows:table} spec.
+func (i *Interpolator) table(rows interface{}, m renderMode) (string, error) {
+	return i.renderedTable(rows, "", m)
+}
+
+// tableCells renders the header and cells of a table, and reports which columns hold numbers.
+// Cells are rendered in mode m.
+func (i *Interpolator) tableCells(rows interface{}, columns string, m renderMode) (header []string, cells [][]string, numeric []bool, err error) {
+	records, keys, err := records(rows, i.keyOrder)
+	if err != nil {
+		return nil, nil, nil, &Error{Spec: "table", Err: err, kind: ErrUnsupportedType}
+	}
+	header, formats, err := tableColumns(columns, keys)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	numeric = make([]bool, len(header))
+	for c, key := range header {
+		numeric[c] = len(records) > 0
+		for _, r := range records {
+			if v, ok := r[key]; ok && !isNumber(v) && !isMoney(v) {
+				numeric[c] = false
+				break
+			}
+		}
+	}
+	for _, r := range records {
+		row := make([]string, len(formats))
+		for c, format := range formats {
+			if _, ok := r[header[c]]; !ok {
+				continue // the row has no such field
+			}
+			if row[c], err = i.render(format, r, m); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		cells = append(cells, row)
+	}
+	for c, key := range header {
+		header[c] = i.escape.escapeString(key)
+	}
+	return header, cells, numeric, nil
+}
+
+// tableColumns returns the keys and placeholders of the columns of a table, given as placeholders or, if
+// columns is empty, as the fields of its records, keys.
+func tableColumns(columns string, keys []string) (header, formats []string, err error) {
+	if columns == "" {
+		for _, k := range keys {
+			header = append(header, k)
+			formats = append(formats, "{"+k+"}")
+		}
+		return header, formats, nil
+	}
+	for _, m := range placeholderRe.FindAllString(columns, -1) {
+		p, ok := parsePlaceholder(m)
+		if !ok || p.literal || p.secret || p.key == "*" {
+			return nil, nil, &Error{Format: columns, Err: fmt.Errorf("invalid column %s", m), kind: ErrParse}
+		}
+		header = append(header, p.key)
+		formats = append(formats, m)
+	}
+	return header, formats, nil
+}
+
+// renderTable lays out a header and rows of cells in aligned columns separated by two spaces,
+// with a line of dashes under the header. Numeric columns are right-aligned. The footer rows, such
+// as totals, follow another line of dashes.
+func renderTable(header []string, cells [][]string, numeric []bool, footer ...[]string) string {
+	widths := make([]int, len(header))
+	for c, h := range header {
+		widths[c] = utf8.RuneCountInString(h)
+		for _, row := range cells {
+			widths[c] = max(widths[c], utf8.RuneCountInString(row[c]))
+		}
+		for _, row := range footer {
+			widths[c] = max(widths[c], utf8.RuneCountInString(row[c]))
+		}
+	}
+	var b strings.Builder
+	writeRow := func(row []string) {
+		line := make([]string, len(row))
+		for c, cell := range row {
+			align := byte('<')
+			if numeric[c] {
+				align = '>'
+			}
+			line[c] = alignText(cell, widths[c], align)
+		}
+		b.WriteString(strings.TrimRight(strings.Join(line, "  "), " "))
+		b.WriteByte('\n')
+	}
+	writeRow(header)
+	rule := make([]string, len(header))
+	for c, w := range widths {
+		rule[c] = strings.Repeat("-", w)
+	}
+	writeRow(rule)
+	for _, row := range cells {
+		writeRow(row)
+	}
+	if len(footer) > 0 {
+		writeRow(rule)
+		for _, row := range footer {
+			writeRow(row)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// records converts a slice of structs or of maps with string keys into one data map per element.
+// It also returns the fields of the records in display order: struct fields in declaration order,
+// map keys in the given order.
+func records(rows interface{}, order KeyOrder) ([]map[string]interface{}, []string, error) {
+	v := indirect(reflect.ValueOf(rows))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("cannot format %T as rows", rows)
+	}
+	var (
+		result []map[string]interface{}
+		keys   []string
+		seen   = make(map[string]bool)
+	)
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	structs := elem.Kind() == reflect.Struct
+	if structs {
+		// Take the columns from the type, so that they are known even if there are no rows.
+		_, fields, _ := record(reflect.New(elem).Elem())
+		for _, f := range fields {
+			seen[f] = true
+			keys = append(keys, f)
+		}
+	}
+	for n := 0; n < v.Len(); n++ {
+		row := indirect(v.Index(n))
+		m, fields, err := record(row)
+		if err != nil {
+			return nil, nil, err
+		}
+		structs = structs || row.Kind() == reflect.Struct
+		for _, f := range fields {
+			if !seen[f] {
+				seen[f] = true
+				keys = append(keys, f)
+			}
+		}
+		result = append(result, m)
+	}
+	if !structs {
+		order(keys)
+	}
+	return result, keys, nil
+}
+
+// record converts a struct or a map with string keys into a data map, also returning its fields.
+func record(v reflect.Value) (map[string]interface{}, []string, error) {
+	fields, ok := diffFields(v)
+	if !ok {
+		if !v.IsValid() {
+			return nil, nil, fmt.Errorf("cannot format nil as a row")
+		}
+		return nil, nil, fmt.Errorf("cannot format %s as a row", v.Type())
+	}
+	m := make(map[string]interface{}, len(fields))
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		m[f.name] = f.value.Interface()
+		names[i] = f.name
+	}
+	return m, names, nil
+}
+
+// isNumber reports whether value is of an integer or floating-point kind.
+func isNumber(value interface{}) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+package fstr
+
+import "testing"
+
+func TestTable(t *testing.T) {
+	type account struct {
+		Name    string  `fstr:"name"`
+		Balance float64 `fstr:"balance"`
+		Age     int
+		secret  string
+	}
+	accounts := []account{
+		{Name: "Alice", Balance: 1234.5, Age: 31},
+		{Name: "Bob", Balance: 12, Age: 4},
+	}
+	tests := []struct {
+		name    string
+		rows    interface{}
+		columns string
+		want    string
+	}{
+		{
+			name:    "Struct rows with column specs",
+			rows:    accounts,
+			columns: "{name} {balance:,.2f}",
+			want: "name    balance\n" +
+				"-----  --------\n" +
+				"Alice  1,234.50\n" +
+				"Bob       12.00",
+		},
+		{
+			name: "All struct fields",
+			rows: []*account{&accounts[0], &accounts[1]},
+			want: "name   balance  Age\n" +
+				"-----  -------  ---\n" +
+				"Alice   1234.5   31\n" +
+				"Bob         12    4",
+		},
+		{
+			name: "Map rows",
+			rows: []map[string]interface{}{
+				{"host": "web-1", "cpu": 0.5},
+				{"host": "db-1", "cpu": 12.25, "note": "primary"},
+			},
+			want: "  cpu  host   note\n" +
+				"-----  -----  -------\n" +
+				"  0.5  web-1\n" +
+				"12.25  db-1   primary",
+		},
+		{
+			name:    "Filters in columns",
+			rows:    accounts,
+			columns: "{name|kebab} {Age:03}",
+			want: "name   Age\n" +
+				"-----  ---\n" +
+				"alice  031\n" +
+				"bob    004",
+		},
+		{
+			name: "No rows",
+			rows: []account{},
+			want: "name  balance  Age\n" +
+				"----  -------  ---",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Table(tt.rows, tt.columns)
+			if err != nil {
+				t.Fatalf("Table() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Table() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+	got := Eval("Accounts:\n{rows:table}", map[string]interface{}{"rows": accounts})
+	want := "Accounts:\n" +
+		"name   balance  Age\n" +
+		"-----  -------  ---\n" +
+		"Alice   1234.5   31\n" +
+		"Bob         12    4"
+	if got != want {
+		t.Errorf("Eval() =\n%s\nwant\n%s", got, want)
+	}
+	if _, err := Table("not rows", ""); err == nil {
+		t.Errorf("Table() error = nil, want error")
+	}
+}
@@ -0,0 +1,95 @@
+package fstr
+
+import "strings"
+
+// Template is a compiled format string, parsed once and ready to be executed with different data.
+// Compile one with Compile or MustCompile; package-level template variables can then be declared
+// as tersely as with regexp.MustCompile:
+//
+//	var greeting = fstr.MustCompile("Hello {name}, your balance is {balance:,.2f}")
+//
+//	msg, err := greeting.Execute(map[string]interface{}{"name": "Ziad", "balance": 1234.5})
+//
+// A Template is safe for concurrent use by multiple goroutines.
+type Template struct {
+	interp *Interpolator
+	format string
+}
+
+// Compile parses a format string into a Template that interpolates with the default options.
+// It returns an *Error if the format string cannot be parsed.
+func Compile(format string) (*Template, error) {
+	return defaultInterpolator.Compile(format)
+}
+
+// MustCompile is like Compile but panics if the format string cannot be parsed.
+func MustCompile(format string) *Template {
+	return defaultInterpolator.MustCompile(format)
+}
+
+// Compile parses a format string into a Template that interpolates with the Interpolator's options.
+// It returns an *Error if the format string cannot be parsed.
+func (i *Interpolator) Compile(format string) (*Template, error) {
+	if _, err := i.parse(format, i.mode(false)); err != nil {
+		return nil, err
+	}
+	return &Template{interp: i, format: format}, nil
+}
+
+// MustCompile is like Compile but panics if the format string cannot be parsed.
+func (i *Interpolator) MustCompile(format string) *Template {
+	t, err := i.Compile(format)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Execute interpolates the template with data, like Interpolate.
+func (t *Template) Execute(data map[string]interface{}) (string, error) {
+	return t.interp.Interpolate(t.format, data)
+}
+
+// MustExecute is like Execute but panics with the *Error if one occurs.
+func (t *Template) MustExecute(data map[string]interface{}) string {
+	return t.interp.Eval(t.format, data)
+}
+
+// Append compiles a template rendering t followed by other, with t's options. Neither t nor other is
+// modified, so reusable pieces can be combined into messages:
+//
+//	var header = fstr.MustCompile("Dear {name},\n\n")
+//	var footer = fstr.MustCompile("\n\n-- {sender}")
+//
+//	reminder, err := fstr.Join(header, fstr.MustCompile("Your balance is {balance:,.2f}."), footer)
+//
+// It returns an *Error if the combined format string cannot be parsed.
+func (t *Template) Append(other *Template) (*Template, error) {
+	return t.interp.Compile(t.format + other.format)
+}
+
+// Join compiles a template rendering each of templates in turn, with the options of the first one.
+// Joining no templates yields a template rendering the empty string. It returns an *Error if the
+// combined format string cannot be parsed.
+func Join(templates ...*Template) (*Template, error) {
+	if len(templates) == 0 {
+		return &Template{interp: defaultInterpolator}, nil
+	}
+	var b strings.Builder
+	for _, t := range templates {
+		b.WriteString(t.format)
+	}
+	return templates[0].interp.Compile(b.String())
+}
+
+// Clone returns a copy of the template, from which variants can be derived with Append without
+// affecting the original.
+func (t *Template) Clone() *Template {
+	c := *t
+	return &c
+}
+
+// String returns the format string the template was compiled from.
+func (t *Template) String() string {
+	return t.format
+}
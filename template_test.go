@@ -0,0 +1,102 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+var greeting = MustCompile("Hello {name}, your balance is {balance:,.2f}")
+
+func TestTemplate(t *testing.T) {
+	got, err := greeting.Execute(map[string]interface{}{"name": "Ziad", "balance": 1234.5})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := "Hello Ziad, your balance is 1,234.50"; got != want {
+		t.Errorf("Execute() = %q, want %q", got, want)
+	}
+	if got := greeting.MustExecute(map[string]interface{}{"name": "Bob", "balance": 0}); got != "Hello Bob, your balance is 0.00" {
+		t.Errorf("MustExecute() = %q", got)
+	}
+	if got := greeting.String(); got != "Hello {name}, your balance is {balance:,.2f}" {
+		t.Errorf("String() = %q", got)
+	}
+	strict := New(WithStrictKeys()).MustCompile(greeting.String())
+	if _, err := strict.Execute(map[string]interface{}{"name": "Bob"}); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("Execute() error = %v, want ErrMissingKey", err)
+	}
+
+	interp := New(WithKeyOrder(FixedOrder("id")))
+	if got := interp.MustCompile("{*}").MustExecute(map[string]interface{}{"name": "a", "id": 1}); got != "id=1 name=a" {
+		t.Errorf("MustExecute() with options = %q", got)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	if _, err := Compile("{#each items}unclosed"); !errors.Is(err, ErrParse) {
+		t.Errorf("Compile() error = %v, want ErrParse", err)
+	}
+	panics := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s did not panic", name)
+			}
+		}()
+		fn()
+	}
+	panics("MustCompile()", func() { MustCompile("{#each items}unclosed") })
+	panics("MustExecute()", func() { greeting.MustExecute(nil) })
+}
+
+func TestTemplateComposition(t *testing.T) {
+	header := MustCompile("Dear {name},\n")
+	footer := MustCompile("\n-- {sender}")
+	body := MustCompile("Your balance is {balance:,.2f}.")
+	data := map[string]interface{}{"name": "Ziad", "balance": 1234.5, "sender": "Billing"}
+	want := "Dear Ziad,\nYour balance is 1,234.50.\n-- Billing"
+
+	withBody, err := header.Append(body)
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	reminder, err := withBody.Append(footer)
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if got := reminder.MustExecute(data); got != want {
+		t.Errorf("Append() = %q, want %q", got, want)
+	}
+	joined, err := Join(header, body, footer)
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if got := joined.MustExecute(data); got != want {
+		t.Errorf("Join() = %q, want %q", got, want)
+	}
+	if header.String() != "Dear {name},\n" || body.String() != "Your balance is {balance:,.2f}." {
+		t.Error("Append() modified its operands")
+	}
+	if empty, err := Join(); err != nil || empty.MustExecute(nil) != "" {
+		t.Errorf("Join() = %v, %v, want empty", empty, err)
+	}
+	block := MustCompile("{block title}Hi{/block}")
+	if _, err := block.Append(block); !errors.Is(err, ErrParse) {
+		t.Errorf("Append() error = %v, want ErrParse for a block defined twice", err)
+	}
+	if _, err := Join(body, block, block); !errors.Is(err, ErrParse) {
+		t.Errorf("Join() error = %v, want ErrParse for a block defined twice", err)
+	}
+
+	interp := New(WithKeyOrder(FixedOrder("id")))
+	variant, err := interp.MustCompile("{*}").Clone().Append(MustCompile("!"))
+	if err != nil {
+		t.Fatalf("Clone().Append() error = %v", err)
+	}
+	if got := variant.MustExecute(map[string]interface{}{"name": "a", "id": 1}); got != "id=1 name=a!" {
+		t.Errorf("Clone().Append() = %q, want the first template's options", got)
+	}
+	if c := greeting.Clone(); c == greeting || c.String() != greeting.String() {
+		t.Errorf("Clone() = %p %q, want a copy of %p", c, c, greeting)
+	}
+}
@@ -0,0 +1,176 @@
+package fstr
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultTerminalWidth is the width assumed when the terminal width cannot be detected.
+const defaultTerminalWidth = 80
+
+// terminalSize returns the width of the terminal open on fd, or false if fd is not a terminal. It is
+// ttyWidth, replaced by tests so that they do not depend on the terminal they run in.
+var terminalSize = ttyWidth
+
+// terminalWidth returns the width of the terminal attached to stdout. If stdout is not a terminal,
+// it falls back to the COLUMNS environment variable and then to fallback.
+func terminalWidth(fallback int) int {
+	if width, ok := terminalSize(os.Stdout.Fd()); ok && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return fallback
+}
+
+// isTerminal reports whether fd is a terminal.
+func isTerminal(fd uintptr) bool {
+	_, ok := terminalSize(fd)
+	return ok
+}
+
+// alignText pads s with spaces to width runes: align is '<' (left), '>' (right) or '^' (centre).
+// s is returned unchanged if it is already at least as wide.
+func alignText(s string, width int, align byte) string {
+	n := width - utf8.RuneCountInString(s)
+	if n <= 0 {
+		return s
+	}
+	fill := strings.Repeat(" ", n)
+	switch align {
+	case '<':
+		return s + fill
+	case '^':
+		return fill[:n/2] + s + fill[n/2:]
+	default:
+		return fill + s
+	}
+}
+
+// alignTerm aligns a value across the terminal width, as in {title:^term}.
+func (i *Interpolator) alignTerm(value interface{}, align string) string {
+	return alignText(fmt.Sprint(value), terminalWidth(i.fallbackWidth), align[0])
+}
+
+// center centres a value across the given width, or across the terminal width if none is given:
+// {title|center} or {title|center(40)}.
+func (i *Interpolator) center(value interface{}, width ...string) (string, error) {
+	w := terminalWidth(i.fallbackWidth)
+	if len(width) > 0 {
+		var err error
+		if w, err = strconv.Atoi(width[0]); err != nil || w < 0 || w > maxSpecWidth {
+			return "", &Error{Spec: "center", Err: fmt.Errorf("invalid width %q", width[0]), kind: ErrBadSpec}
+		}
+	}
+	return alignText(fmt.Sprint(value), w, '^'), nil
+}
+
+// columns lays out the elements of a slice in aligned columns, filled top to bottom like the output
+// of ls: {files|columns(3)}. Without a count, {files|columns} uses as many columns as fit across the
+// terminal width. Columns are separated by two spaces.
+func (i *Interpolator) columns(value interface{}, count ...string) (string, error) {
+	v := indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", &Error{Spec: "columns", Err: fmt.Errorf("cannot format %T as columns", value), kind: ErrUnsupportedType}
+	}
+	items := make([]string, v.Len())
+	for n := range items {
+		items[n] = fmt.Sprint(v.Index(n).Interface())
+	}
+	if len(count) > 0 {
+		n, err := strconv.Atoi(count[0])
+		if err != nil || n < 1 {
+			return "", &Error{Spec: "columns", Err: fmt.Errorf("invalid column count %q", count[0]), kind: ErrBadSpec}
+		}
+		// More columns than items would only be empty.
+		return layoutColumns(items, min(n, len(items))), nil
+	}
+	width := terminalWidth(i.fallbackWidth)
+	for n := len(items); n > 1; n-- {
+		if s := layoutColumns(items, n); maxLineWidth(s) <= width {
+			return s, nil
+		}
+	}
+	return layoutColumns(items, 1), nil
+}
+
+// layoutColumns arranges items in n columns, filled top to bottom.
+func layoutColumns(items []string, n int) string {
+	if len(items) == 0 {
+		return ""
+	}
+	rows := (len(items) + n - 1) / n
+	widths := make([]int, n)
+	for k, item := range items {
+		widths[k/rows] = max(widths[k/rows], visibleWidth(item))
+	}
+	lines := make([]string, rows)
+	for r := range lines {
+		var cells []string
+		for c := 0; c < n && c*rows+r < len(items); c++ {
+			item := items[c*rows+r]
+			cells = append(cells, item+strings.Repeat(" ", widths[c]-visibleWidth(item)))
+		}
+		lines[r] = strings.TrimRight(strings.Join(cells, "  "), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maxLineWidth returns the visible width of the longest line of s.
+func maxLineWidth(s string) int {
+	width := 0
+	for _, line := range strings.Split(s, "\n") {
+		width = max(width, visibleWidth(line))
+	}
+	return width
+}
+
+// wrapText soft-wraps each line of s at width columns, breaking only at spaces. The spaces at a
+// break are dropped; a word wider than width is kept whole on its own line.
+func wrapText(s string, width int) string {
+	lines := strings.Split(s, "\n")
+	for n, line := range lines {
+		var (
+			b       strings.Builder
+			used    int    // visible width of the current output line
+			started bool   // whether the current output line holds a word
+			spaces  string // spaces seen since the last word
+		)
+		for _, token := range wordsAndSpaces(line) {
+			if token[0] == ' ' {
+				spaces = token
+				continue
+			}
+			w := visibleWidth(token)
+			if started && used+len(spaces)+w > width {
+				b.WriteByte('\n')
+				used, spaces = 0, ""
+			}
+			b.WriteString(spaces + token)
+			used += len(spaces) + w
+			started, spaces = true, ""
+		}
+		lines[n] = b.String() + spaces
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wordsAndSpaces splits a line into alternating runs of spaces and of other characters.
+func wordsAndSpaces(line string) []string {
+	var tokens []string
+	for line != "" {
+		space := line[0] == ' '
+		end := strings.IndexFunc(line, func(r rune) bool { return (r == ' ') != space })
+		if end < 0 {
+			end = len(line)
+		}
+		tokens = append(tokens, line[:end])
+		line = line[end:]
+	}
+	return tokens
+}
@@ -0,0 +1,8 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package fstr
+
+// ttyWidth reports false: terminal size detection is not supported on this platform.
+func ttyWidth(fd uintptr) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,149 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+// setTerminal makes the tests of t see stdout and every other file as a terminal of the given width,
+// or as no terminal if width is 0.
+func setTerminal(t *testing.T, width int) {
+	saved := terminalSize
+	terminalSize = func(uintptr) (int, bool) { return width, width > 0 }
+	t.Cleanup(func() { terminalSize = saved })
+}
+
+func TestTerminalAlignment(t *testing.T) {
+	setTerminal(t, 0)
+	tests := []struct {
+		name    string
+		columns string
+		format  string
+		want    string
+	}{
+		{name: "Centre with fallback width", format: "[{t:^term}]", want: "[   fstr   ]"},
+		{name: "Right with fallback width", format: "[{t:>term}]", want: "[      fstr]"},
+		{name: "Left with fallback width", format: "[{t:<term}]", want: "[fstr      ]"},
+		{name: "Centre with COLUMNS", columns: "8", format: "[{t:^term}]", want: "[  fstr  ]"},
+		{name: "Centre filter", format: "[{t|center}]", want: "[   fstr   ]"},
+		{name: "Centre filter with width", format: "[{t|center(6)}]", want: "[ fstr ]"},
+		{name: "Wider than the terminal", format: "[{t|center(2)}]", want: "[fstr]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COLUMNS", tt.columns)
+			got, err := New(WithFallbackWidth(10)).Interpolate(tt.format, map[string]interface{}{"t": "fstr"})
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	// The width of a terminal on stdout wins over COLUMNS.
+	setTerminal(t, 12)
+	t.Setenv("COLUMNS", "8")
+	if got, want := New().Eval("[{t:^term}]", map[string]interface{}{"t": "fstr"}), "[    fstr    ]"; got != want {
+		t.Errorf("Interpolate() in a terminal = %q, want %q", got, want)
+	}
+	for _, format := range []string{"{t|center(-1)}", "{t|center(100000000000)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"t": "fstr"}); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("Interpolate(%q) error = %v, want ErrBadSpec", format, err)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	setTerminal(t, 0)
+	files := []string{"README.md", "go.mod", "fstr.go", "format.go", "table.go", "text.go", "x"}
+	tests := []struct {
+		name    string
+		format  string
+		columns string
+		want    string
+	}{
+		{
+			name:   "Three columns",
+			format: "{files|columns(3)}",
+			want:   "README.md  format.go  x\ngo.mod     table.go\nfstr.go    text.go",
+		},
+		{
+			name:   "One column",
+			format: "{files|columns(1)}",
+			want:   "README.md\ngo.mod\nfstr.go\nformat.go\ntable.go\ntext.go\nx",
+		},
+		{
+			name:   "More columns than items",
+			format: "{files|columns(100000000000)}",
+			want:   "README.md  go.mod  fstr.go  format.go  table.go  text.go  x",
+		},
+		{
+			name:    "Fit the terminal width",
+			format:  "{files|columns}",
+			columns: "40",
+			want:    "README.md  fstr.go    table.go  x\ngo.mod     format.go  text.go",
+		},
+		{
+			name:    "Narrow terminal",
+			format:  "{files|columns}",
+			columns: "5",
+			want:    "README.md\ngo.mod\nfstr.go\nformat.go\ntable.go\ntext.go\nx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COLUMNS", tt.columns)
+			got, err := New(WithFallbackWidth(10)).Interpolate(tt.format, map[string]interface{}{"files": files})
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+	for _, format := range []string{"{files|columns(0)}", "{name|columns(2)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"files": files, "name": "x"}); err == nil {
+			t.Errorf("Interpolate(%q) error = nil, want error", format)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{name: "Fits", s: "short line", width: 20, want: "short line"},
+		{name: "Break between words", s: "the quick brown fox jumps", width: 10, want: "the quick\nbrown fox\njumps"},
+		{name: "Exact width", s: "aaaa bbbb", width: 9, want: "aaaa bbbb"},
+		{name: "Long word kept whole", s: "a supercalifragilistic word", width: 8, want: "a\nsupercalifragilistic\nword"},
+		{name: "Existing lines", s: "one two\nthree four", width: 7, want: "one two\nthree\nfour"},
+		{name: "Indentation kept", s: "  indented text here", width: 12, want: "  indented\ntext here"},
+		{name: "ANSI sequences take no width", s: "\x1b[31mred\x1b[0m and \x1b[1mbold\x1b[0m", width: 8, want: "\x1b[31mred\x1b[0m and\n\x1b[1mbold\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.s, tt.width); got != tt.want {
+				t.Errorf("wrapText(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithWrap(t *testing.T) {
+	setTerminal(t, 0)
+	t.Setenv("COLUMNS", "")
+	if got, want := New(WithWrap(0), WithFallbackWidth(10)).wrapped("the quick brown fox"), "the quick\nbrown fox"; got != want {
+		t.Errorf("wrapped() at the terminal width = %q, want %q", got, want)
+	}
+	if got, want := New(WithWrap(5)).wrapped("the quick brown fox"), "the\nquick\nbrown\nfox"; got != want {
+		t.Errorf("wrapped() at width 5 = %q, want %q", got, want)
+	}
+	if got, want := New().wrapped("the quick brown fox"), "the quick brown fox"; got != want {
+		t.Errorf("wrapped() without WithWrap = %q, want %q", got, want)
+	}
+}
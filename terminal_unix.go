@@ -0,0 +1,19 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package fstr
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// ttyWidth returns the width of the terminal open on fd, or false if fd is not a terminal.
+func ttyWidth(fd uintptr) (int, bool) {
+	var ws struct {
+		row, col, xpixel, ypixel uint16
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0, false
+	}
+	return int(ws.col), true
+}
@@ -0,0 +1,159 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ellipsis is appended to truncated text.
+const ellipsis = "…"
+
+// truncate shortens text to at most n runes, including the trailing "…" it appends when it cuts
+// anything: {text|truncate(10)} renders "Hello, wonderful world" as "Hello, wo…".
+// With the "word" mode, {text|truncate(10, word)}, it cuts at the last word boundary instead,
+// rendering "Hello,…", unless the first word alone is too long.
+func truncate(value interface{}, args ...string) (string, error) {
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return "", &Error{Spec: "truncate", Err: fmt.Errorf("invalid length %q", args[0]), kind: ErrBadSpec}
+	}
+	byWord := false
+	if len(args) > 1 {
+		if args[1] != "word" {
+			return "", &Error{Spec: "truncate", Err: fmt.Errorf("unknown mode %q", args[1]), kind: ErrBadSpec}
+		}
+		byWord = true
+	}
+	rs := []rune(fmt.Sprint(value))
+	if len(rs) <= n {
+		return string(rs), nil
+	}
+	cut := rs[:n-1]
+	if byWord {
+		for i := len(cut); i > 0; i-- {
+			if unicode.IsSpace(rs[i]) {
+				cut = rs[:i]
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(cut), unicode.IsSpace) + ellipsis, nil
+}
+
+// indent prefixes every non-empty line of text with n spaces, for embedding multi-line values such
+// as YAML or code into an indented block: {block|indent(4)}.
+func indent(value interface{}, n string) (string, error) {
+	width, err := strconv.Atoi(n)
+	if err != nil || width < 0 || width > maxSpecWidth {
+		return "", &Error{Spec: "indent", Err: fmt.Errorf("invalid width %q", n), kind: ErrBadSpec}
+	}
+	return indentLines(fmt.Sprint(value), width), nil
+}
+
+// nindent is like indent but starts with a newline, so that the block can follow a key on the
+// same line of the template: "spec:{block|nindent(2)}".
+func nindent(value interface{}, n string) (string, error) {
+	width, err := strconv.Atoi(n)
+	if err != nil || width < 0 || width > maxSpecWidth {
+		return "", &Error{Spec: "nindent", Err: fmt.Errorf("invalid width %q", n), kind: ErrBadSpec}
+	}
+	return "\n" + indentLines(fmt.Sprint(value), width), nil
+}
+
+// indentLines prefixes every non-empty line of s with width spaces.
+func indentLines(s string, width int) string {
+	pad := strings.Repeat(" ", width)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quote renders a value as a double-quoted Go string literal, escaping quotes, control characters
+// and invalid UTF-8 like the %q verb: {s:q} renders `say "hi"` as "say \"hi\"".
+// Non-string values are quoted as they would otherwise be rendered.
+func quote(value interface{}) string {
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+// maxRepeatCount is the largest number of times repeat may render a value.
+const maxRepeatCount = 10000
+
+// repeat renders a value n times in a row, for separators and underlines: {"-"|repeat(60)}.
+// The count is at most 10000.
+func repeat(value interface{}, n string) (string, error) {
+	count, err := strconv.Atoi(n)
+	if err != nil || count < 0 || count > maxRepeatCount {
+		return "", &Error{Spec: "repeat", Err: fmt.Errorf("invalid count %q", n), kind: ErrBadSpec}
+	}
+	return strings.Repeat(fmt.Sprint(value), count), nil
+}
+
+// trim removes leading and trailing whitespace, or, given a cutset, any of its characters:
+// {s|trim} or {s|trim("[]")}.
+func trim(value interface{}, cutset ...string) string {
+	if len(cutset) == 0 {
+		return strings.TrimSpace(fmt.Sprint(value))
+	}
+	return strings.Trim(fmt.Sprint(value), cutset[0])
+}
+
+// trimSpace removes leading and trailing whitespace: {s|trimSpace}.
+func trimSpace(value interface{}) string {
+	return strings.TrimSpace(fmt.Sprint(value))
+}
+
+// trimPrefix removes a leading prefix, if present: {ref|trimPrefix("refs/heads/")}.
+func trimPrefix(value interface{}, prefix string) string {
+	return strings.TrimPrefix(fmt.Sprint(value), prefix)
+}
+
+// trimSuffix removes a trailing suffix, if present: {file|trimSuffix(".go")}.
+func trimSuffix(value interface{}, suffix string) string {
+	return strings.TrimSuffix(fmt.Sprint(value), suffix)
+}
+
+// humanjoin joins the elements of a slice into a list for running text: {names|humanjoin} renders
+// "Alice, Bob and Carol". The first argument replaces the conjunction, as in {names|humanjoin(or)},
+// and the "oxford" mode adds a serial comma before it: {names|humanjoin(and, oxford)} renders
+// "Alice, Bob, and Carol". A value that is not a slice is rendered as is.
+func humanjoin(value interface{}, args ...string) (string, error) {
+	conjunction, oxford := "and", false
+	if len(args) > 0 {
+		conjunction = args[0]
+	}
+	if len(args) > 1 {
+		if args[1] != "oxford" {
+			return "", &Error{Spec: "humanjoin", Err: fmt.Errorf("unknown mode %q", args[1]), kind: ErrBadSpec}
+		}
+		oxford = true
+	}
+	v := indirect(reflect.ValueOf(value))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fmt.Sprint(value), nil
+	}
+	items := make([]string, v.Len())
+	for n := range items {
+		items[n] = fmt.Sprint(v.Index(n).Interface())
+	}
+	switch len(items) {
+	case 0:
+		return "", nil
+	case 1:
+		return items[0], nil
+	case 2:
+		return items[0] + " " + conjunction + " " + items[1], nil
+	}
+	last := len(items) - 1
+	list := strings.Join(items[:last], ", ")
+	if oxford {
+		list += ","
+	}
+	return list + " " + conjunction + " " + items[last], nil
+}
@@ -0,0 +1,179 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIndent(t *testing.T) {
+	block := "name: web\nports:\n  - 80\n\n  - 443"
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{block|indent(4)}", want: "    name: web\n    ports:\n      - 80\n\n      - 443"},
+		{format: "spec:{block|nindent(2)}", want: "spec:\n  name: web\n  ports:\n    - 80\n\n    - 443"},
+		{format: "{block|indent(0)}", want: block},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"block": block})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	for _, format := range []string{"{block|nindent(-1)}", "{block|indent(100000000000)}", "{block|nindent(10001)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"block": block}); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("Interpolate(%q) error = %v, want ErrBadSpec", format, err)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		format string
+		text   string
+		want   string
+	}{
+		{format: "{text|truncate(10)}", text: "Hello, wonderful world", want: "Hello, wo…"},
+		{format: "{text|truncate(10, word)}", text: "Hello, wonderful world", want: "Hello,…"},
+		{format: "{text|truncate(22)}", text: "Hello, wonderful world", want: "Hello, wonderful world"},
+		{format: "{text|truncate(5)}", text: "héllo wörld", want: "héll…"},
+		{format: "{text|truncate(7)}", text: "Hello, world", want: "Hello,…"},
+		{format: "{text|truncate(6, word)}", text: "Supercalifragilistic", want: "Super…"},
+		{format: "{text|truncate(1)}", text: "abc", want: "…"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"text": tt.text})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %q) error = %v", tt.format, tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %q) = %q, want %q", tt.format, tt.text, got, tt.want)
+		}
+	}
+	for _, format := range []string{"{text|truncate(0)}", "{text|truncate(x)}", "{text|truncate(3, line)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"text": "abcdef"}); err == nil {
+			t.Errorf("Interpolate(%q) error = nil, want error", format)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{value: "hello", want: `"hello"`},
+		{value: `say "hi"`, want: `"say \"hi\""`},
+		{value: "line\nbreak\ttab", want: `"line\nbreak\ttab"`},
+		{value: "\x00bad\xff", want: `"\x00bad\xff"`},
+		{value: 42, want: `"42"`},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{s:q}", map[string]interface{}{"s": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: `{"-"|repeat(5)}`, want: "-----"},
+		{format: `{"=\t"|repeat(2)}`, want: "=\t=\t"},
+		{format: "{title}\n{char|repeat(5)}", want: "Title\n~~~~~"},
+		{format: "{char|repeat(0)}", want: ""},
+		{format: `{"ab"|repeat(2)|center(8)}`, want: "  abab  "},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"title": "Title", "char": "~"})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	for _, format := range []string{"{char|repeat(-1)}", `{"-"|repeat(100000000000)}`, "{char|repeat(10001)}"} {
+		if _, err := Interpolate(format, map[string]interface{}{"char": "~"}); !errors.Is(err, ErrBadSpec) {
+			t.Errorf("Interpolate(%q) error = %v, want ErrBadSpec", format, err)
+		}
+	}
+}
+
+func TestTrimFilters(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		want   string
+	}{
+		{format: "[{s|trim}]", value: " \t padded \n", want: "[padded]"},
+		{format: "[{s|trimSpace}]", value: " \t padded \n", want: "[padded]"},
+		{format: `[{s|trim("[]")}]`, value: "[[wrapped]]", want: "[wrapped]"},
+		{format: `{s|trimPrefix("refs/heads/")}`, value: "refs/heads/main", want: "main"},
+		{format: `{s|trimSuffix(".go")}`, value: "fstr.go", want: "fstr"},
+		{format: `{s|trimSuffix(".go")}`, value: "README.md", want: "README.md"},
+		{format: `{s|trimSpace|trimPrefix("v")}`, value: " v1.2.3 ", want: "1.2.3"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"s": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestHumanJoin(t *testing.T) {
+	data := map[string]interface{}{
+		"three": []string{"Alice", "Bob", "Carol"},
+		"two":   []string{"Alice", "Bob"},
+		"one":   []string{"Alice"},
+		"none":  []string{},
+		"ids":   []int{1, 2, 3, 4},
+		"name":  "Alice",
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "{three|humanjoin}", want: "Alice, Bob and Carol"},
+		{format: "{three|humanjoin(or)}", want: "Alice, Bob or Carol"},
+		{format: "{three|humanjoin(and, oxford)}", want: "Alice, Bob, and Carol"},
+		{format: `{three|humanjoin("&")}`, want: "Alice, Bob & Carol"},
+		{format: "{two|humanjoin(and, oxford)}", want: "Alice and Bob"},
+		{format: "{one|humanjoin}", want: "Alice"},
+		{format: "[{none|humanjoin}]", want: "[]"},
+		{format: "{ids|humanjoin}", want: "1, 2, 3 and 4"},
+		{format: "{name|humanjoin}", want: "Alice"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, data)
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if _, err := Interpolate("{three|humanjoin(and, harvard)}", data); err == nil {
+		t.Errorf("Interpolate() error = nil, want error")
+	}
+}
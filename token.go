@@ -0,0 +1,83 @@
+package fstr
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// token formats a crypto asset amount stored as an integer count of its smallest unit, such as wei:
+// {wei:token(18, ETH)} renders 1500000000000000000 as "1.5 ETH". The first argument is the number of
+// decimals of the token, and the optional second argument its symbol, rendered after the amount:
+// {wei:token(18)} renders "1.5" alone. Insignificant zeros are trimmed, and the integer part is grouped
+// by thousands, with the Interpolator's locale.
+//
+// The amount may be a *big.Int, a value of any integer type, or a string of decimal digits, or of
+// hexadecimal digits after a 0x prefix, as returned by JSON-RPC APIs: "0100" is one hundred. It is
+// never converted to float64, so every digit of an 18-decimal amount is kept.
+func (i *Interpolator) token(loc *numberSymbols, value interface{}, decimals string, symbol ...string) (string, error) {
+	d, err := strconv.Atoi(decimals)
+	if err != nil || d < 0 || d > 77 {
+		return "", &Error{Spec: "token", Err: fmt.Errorf("invalid decimals %q", decimals), kind: ErrBadSpec}
+	}
+	amount, err := toBigInt(value)
+	if err != nil {
+		return "", &Error{Spec: "token", Err: err, kind: ErrUnsupportedType}
+	}
+	s := loc.localize(groupThousands(scaleDecimal(amount, d), ","))
+	if len(symbol) > 0 && symbol[0] != "" {
+		s += " " + symbol[0]
+	}
+	return s, nil
+}
+
+// toBigInt converts an integer value, a *big.Int, or a string of decimal digits or of hexadecimal
+// digits after a 0x or 0X prefix to a *big.Int.
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil, fmt.Errorf("cannot format a nil %T", v)
+		}
+		return v, nil
+	case string:
+		digits, base := v, 10
+		if strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X") {
+			digits, base = v[2:], 16
+		}
+		n, ok := new(big.Int).SetString(digits, base)
+		if !ok || base == 16 && strings.IndexAny(digits, "+-") == 0 {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return n, nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(v.Uint()), nil
+	default:
+		return nil, fmt.Errorf("cannot format %T as a token amount", value)
+	}
+}
+
+// scaleDecimal returns n divided by 10^decimals in plain decimal notation, without trailing zeros:
+// 1500 with 3 decimals => "1.5".
+func scaleDecimal(n *big.Int, decimals int) string {
+	s := n.String()
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	whole, fraction := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
@@ -0,0 +1,52 @@
+package fstr
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestToken(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567", 10)
+	tests := []struct {
+		format string
+		amount interface{}
+		want   string
+	}{
+		{format: "{wei:token(18, ETH)}", amount: big.NewInt(1500000000000000000), want: "1.5 ETH"},
+		{format: "{wei:token(18)}", amount: big.NewInt(1500000000000000000), want: "1.5"},
+		{format: "{wei:token(18, ETH)}", amount: huge, want: "123,456,789.012345678901234567 ETH"},
+		{format: "{wei:token(18)}", amount: "1000000000000000000", want: "1"},
+		{format: "{wei:token(18)}", amount: "0x14d1120d7b160000", want: "1.5"},
+		{format: "{wei:token(18)}", amount: "0X14D1120D7B160000", want: "1.5"},
+		{format: "{wei:token(2)}", amount: "0100", want: "1"},
+		{format: "{wei:token(18)}", amount: uint64(0), want: "0"},
+		{format: "{wei:token(18)}", amount: -5, want: "-0.000000000000000005"},
+		{format: "{wei:token(6, USDC)}", amount: 12500000, want: "12.5 USDC"},
+		{format: "{wei:token(0, SAT)}", amount: 2100, want: "2,100 SAT"},
+		{format: "{wei:,}", amount: huge, want: "123,456,789,012,345,678,901,234,567"},
+		{format: "{wei:.2e}", amount: huge, want: "1.23e+26"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"wei": tt.amount})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.amount, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.amount, got, tt.want)
+		}
+	}
+	got, err := New(WithLocale("de")).Interpolate("{wei:token(18, ETH)}", map[string]interface{}{"wei": huge})
+	if want := "123.456.789,012345678901234567 ETH"; err != nil || got != want {
+		t.Errorf("Interpolate(de) = %q, %v, want %q", got, err, want)
+	}
+	for _, amount := range []interface{}{1.5, "1.5", (*big.Int)(nil), "0B101", "0b101", "0O17", "0o17", "1_000", "0x", "0x-5", "0x1_0"} {
+		if _, err := Interpolate("{wei:token(18)}", map[string]interface{}{"wei": amount}); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Interpolate(%v) error = %v, want ErrUnsupportedType", amount, err)
+		}
+	}
+	if _, err := Interpolate("{wei:token(x)}", map[string]interface{}{"wei": 1}); !errors.Is(err, ErrBadSpec) {
+		t.Errorf("Interpolate(token(x)) error = %v, want ErrBadSpec", err)
+	}
+}
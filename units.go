@@ -0,0 +1,99 @@
+package fstr
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+// conversionNameRe matches the name of a unit conversion filter.
+var conversionNameRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+
+// templateBuiltins lists the functions text/template predefines.
+var templateBuiltins = map[string]bool{
+	"and": true, "call": true, "html": true, "index": true, "slice": true, "js": true, "len": true,
+	"not": true, "or": true, "print": true, "printf": true, "println": true, "urlquery": true,
+	"eq": true, "ge": true, "gt": true, "le": true, "lt": true, "ne": true,
+}
+
+// conversions lists the unit conversion filters by name, built-in and registered with RegisterConversion.
+var conversions = struct {
+	sync.RWMutex
+	fns map[string]func(float64) float64
+}{fns: map[string]func(float64) float64{
+	"c2f":   func(c float64) float64 { return c*9/5 + 32 },
+	"f2c":   func(f float64) float64 { return (f - 32) * 5 / 9 },
+	"km2mi": func(km float64) float64 { return km / 1.609344 },
+	"mi2km": func(mi float64) float64 { return mi * 1.609344 },
+	"m2ft":  func(m float64) float64 { return m / 0.3048 },
+	"ft2m":  func(ft float64) float64 { return ft * 0.3048 },
+	"cm2in": func(cm float64) float64 { return cm / 2.54 },
+	"in2cm": func(in float64) float64 { return in * 2.54 },
+	"kg2lb": func(kg float64) float64 { return kg / 0.45359237 },
+	"lb2kg": func(lb float64) float64 { return lb * 0.45359237 },
+	"l2gal": func(l float64) float64 { return l / 3.785411784 },
+	"gal2l": func(gal float64) float64 { return gal * 3.785411784 },
+}}
+
+// RegisterConversion registers the unit conversion filter name, or replaces it, so that display units
+// are chosen in templates rather than by arithmetic scattered through the code:
+//
+//	fstr.RegisterConversion("kn2kmh", func(kn float64) float64 { return kn * 1.852 })
+//	fstr.Eval("Wind: {wind|kn2kmh:.0f} km/h", data)
+//
+// The built-in conversions are c2f and f2c (temperatures), km2mi, mi2km, m2ft, ft2m, cm2in and in2cm
+// (lengths), kg2lb and lb2kg (masses), and l2gal and gal2l (US gallons). Conversions apply to values of
+// any numeric type and return a float64, usually formatted with a spec such as {temp|c2f:.1f}.
+//
+// Format strings already parsed keep the conversions they were parsed with. RegisterConversion returns
+// an ErrParse *Error if name is not an identifier, is the name of another filter or template function,
+// or if fn is nil.
+func RegisterConversion(name string, fn func(float64) float64) error {
+	if !conversionNameRe.MatchString(name) {
+		return &Error{Err: fmt.Errorf("invalid conversion name %q", name), kind: ErrParse}
+	}
+	if fn == nil {
+		return &Error{Err: fmt.Errorf("conversion %q has no function", name), kind: ErrParse}
+	}
+	if _, ok := filters[name]; ok {
+		return &Error{Err: fmt.Errorf("conversion %q conflicts with a filter", name), kind: ErrParse}
+	}
+	// The template functions include the conversions, which can be replaced.
+	funcs := (&Interpolator{}).funcs(renderMode{})
+	conversions.Lock()
+	defer conversions.Unlock()
+	if _, ok := conversions.fns[name]; !ok && funcs[name] != nil || templateBuiltins[name] {
+		return &Error{Err: fmt.Errorf("conversion %q conflicts with a template function", name), kind: ErrParse}
+	}
+	conversions.fns[name] = fn
+	return nil
+}
+
+// lookupFilter returns the filter name: a filter of the filters table or a unit conversion.
+func lookupFilter(name string) (filterDef, bool) {
+	if def, ok := filters[name]; ok {
+		return def, true
+	}
+	conversions.RLock()
+	_, ok := conversions.fns[name]
+	conversions.RUnlock()
+	return filterDef{kind: KindNumber}, ok
+}
+
+// conversionFuncs returns the template functions of the unit conversions.
+func conversionFuncs() map[string]interface{} {
+	conversions.RLock()
+	defer conversions.RUnlock()
+	funcs := make(map[string]interface{}, len(conversions.fns))
+	for name, fn := range conversions.fns {
+		name, fn := name, fn
+		funcs[name] = func(value interface{}) (float64, error) {
+			if !isNumber(value) {
+				return 0, &Error{Spec: name, Err: fmt.Errorf("cannot format %T as a number", value), kind: ErrUnsupportedType}
+			}
+			return fn(reflect.ValueOf(value).Convert(reflect.TypeOf(float64(0))).Float()), nil
+		}
+	}
+	return funcs
+}
@@ -0,0 +1,63 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		format string
+		value  interface{}
+		want   string
+	}{
+		{format: "{v|c2f:.1f}", value: 21.5, want: "70.7"},
+		{format: "{v|f2c:.1f}", value: 98.6, want: "37.0"},
+		{format: "{v|km2mi:.2f}", value: 10, want: "6.21"},
+		{format: "{v|mi2km:.3f}", value: uint8(1), want: "1.609"},
+		{format: "{v|kg2lb:,.1f}", value: 1000, want: "2,204.6"},
+		{format: "{v|lb2kg:.2f}", value: float32(10), want: "4.54"},
+		{format: "{v|m2ft:.0f} ft", value: 8849, want: "29032 ft"},
+		{format: "{v|in2cm}", value: 2, want: "5.08"},
+		{format: "{v|c2f}", value: 100, want: "212"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"v": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%q, %v) error = %v", tt.format, tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q, %v) = %q, want %q", tt.format, tt.value, got, tt.want)
+		}
+	}
+	if _, err := Interpolate("{v|c2f}", map[string]interface{}{"v": "hot"}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("Interpolate(string) error = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestRegisterConversion(t *testing.T) {
+	if err := RegisterConversion("kn2kmh", func(kn float64) float64 { return kn * 1.852 }); err != nil {
+		t.Fatalf("RegisterConversion() error = %v", err)
+	}
+	got, err := Interpolate("Wind: {wind|kn2kmh:.0f} km/h", map[string]interface{}{"wind": 12})
+	if want := "Wind: 22 km/h"; err != nil || got != want {
+		t.Errorf("Interpolate() = %q, %v, want %q", got, err, want)
+	}
+	reqs, _ := Requirements("{wind|kn2kmh}")
+	if len(reqs) != 1 || reqs[0].Kind != KindNumber {
+		t.Errorf("Requirements() = %+v, want one number", reqs)
+	}
+	for _, name := range []string{"snake", "2x", "kn-kmh", "", "money", "formatNumber", "partial", "kv", "printf", "len", "eq"} {
+		if err := RegisterConversion(name, func(f float64) float64 { return f }); !errors.Is(err, ErrParse) {
+			t.Errorf("RegisterConversion(%q) error = %v, want ErrParse", name, err)
+		}
+	}
+	if err := RegisterConversion("nilfn", nil); !errors.Is(err, ErrParse) {
+		t.Errorf("RegisterConversion(nil) error = %v, want ErrParse", err)
+	}
+	// Conversions, built-in ones included, can be replaced.
+	if err := RegisterConversion("kn2kmh", func(kn float64) float64 { return kn * 1.852 }); err != nil {
+		t.Errorf("RegisterConversion() again error = %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package fstr
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// URL interpolates a URL template, escaping every value for its part of the URL. See Interpolator.URL.
+func URL(format string, data map[string]interface{}) (string, error) {
+	return defaultInterpolator.URL(format, data)
+}
+
+// URL interpolates format, a URL template, with data, percent-encoding the output of each placeholder
+// for the part of the URL it appears in, so that API clients need no url.PathEscape around every value:
+//
+//	u, err := fstr.URL("https://api.example.com/users/{id}/files/{name}?q={query}", map[string]interface{}{
+//		"id": 42, "name": "Q1 report/final.pdf", "query": "a&b=c",
+//	})
+//	// u: https://api.example.com/users/42/files/Q1%20report%2Ffinal.pdf?q=a%26b%3Dc
+//
+// Values in the path and fragment are escaped as single path segments, '/' included, and values after
+// the '?' of the format string as query components, except for whole query strings rendered by the
+// query spec, as in "/search?{params:query}". The format string's own text is kept as it is.
+// An *Error is returned if a placeholder fails to render or the result is not a valid URL. That
+// includes values making a path segment of "." or "..", which would send the request to another
+// path, and placeholders rendering the scheme and host, as in "{base}/users/{id}", whose slashes
+// are escaped like any other.
+func (i *Interpolator) URL(format string, data map[string]interface{}) (string, error) {
+	if _, err := i.parse(format, i.mode(false)); err != nil {
+		return "", err
+	}
+	data = i.resolveMissing(format, data)
+	// dotValue is a path value of "." or "..", written to b at start.
+	type dotValue struct {
+		start       int
+		placeholder string
+	}
+	var (
+		b    strings.Builder
+		part = urlPath
+		dots []dotValue
+	)
+	for _, seg := range segments(format) {
+		if !seg.fragment || tokenRe.FindString(seg.text) == seg.text && !isTag(seg.text) {
+			// Text, or a placeholder rendered as written.
+			b.WriteString(seg.text)
+			part = part.after(seg.text)
+			continue
+		}
+		s, err := i.render(seg.text, data, i.mode(false))
+		if err != nil {
+			var e *Error
+			if errors.As(err, &e) {
+				e.Format = format
+			}
+			return "", err
+		}
+		if part == urlPath && (s == "." || s == "..") {
+			dots = append(dots, dotValue{start: b.Len(), placeholder: seg.text})
+		}
+		if p, ok := parsePlaceholder(seg.text); ok && p.spec == "query" {
+			b.WriteString(s) // already encoded
+		} else if part == urlQuery {
+			b.WriteString(url.QueryEscape(s))
+		} else {
+			b.WriteString(url.PathEscape(s))
+		}
+	}
+	for _, d := range dots {
+		// A dot value in a path segment of only dots would move the request to another path.
+		out := b.String()
+		start := strings.LastIndexByte(out[:d.start], '/') + 1
+		end := len(out)
+		if n := strings.IndexAny(out[d.start:], "/?#"); n >= 0 {
+			end = d.start + n
+		}
+		if segment := out[start:end]; segment == "." || segment == ".." {
+			return "", &Error{Format: format, Placeholder: d.placeholder, Err: fmt.Errorf("invalid URL: path segment %q", segment)}
+		}
+	}
+	u, err := url.Parse(b.String())
+	if err != nil {
+		return "", &Error{Format: format, Err: fmt.Errorf("invalid URL: %w", err)}
+	}
+	if u.Opaque != "" {
+		// The slashes after the scheme were escaped, or never there.
+		return "", &Error{Format: format, Err: fmt.Errorf("invalid URL: no host after the scheme in %q", b.String())}
+	}
+	return b.String(), nil
+}
+
+// Query renders params, a map with string keys or a struct, as a URL-encoded query string, sorted by
+// key, for composing API URLs: map[string]interface{}{"q": "go fmt", "page": 2} renders as
+// "page=2&q=go+fmt". Slice values yield one parameter per element, and nil values none. Struct fields
+// are named as in Table. The {params:query} spec renders a query string in place. Values that are
+// themselves maps or structs yield an ErrUnsupportedType *Error.
+func Query(params interface{}) (string, error) {
+	v := indirect(reflect.ValueOf(params))
+	fields, ok := diffFields(v)
+	if !ok {
+		return "", &Error{Spec: "query", Err: fmt.Errorf("cannot render %T as a query string", params), kind: ErrUnsupportedType}
+	}
+	values := url.Values{}
+	for _, f := range fields {
+		elem := indirect(f.value)
+		if !elem.IsValid() {
+			continue
+		}
+		var items []reflect.Value
+		if (elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array) && elem.Type().Elem().Kind() != reflect.Uint8 {
+			for n := 0; n < elem.Len(); n++ {
+				items = append(items, indirect(elem.Index(n)))
+			}
+		} else {
+			items = []reflect.Value{elem}
+		}
+		for _, item := range items {
+			if !item.IsValid() {
+				continue
+			}
+			if _, nested := diffFields(item); nested {
+				return "", &Error{Spec: "query", Err: fmt.Errorf("cannot render %q, a %s, in a query string", f.name, item.Type()), kind: ErrUnsupportedType}
+			}
+			values.Add(f.name, fmt.Sprint(item.Interface()))
+		}
+	}
+	return values.Encode(), nil
+}
+
+// urlPart is a part of a URL: its path, including the scheme and host, its query or its fragment.
+type urlPart int
+
+const (
+	urlPath urlPart = iota
+	urlQuery
+	urlFragment
+)
+
+// after returns the part of a URL that follows text, starting in part p.
+func (p urlPart) after(text string) urlPart {
+	for _, r := range text {
+		switch {
+		case r == '#':
+			p = urlFragment
+		case r == '?' && p == urlPath:
+			p = urlQuery
+		}
+	}
+	return p
+}
@@ -0,0 +1,111 @@
+package fstr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	data := map[string]interface{}{
+		"id":    42,
+		"name":  "Q1 report/final.pdf",
+		"query": "a&b=c d",
+		"host":  "api.example.com",
+		"price": 1234.5,
+		"tag":   "x?y#z",
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "https://{host}/users/{id}", want: "https://api.example.com/users/42"},
+		{format: "/files/{name}", want: "/files/Q1%20report%2Ffinal.pdf"},
+		{format: "/search?q={query}&page={id}", want: "/search?q=a%26b%3Dc+d&page=42"},
+		{format: "/tags/{tag}?t={tag}#{tag}", want: "/tags/x%3Fy%23z?t=x%3Fy%23z#x%3Fy%23z"},
+		{format: "/price/{price:,.2f}", want: "/price/1%2C234.50"},
+		{format: "/users/{name|slug}", want: "/users/q1-report-final-pdf"},
+		{format: "/x/{id|unknown}", want: "/x/{id|unknown}"},
+	}
+	for _, tt := range tests {
+		got, err := URL(tt.format, data)
+		if err != nil {
+			t.Errorf("URL(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("URL(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	_, err := New(WithStrictKeys()).URL("https://{host}/users/{user}", data)
+	var e *Error
+	if !errors.Is(err, ErrMissingKey) || !errors.As(err, &e) || e.Format != "https://{host}/users/{user}" {
+		t.Errorf("URL() error = %#v, want ErrMissingKey for the format", err)
+	}
+	failing := []struct {
+		format string
+		data   map[string]interface{}
+	}{
+		{format: "http://[::1/{id}", data: data},
+		{format: "/users/{id}/orders", data: map[string]interface{}{"id": ".."}},
+		{format: "/users/{id}/orders", data: map[string]interface{}{"id": "."}},
+		{format: "/users/{a}{b}", data: map[string]interface{}{"a": ".", "b": "."}},
+		{format: "{base}/users/{id}", data: map[string]interface{}{"base": "https://x.y", "id": "a/b"}},
+		{format: "https://{host}/users", data: map[string]interface{}{"host": "x.y/z"}},
+	}
+	for _, tt := range failing {
+		if got, err := URL(tt.format, tt.data); !errors.As(err, &e) || e.Format != tt.format {
+			t.Errorf("URL(%q, %v) = %q, %v, want an *Error", tt.format, tt.data, got, err)
+		}
+	}
+	// Dots are fine within a segment, and after the path.
+	got, err := URL("/files/{id}.txt?dir={id}", map[string]interface{}{"id": ".."})
+	if want := "/files/...txt?dir=.."; err != nil || got != want {
+		t.Errorf("URL() = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	type filter struct {
+		Status string `fstr:"status"`
+		Limit  int    `fstr:"limit"`
+		Cursor *string
+	}
+	tests := []struct {
+		name   string
+		params interface{}
+		want   string
+	}{
+		{name: "Map", params: map[string]interface{}{"q": "go fmt", "page": 2, "sort": "-date"}, want: "page=2&q=go+fmt&sort=-date"},
+		{name: "Strings", params: map[string]string{"b": "x&y=z", "a": "é"}, want: "a=%C3%A9&b=x%26y%3Dz"},
+		{name: "Repeated", params: map[string]interface{}{"tag": []string{"go", "cli"}, "id": []int{1, 2}}, want: "id=1&id=2&tag=go&tag=cli"},
+		{name: "Values", params: map[string][]string{"x": {"1"}}, want: "x=1"},
+		{name: "Nil", params: map[string]interface{}{"a": nil, "b": 1}, want: "b=1"},
+		{name: "Struct", params: filter{Status: "open", Limit: 10}, want: "limit=10&status=open"},
+		{name: "Empty", params: map[string]interface{}{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Query(tt.params)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Query() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	for _, params := range []interface{}{42, map[string]interface{}{"a": map[string]int{"b": 1}}} {
+		if _, err := Query(params); !errors.Is(err, ErrUnsupportedType) {
+			t.Errorf("Query(%v) error = %v, want ErrUnsupportedType", params, err)
+		}
+	}
+
+	data := map[string]interface{}{"id": 7, "params": map[string]interface{}{"q": "a b", "page": 2}}
+	if got, err := Interpolate("/search?{params:query}", data); err != nil || got != "/search?page=2&q=a+b" {
+		t.Errorf("Interpolate() = %q, %v", got, err)
+	}
+	if got, err := URL("/users/{id}/search?{params:query}&x={id}", data); err != nil || got != "/users/7/search?page=2&q=a+b&x=7" {
+		t.Errorf("URL() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package web renders fstr templates as HTML responses, for small sites where a full html/template
+// setup feels heavy but escaping still matters.
+//
+// Templates are registered by name, like fstr partials, and may include and extend each other:
+//
+//	web.Define("layout", "<html><title>{block title}Shop{/block}</title><body>{block body}{/block}</body></html>")
+//	web.Define("product", "{extends layout}{block title}{name}{/block}{block body}<h1>{name}</h1>{/block}")
+//
+//	func show(w http.ResponseWriter, r *http.Request) {
+//		if err := web.Render(w, http.StatusOK, "product", data); err != nil {
+//			http.Error(w, "internal error", http.StatusInternalServerError)
+//		}
+//	}
+//
+// The values of placeholders are escaped for the Content-Type of the response, HTML by default; the
+// text of the templates is not.
+package web
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ZiadMansourM/fstr"
+)
+
+// nameRe matches the names of templates.
+var nameRe = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// Renderer renders named templates as HTTP responses. A Renderer is safe for concurrent use by
+// multiple goroutines.
+type Renderer struct {
+	interps map[fstr.EscapeMode]*fstr.Interpolator // by escape mode, sharing the templates
+	escape  *fstr.EscapeMode                       // overrides the mode chosen by Content-Type, if set
+}
+
+// escapeModes lists the escape modes a Renderer chooses from.
+var escapeModes = []fstr.EscapeMode{fstr.EscapeNone, fstr.EscapeHTML, fstr.EscapeJSON}
+
+// defaultRenderer backs the package-level functions.
+var defaultRenderer = New(nil)
+
+// New returns a Renderer for the given templates, by name, interpolating with the given options.
+// Values are escaped as chosen by Render, and styles such as {status:red} never emit ANSI escape codes.
+func New(templates map[string]string, opts ...fstr.Option) *Renderer {
+	opts = append([]fstr.Option{fstr.WithColor(fstr.ColorNever)}, opts...)
+	opts = append(opts, fstr.WithPartials(templates))
+	r := &Renderer{interps: make(map[fstr.EscapeMode]*fstr.Interpolator, len(escapeModes))}
+	for _, mode := range escapeModes {
+		r.interps[mode] = fstr.New(append(opts, fstr.WithEscape(mode))...)
+	}
+	return r
+}
+
+// WithEscape returns a Renderer sharing r's templates that escapes values with mode, whatever the
+// Content-Type of the response, for instance to render HTML fragments as text/plain.
+func (r *Renderer) WithEscape(mode fstr.EscapeMode) *Renderer {
+	c := *r
+	c.escape = &mode
+	return &c
+}
+
+// Define registers the template name with the default Renderer. See Renderer.Define.
+func Define(name, format string) error {
+	return defaultRenderer.Define(name, format)
+}
+
+// Render renders the template name of the default Renderer. See Renderer.Render.
+func Render(w http.ResponseWriter, status int, name string, data map[string]interface{}) error {
+	return defaultRenderer.Render(w, status, name, data)
+}
+
+// Define registers the template name, or replaces it. Names consist of letters, digits and
+// underscores. It returns an *fstr.Error if format cannot be parsed.
+func (r *Renderer) Define(name, format string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("web: invalid template name %q: %w", name, fstr.ErrParse)
+	}
+	for _, mode := range escapeModes {
+		if err := r.interps[mode].Define(name, format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render renders the template name with data and writes it to w with the given status code, as
+// text/html unless w already has a Content-Type. Values are escaped for the Content-Type, unless
+// overridden with WithEscape: as HTML for text/html and XML types, as JSON strings for application/json
+// and other JSON types, and not at all for text/plain and any other type. If rendering fails, nothing is
+// written and the error, usually an *fstr.Error, is returned, so that the caller can respond with an
+// error page.
+func (r *Renderer) Render(w http.ResponseWriter, status int, name string, data map[string]interface{}) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("web: invalid template name %q: %w", name, fstr.ErrParse)
+	}
+	contentType := w.Header().Get("Content-Type")
+	if contentType == "" {
+		contentType = "text/html; charset=utf-8"
+	}
+	mode := escapeFor(contentType)
+	if r.escape != nil {
+		mode = *r.escape
+	}
+	body, err := r.interps[mode].Interpolate("{>"+name+"}", data)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, err = io.WriteString(w, body)
+	return err
+}
+
+// escapeFor returns the escape mode for a Content-Type.
+func escapeFor(contentType string) fstr.EscapeMode {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fstr.EscapeHTML
+	}
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml" ||
+		mediaType == "text/xml" || mediaType == "application/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return fstr.EscapeHTML
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return fstr.EscapeJSON
+	default:
+		return fstr.EscapeNone
+	}
+}
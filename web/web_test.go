@@ -0,0 +1,108 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZiadMansourM/fstr"
+)
+
+func TestRender(t *testing.T) {
+	r := New(map[string]string{
+		"layout":  "<title>{block title}Shop{/block}</title>{block body}{/block}",
+		"product": `{extends layout}{block title}{name}{/block}{block body}<a href="/p/{id}" title="{name}">{name}</a>{/block}`,
+		"plain":   "{status:red} {n:,}",
+	}, fstr.WithStrictKeys())
+	data := map[string]interface{}{"name": `Tom & Jerry's "<script>"`, "id": 7}
+
+	rec := httptest.NewRecorder()
+	if err := r.Render(rec, http.StatusCreated, "product", data); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := `<title>Tom &amp; Jerry&#39;s &#34;&lt;script&gt;&#34;</title>` +
+		`<a href="/p/7" title="Tom &amp; Jerry&#39;s &#34;&lt;script&gt;&#34;">Tom &amp; Jerry&#39;s &#34;&lt;script&gt;&#34;</a>`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("Render() body = %q, want %q", got, want)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Render() status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("Render() Content-Type = %q", got)
+	}
+
+	rec = httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "text/plain")
+	if err := r.Render(rec, http.StatusOK, "plain", map[string]interface{}{"status": "<ok>", "n": 1234}); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got, want := rec.Body.String(), "<ok> 1,234"; got != want {
+		t.Errorf("Render() body = %q, want %q", got, want)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/plain" {
+		t.Errorf("Render() Content-Type = %q, want the one set", got)
+	}
+
+	rec = httptest.NewRecorder()
+	if err := r.Render(rec, http.StatusOK, "product", nil); !errors.Is(err, fstr.ErrMissingKey) {
+		t.Errorf("Render() error = %v, want ErrMissingKey", err)
+	}
+	if rec.Body.Len() != 0 || rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "" {
+		t.Errorf("Render() wrote a response on error: %d %q", rec.Code, rec.Body.String())
+	}
+	for _, name := range []string{"missing", "bad name"} {
+		if err := r.Render(httptest.NewRecorder(), http.StatusOK, name, data); !errors.Is(err, fstr.ErrParse) {
+			t.Errorf("Render(%q) error = %v, want ErrParse", name, err)
+		}
+	}
+}
+
+func TestDefine(t *testing.T) {
+	if err := Define("hello", "<p>Hello {name}</p>"); err != nil {
+		t.Fatalf("Define() error = %v", err)
+	}
+	rec := httptest.NewRecorder()
+	if err := Render(rec, http.StatusOK, "hello", map[string]interface{}{"name": "<b>Bob</b>"}); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got, want := rec.Body.String(), "<p>Hello &lt;b&gt;Bob&lt;/b&gt;</p>"; got != want {
+		t.Errorf("Render() body = %q, want %q", got, want)
+	}
+	if err := Define("bad name", "x"); !errors.Is(err, fstr.ErrParse) {
+		t.Errorf("Define() error = %v, want ErrParse", err)
+	}
+}
+
+func TestContentTypeEscaping(t *testing.T) {
+	r := New(map[string]string{"msg": `{"text": "{text}"}`})
+	data := map[string]interface{}{"text": `<b>"hi"</b>`}
+	tests := []struct {
+		name        string
+		renderer    *Renderer
+		contentType string
+		want        string
+	}{
+		{name: "Default", renderer: r, want: `{"text": "&lt;b&gt;&#34;hi&#34;&lt;/b&gt;"}`},
+		{name: "HTML", renderer: r, contentType: "text/html", want: `{"text": "&lt;b&gt;&#34;hi&#34;&lt;/b&gt;"}`},
+		{name: "JSON", renderer: r, contentType: "application/json; charset=utf-8", want: `{"text": "<b>\"hi\"</b>"}`},
+		{name: "JSON suffix", renderer: r, contentType: "application/problem+json", want: `{"text": "<b>\"hi\"</b>"}`},
+		{name: "Plain", renderer: r, contentType: "text/plain", want: `{"text": "<b>"hi"</b>"}`},
+		{name: "Override", renderer: r.WithEscape(fstr.EscapeNone), contentType: "text/html", want: `{"text": "<b>"hi"</b>"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			if tt.contentType != "" {
+				rec.Header().Set("Content-Type", tt.contentType)
+			}
+			if err := tt.renderer.Render(rec, http.StatusOK, "msg", data); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if got := rec.Body.String(); got != tt.want {
+				t.Errorf("Render() body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package fstr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	smallNumberWords = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+		"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+	}
+	tensWords = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	// scaleWords names the powers of a thousand, in the short scale.
+	scaleWords = []string{"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion"}
+)
+
+// inWords spells out an amount in English words, as written on checks and in legal documents:
+// {amount:words} renders 1234.56 as "one thousand two hundred thirty-four and 56/100". The cents are
+// written as a fraction of a hundred, rounded half to even; {amount:words(0)} omits the fraction, and
+// {amount:words(3)} writes it in thousandths.
+//
+// The fraction of a Money has the decimal places of its currency's minor unit, so that ¥1,235 renders
+// "one thousand two hundred thirty-five" and 1.5 KWD "one and 500/1000". Negative amounts start with
+// "minus".
+func inWords(value interface{}, digits ...string) (string, error) {
+	precision := 2
+	if m, ok := value.(Money); ok {
+		if c, ok := currencies[m.Currency]; ok {
+			precision = c.digits
+		} else {
+			precision = max(m.Scale, 0)
+		}
+	}
+	if len(digits) > 0 {
+		var err error
+		if precision, err = strconv.Atoi(digits[0]); err != nil || precision < 0 || precision > 18 {
+			return "", &Error{Spec: "words", Err: fmt.Errorf("invalid decimal places %q", digits[0]), kind: ErrBadSpec}
+		}
+	}
+	// Floats are rounded in their shortest decimal representation, as by the numeric specs.
+	number, err := formatDecimal(value, -1)
+	if err != nil {
+		return "", &Error{Spec: "words", Err: err, kind: ErrUnsupportedType}
+	}
+	if !isDigits(number[len(number)-1:]) {
+		return "", &Error{Spec: "words", Err: fmt.Errorf("cannot spell out %s", number), kind: ErrUnsupportedType}
+	}
+	number = roundHalfEven(number, precision)
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "minus ", number[1:]
+	}
+	whole, fraction, _ := strings.Cut(number, ".")
+	n, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil {
+		return "", &Error{Spec: "words", Err: fmt.Errorf("%s is too large to spell out", whole), kind: ErrUnsupportedType}
+	}
+	s := sign + spellOut(n)
+	if precision > 0 {
+		s += " and " + fraction + "/1" + strings.Repeat("0", precision)
+	}
+	return s, nil
+}
+
+// spellOut returns the English words of a whole number: 1234 => "one thousand two hundred thirty-four".
+func spellOut(n uint64) string {
+	if n == 0 {
+		return smallNumberWords[0]
+	}
+	var groups []string
+	for scale := 0; n > 0; scale, n = scale+1, n/1000 {
+		if group := n % 1000; group > 0 {
+			w := spellHundreds(int(group))
+			if scaleWords[scale] != "" {
+				w += " " + scaleWords[scale]
+			}
+			groups = append([]string{w}, groups...)
+		}
+	}
+	return strings.Join(groups, " ")
+}
+
+// spellHundreds returns the English words of a number between 1 and 999.
+func spellHundreds(n int) string {
+	var ws []string
+	if n >= 100 {
+		ws = append(ws, smallNumberWords[n/100], "hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20 && n%10 != 0:
+		ws = append(ws, tensWords[n/10]+"-"+smallNumberWords[n%10])
+	case n >= 20:
+		ws = append(ws, tensWords[n/10])
+	case n > 0:
+		ws = append(ws, smallNumberWords[n])
+	}
+	return strings.Join(ws, " ")
+}
@@ -0,0 +1,50 @@
+package fstr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		spec  string
+		value interface{}
+		want  string
+	}{
+		{spec: "words", value: 1234.56, want: "one thousand two hundred thirty-four and 56/100"},
+		{spec: "words", value: 0, want: "zero and 00/100"},
+		{spec: "words", value: 21, want: "twenty-one and 00/100"},
+		{spec: "words", value: 90, want: "ninety and 00/100"},
+		{spec: "words", value: 1000101, want: "one million one hundred one and 00/100"},
+		{spec: "words", value: 0.125, want: "zero and 12/100"},
+		{spec: "words", value: -7.5, want: "minus seven and 50/100"},
+		{spec: "words(0)", value: 12.5, want: "twelve"},
+		{spec: "words(3)", value: 12.3456, want: "twelve and 346/1000"},
+		{spec: "words", value: 2.675, want: "two and 68/100"},
+		{spec: "words", value: float32(2.675), want: "two and 68/100"},
+		{spec: "words", value: Money{Amount: 123456, Currency: "USD", Scale: 2}, want: "one thousand two hundred thirty-four and 56/100"},
+		{spec: "words", value: Money{Amount: 1235, Currency: "JPY"}, want: "one thousand two hundred thirty-five"},
+		{spec: "words", value: Money{Amount: 1500, Currency: "KWD", Scale: 3}, want: "one and 500/1000"},
+		{spec: "words", value: uint64(18446744073709551615), want: "eighteen quintillion four hundred forty-six quadrillion " +
+			"seven hundred forty-four trillion seventy-three billion seven hundred nine million " +
+			"five hundred fifty-one thousand six hundred fifteen and 00/100"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate("{n:"+tt.spec+"}", map[string]interface{}{"n": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%s, %v) error = %v", tt.spec, tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%s, %v) = %v, want %v", tt.spec, tt.value, got, tt.want)
+		}
+	}
+	for _, value := range []interface{}{"12", 1e20, math.NaN(), math.Inf(-1)} {
+		if _, err := Interpolate("{n:words}", map[string]interface{}{"n": value}); err == nil {
+			t.Errorf("Interpolate(%v) error = nil, want error", value)
+		}
+	}
+	if _, err := Interpolate("{n:words(x)}", map[string]interface{}{"n": 1}); err == nil {
+		t.Errorf("Interpolate(words(x)) error = nil, want error")
+	}
+}
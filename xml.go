@@ -0,0 +1,42 @@
+package fstr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// xmlTextReplacer escapes the characters that are special in XML element content.
+var xmlTextReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// xmlAttrReplacer escapes the characters that are special in XML attribute values, quoted with either
+// quote, including the whitespace that attribute value normalization would turn into spaces.
+var xmlAttrReplacer = strings.NewReplacer(
+	"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;",
+	"\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;",
+)
+
+// xmlText escapes a value for XML element content, such as SOAP payloads: {v:xml} renders
+// "Tom & Jerry <3" as "Tom &amp; Jerry &lt;3". Characters that XML does not allow are replaced
+// with U+FFFD.
+func xmlText(value interface{}) string {
+	return xmlTextReplacer.Replace(xmlValid(fmt.Sprint(value)))
+}
+
+// xmlAttr escapes a value for an XML attribute value, quoted with either quote: {v:xmlattr} renders
+// `say "hi"` as "say &quot;hi&quot;". Tabs and line breaks are kept as character references.
+func xmlAttr(value interface{}) string {
+	return xmlAttrReplacer.Replace(xmlValid(fmt.Sprint(value)))
+}
+
+// xmlValid replaces the characters of s that XML 1.0 does not allow, such as most control characters
+// and invalid UTF-8, with U+FFFD.
+func xmlValid(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r', r >= 0x20 && r <= 0xD7FF, r >= 0xE000 && r <= 0xFFFD, r >= 0x10000:
+			return r
+		default:
+			return '\uFFFD'
+		}
+	}, s)
+}
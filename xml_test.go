@@ -0,0 +1,29 @@
+package fstr
+
+import "testing"
+
+func TestXMLEscaping(t *testing.T) {
+	tests := []struct {
+		format string
+		value  interface{}
+		want   string
+	}{
+		{format: "<name>{v:xml}</name>", value: "Tom & Jerry <3", want: "<name>Tom &amp; Jerry &lt;3</name>"},
+		{format: "<q>{v:xml}</q>", value: `"quoted" 'text'`, want: `<q>"quoted" 'text'</q>`},
+		{format: "<n>{v:xml}</n>", value: 42, want: "<n>42</n>"},
+		{format: "<t>{v:xml}</t>", value: "line\nbreak\x00\x1b", want: "<t>line\nbreak��</t>"},
+		{format: `<a title="{v:xmlattr}"/>`, value: `say "hi" & 'bye' <now>`, want: `<a title="say &quot;hi&quot; &amp; &apos;bye&apos; &lt;now&gt;"/>`},
+		{format: `<a v="{v:xmlattr}"/>`, value: "a\tb\r\nc", want: `<a v="a&#x9;b&#xD;&#xA;c"/>`},
+		{format: "<u>{v|trimSpace:xml}</u>", value: "  ü & ö ", want: "<u>ü &amp; ö</u>"},
+	}
+	for _, tt := range tests {
+		got, err := Interpolate(tt.format, map[string]interface{}{"v": tt.value})
+		if err != nil {
+			t.Errorf("Interpolate(%q) error = %v", tt.format, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Interpolate(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package fstr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlPlainRe matches the strings that can be written as plain YAML scalars, without quotes.
+var yamlPlainRe = regexp.MustCompile(`^[A-Za-z0-9_./][A-Za-z0-9_ ./:@-]*$`)
+
+// yamlReserved lists the plain scalars that YAML parsers read as something other than a string.
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true, "~": true,
+}
+
+// yamlNumberRe matches the plain scalars that YAML 1.1 parsers read as integers, floats or timestamps,
+// such as 0x1F, 0o17, 1_000, 1:30, .inf and 2024-01-01, beyond what strconv.ParseFloat accepts.
+var yamlNumberRe = regexp.MustCompile(`^[-+]?(0b[01_]+|0o?[0-7_]+|(0|[1-9][0-9_]*)|0x[0-9a-fA-F_]+|[1-9][0-9_]*(:[0-5]?[0-9])+)$` +
+	`|^[-+]?(([0-9][0-9_]*)?\.[0-9_]*([eE][-+]?[0-9]+)?|[0-9][0-9_]*(:[0-5]?[0-9])+\.[0-9_]*|\.(inf|Inf|INF))$|^\.(nan|NaN|NAN)$` +
+	`|^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}(([Tt]|[ \t]+).*)?$`)
+
+// toYaml renders a value as block-style YAML, for embedding nested objects into generated manifests,
+// usually with nindent: {labels|toYaml|nindent(4)}. Maps are written in the given key order, structs
+// field by field as in Table, and strings are quoted only where YAML requires it.
+func toYaml(value interface{}, order KeyOrder) string {
+	s, _ := yamlNode(reflect.ValueOf(value), order)
+	return s
+}
+
+// yamlNode renders a value as YAML and reports whether it is a block: a non-empty map or slice,
+// written over one line or more, rather than a scalar or flow collection.
+func yamlNode(v reflect.Value, order KeyOrder) (string, bool) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return "null", false
+	}
+	if fields, ok := diffFields(v); ok {
+		if len(fields) == 0 {
+			return "{}", false
+		}
+		names := make([]string, len(fields))
+		values := make(map[string]reflect.Value, len(fields))
+		for n, f := range fields {
+			names[n] = f.name
+			values[f.name] = f.value
+		}
+		if v.Kind() == reflect.Map {
+			order(names)
+		}
+		lines := make([]string, len(names))
+		for n, name := range names {
+			child, block := yamlNode(values[name], order)
+			if block {
+				lines[n] = yamlString(name) + ":\n" + prefixLines(child, "  ", "  ")
+			} else {
+				lines[n] = yamlString(name) + ": " + child
+			}
+		}
+		return strings.Join(lines, "\n"), true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return yamlString(string(v.Bytes())), false
+		}
+		if v.Len() == 0 {
+			return "[]", false
+		}
+		lines := make([]string, v.Len())
+		for n := range lines {
+			child, _ := yamlNode(v.Index(n), order)
+			lines[n] = prefixLines(child, "- ", "  ")
+		}
+		return strings.Join(lines, "\n"), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v.Interface()), false
+	case reflect.String:
+		return yamlString(v.String()), false
+	default:
+		return yamlString(fmt.Sprint(v.Interface())), false
+	}
+}
+
+// yamlString renders a string as a YAML scalar: plain if that reads back as the same string,
+// double-quoted otherwise.
+func yamlString(s string) string {
+	_, err := strconv.ParseFloat(s, 64)
+	if yamlPlainRe.MatchString(s) && !strings.HasSuffix(s, " ") && !strings.Contains(s, ": ") &&
+		!yamlReserved[strings.ToLower(s)] && !yamlNumberRe.MatchString(s) && err != nil {
+		return s
+	}
+	// JSON strings are valid double-quoted YAML scalars.
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// prefixLines prefixes the first line of s with first and the others with rest.
+func prefixLines(s, first, rest string) string {
+	return first + strings.ReplaceAll(s, "\n", "\n"+rest)
+}
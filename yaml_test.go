@@ -0,0 +1,56 @@
+package fstr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToYaml(t *testing.T) {
+	type port struct {
+		Name string
+		Port int
+	}
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "Scalar", value: 8080, want: "8080"},
+		{name: "Nil", value: nil, want: "null"},
+		{name: "Map", value: map[string]interface{}{"app": "web", "tier": "frontend"}, want: "app: web\ntier: frontend"},
+		{name: "Quoted", value: map[string]interface{}{"a": "yes", "b": "1.5", "c": "key: value", "d": "", "e": "line\nbreak"},
+			want: "a: \"yes\"\nb: \"1.5\"\nc: \"key: value\"\nd: \"\"\ne: \"line\\nbreak\""},
+		{name: "YAML 1.1 scalars", value: []string{"0x1F", "1_000", ".inf", "2024-01-01", "0o17", "1:30", "v1.0", "10.0.0.1", "web-1"},
+			want: "- \"0x1F\"\n- \"1_000\"\n- \".inf\"\n- \"2024-01-01\"\n- \"0o17\"\n- \"1:30\"\n- v1.0\n- 10.0.0.1\n- web-1"},
+		{name: "Nested", value: map[string]interface{}{"labels": map[string]string{"app": "web"}, "replicas": 3, "enabled": true},
+			want: "enabled: true\nlabels:\n  app: web\nreplicas: 3"},
+		{name: "List", value: []string{"a", "b"}, want: "- a\n- b"},
+		{name: "Structs", value: []port{{Name: "http", Port: 80}, {Name: "https", Port: 443}},
+			want: "- Name: http\n  Port: 80\n- Name: https\n  Port: 443"},
+		{name: "Empty", value: map[string]interface{}{"args": []string{}, "env": map[string]string{}}, want: "args: []\nenv: {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Interpolate("{v|toYaml}", map[string]interface{}{"v": tt.value})
+			if err != nil {
+				t.Fatalf("Interpolate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Interpolate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Combined with nindent, as in Helm charts.
+	format := "metadata:\n  labels:{labels|toYaml|nindent(4)}"
+	got, err := Interpolate(format, map[string]interface{}{"labels": map[string]string{"app": "web", "env": "prod"}})
+	if want := "metadata:\n  labels:\n    app: web\n    env: prod"; err != nil || got != want {
+		t.Errorf("Interpolate(%q) = %q, %v, want %q", format, got, err, want)
+	}
+	// Maps follow the interpolator's key order.
+	reverse := New(WithKeyOrder(FixedOrder("b", "a")))
+	got, err = reverse.Interpolate("{v|toYaml}", map[string]interface{}{"v": map[string]int{"a": 1, "b": 2}})
+	if err != nil || !strings.HasPrefix(got, "b: 2") {
+		t.Errorf("Interpolate() = %q, %v, want b first", got, err)
+	}
+}